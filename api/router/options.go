@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/micro/go-micro/api/resolver"
 	"github.com/micro/go-micro/api/resolver/micro"
+	"github.com/micro/go-micro/client/selector"
 	"github.com/micro/go-micro/config/cmd"
 	"github.com/micro/go-micro/registry"
 )
@@ -12,6 +13,8 @@ type Options struct {
 	Handler   string
 	Registry  registry.Registry
 	Resolver  resolver.Resolver
+	// Rules route requests to versions of services
+	Rules selector.Rules
 }
 
 type Option func(o *Options)
@@ -59,3 +62,10 @@ func WithResolver(r resolver.Resolver) Option {
 		o.Resolver = r
 	}
 }
+
+// WithRules sets the rules routing requests to versions of services
+func WithRules(r selector.Rules) Option {
+	return func(o *Options) {
+		o.Rules = r
+	}
+}
@@ -305,6 +305,22 @@ func (r *registryRouter) Endpoint(req *http.Request) (*api.Service, error) {
 	return nil, errors.New("not found")
 }
 
+// route applies the rules to the services using the request headers as metadata
+func (r *registryRouter) route(req *http.Request, services []*registry.Service) []*registry.Service {
+	if r.opts.Rules == nil {
+		return services
+	}
+
+	md := make(map[string]string, len(req.Header))
+	for k, v := range req.Header {
+		if len(v) > 0 {
+			md[k] = v[0]
+		}
+	}
+
+	return r.opts.Rules.Route(services, md)
+}
+
 func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
 	if r.isClosed() {
 		return nil, errors.New("router closed")
@@ -313,7 +329,13 @@ func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
 	// try get an endpoint
 	ep, err := r.Endpoint(req)
 	if err == nil {
-		return ep, nil
+		if r.opts.Rules == nil {
+			return ep, nil
+		}
+		// copy the endpoint before routing its services
+		routed := *ep
+		routed.Services = r.route(req, ep.Services)
+		return &routed, nil
 	}
 
 	// error not nil
@@ -335,6 +357,9 @@ func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
 		return nil, err
 	}
 
+	// route to versions by the rules
+	services = r.route(req, services)
+
 	// only use endpoint matching when the meta handler is set aka api.Default
 	switch r.opts.Handler {
 	// rpc handlers
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/micro/go-micro/broker"
 )
@@ -21,3 +22,27 @@ func Handle(pattern string, handler http.Handler) broker.Option {
 		o.Context = context.WithValue(o.Context, "http_handlers", handlers)
 	}
 }
+
+// Spool enables at-least-once delivery. Published messages are
+// persisted to dir and redelivered with backoff until a subscriber
+// acks them, so they survive restarts of the publisher.
+func Spool(dir string) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, "http_spool", dir)
+	}
+}
+
+// SpoolTTL sets how long spooled messages are redelivered for. Messages
+// which haven't been delivered by then, such as those published to a
+// topic nobody subscribes to, are dropped. Defaults to DefaultSpoolTTL.
+func SpoolTTL(d time.Duration) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, "http_spool_ttl", d)
+	}
+}
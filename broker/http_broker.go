@@ -13,15 +13,18 @@ import (
 	"net/http"
 	"net/url"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/micro/go-micro/codec/compress"
 	"github.com/micro/go-micro/codec/json"
 	merr "github.com/micro/go-micro/errors"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/registry/cache"
 	maddr "github.com/micro/go-micro/util/addr"
+	"github.com/micro/go-micro/util/log"
 	mnet "github.com/micro/go-micro/util/net"
 	mls "github.com/micro/go-micro/util/tls"
 	"golang.org/x/net/http2"
@@ -46,6 +49,9 @@ type httpBroker struct {
 	// offline message inbox
 	mtx   sync.RWMutex
 	inbox map[string][][]byte
+
+	// persistent message spool, nil unless enabled
+	spool *spool
 }
 
 type httpSubscriber struct {
@@ -55,11 +61,14 @@ type httpSubscriber struct {
 	fn    Handler
 	svc   *registry.Service
 	hb    *httpBroker
+	// cancels retries scheduled by the handler
+	stop func()
 }
 
 type httpEvent struct {
-	m *Message
-	t string
+	m     *Message
+	t     string
+	acked bool
 }
 
 var (
@@ -106,8 +115,9 @@ func newTransport(config *tls.Config) *http.Transport {
 
 func newHttpBroker(opts ...Option) Broker {
 	options := Options{
-		Codec:   json.Marshaler{},
-		Context: context.TODO(),
+		Codec:                json.Marshaler{},
+		Context:              context.TODO(),
+		CompressionThreshold: compress.DefaultThreshold,
 	}
 
 	for _, o := range opts {
@@ -154,7 +164,10 @@ func newHttpBroker(opts ...Option) Broker {
 	return h
 }
 
+// Ack acknowledges the message. It must be called before the
+// handler returns as the publisher is waiting on the response.
 func (h *httpEvent) Ack() error {
+	h.acked = true
 	return nil
 }
 
@@ -245,6 +258,7 @@ func (h *httpBroker) unsubscribe(s *httpSubscriber) error {
 		// deregister and skip forward
 		if sub == s {
 			_ = h.r.Deregister(sub.svc)
+			sub.stop()
 			continue
 		}
 		// keep subscriber
@@ -261,8 +275,20 @@ func (h *httpBroker) run(l net.Listener) {
 	t := time.NewTicker(registerInterval)
 	defer t.Stop()
 
+	// redeliver spooled messages
+	var retry <-chan time.Time
+	if h.spool != nil {
+		rt := time.NewTicker(spoolInterval)
+		defer rt.Stop()
+		retry = rt.C
+	}
+
 	for {
 		select {
+		case <-retry:
+			for _, m := range h.spool.due() {
+				go h.deliver(m)
+			}
 		// heartbeat for each subscriber
 		case <-t.C:
 			h.RLock()
@@ -305,6 +331,16 @@ func (h *httpBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// decompress the body
+	if enc := req.Header.Get(compress.ContentEncodingHeader); len(enc) > 0 {
+		if b, err = compress.Decompress(enc, b); err != nil {
+			errr := merr.BadRequest("go.micro.broker", "Error decompressing request body: %v", err)
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			w.Write([]byte(errr.Error()))
+			return
+		}
+	}
+
 	var m *Message
 	if err = h.opts.Codec.Unmarshal(b, &m); err != nil {
 		errr := merr.InternalServerError("go.micro.broker", "Error parsing request body: %v", err)
@@ -316,6 +352,12 @@ func (h *httpBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	topic := m.Header[":topic"]
 	delete(m.Header, ":topic")
 
+	// spooled messages are redelivered until acked
+	delete(m.Header, DeliveryAttemptHeader)
+	if v := req.Header.Get(DeliveryAttemptHeader); len(v) > 0 {
+		m.Header[DeliveryAttemptHeader] = v
+	}
+
 	if len(topic) == 0 {
 		errr := merr.InternalServerError("go.micro.broker", "Topic not found")
 		w.WriteHeader(500)
@@ -323,24 +365,38 @@ func (h *httpBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	p := &httpEvent{m: m, t: topic}
 	id := req.Form.Get("id")
 
 	//nolint:prealloc
-	var subs []Handler
+	var subs []*httpSubscriber
 
 	h.RLock()
 	for _, subscriber := range h.subscribers[topic] {
 		if id != subscriber.id {
 			continue
 		}
-		subs = append(subs, subscriber.fn)
+		subs = append(subs, subscriber)
 	}
 	h.RUnlock()
 
+	acked := true
+
 	// execute the handler
-	for _, fn := range subs {
-		fn(p)
+	for _, sub := range subs {
+		p := &httpEvent{m: m, t: topic}
+		if err := sub.fn(p); err == nil && sub.opts.AutoAck {
+			p.Ack()
+		}
+		if !p.acked {
+			acked = false
+		}
+	}
+
+	// tell the publisher to redeliver
+	if !acked {
+		errr := merr.InternalServerError("go.micro.broker", "Message not acknowledged")
+		w.WriteHeader(500)
+		w.Write([]byte(errr.Error()))
 	}
 }
 
@@ -350,6 +406,13 @@ func (h *httpBroker) Address() string {
 	return h.address
 }
 
+// Connected returns true if the broker is serving subscribers
+func (h *httpBroker) Connected() bool {
+	h.RLock()
+	defer h.RUnlock()
+	return h.running
+}
+
 func (h *httpBroker) Connect() error {
 	h.RLock()
 	if h.running {
@@ -403,6 +466,20 @@ func (h *httpBroker) Connect() error {
 		return err
 	}
 
+	// open the spool before serving so pending messages are redelivered
+	if dir, ok := h.opts.Context.Value("http_spool").(string); ok && h.spool == nil {
+		ttl := DefaultSpoolTTL
+		if d, ok := h.opts.Context.Value("http_spool_ttl").(time.Duration); ok && d > 0 {
+			ttl = d
+		}
+		sp, err := newSpool(dir, ttl)
+		if err != nil {
+			l.Close()
+			return err
+		}
+		h.spool = sp
+	}
+
 	addr := h.address
 	h.address = l.Addr().String()
 
@@ -450,6 +527,13 @@ func (h *httpBroker) Disconnect() error {
 	h.exit <- ch
 	err := <-ch
 
+	// cancel the retries of messages
+	for _, subs := range h.subscribers {
+		for _, sub := range subs {
+			sub.stop()
+		}
+	}
+
 	// set not running
 	h.running = false
 	return err
@@ -507,6 +591,11 @@ func (h *httpBroker) Options() Options {
 }
 
 func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption) error {
+	var options PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
 	// create the message first
 	m := &Message{
 		Header: make(map[string]string),
@@ -517,6 +606,20 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 		m.Header[k] = v
 	}
 
+	h.RLock()
+	sp := h.spool
+	h.RUnlock()
+
+	// without a spool delayed messages are held in memory
+	if d := time.Until(options.DeliverAt); d > 0 && sp == nil {
+		time.AfterFunc(d, func() {
+			if err := h.Publish(topic, m); err != nil {
+				log.Logf("Error publishing delayed message to %s: %v", topic, err)
+			}
+		})
+		return nil
+	}
+
 	m.Header[":topic"] = topic
 
 	// encode the message
@@ -525,6 +628,24 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 		return err
 	}
 
+	// persist the message and deliver until acked
+	if sp != nil {
+		sm := &spoolMessage{
+			Id:        uuid.New().String(),
+			Topic:     topic,
+			Body:      b,
+			DeliverAt: options.DeliverAt,
+		}
+		if err := sp.save(sm); err != nil {
+			return err
+		}
+		// delayed messages are picked up by the redelivery loop
+		if sm.inflight {
+			go h.deliver(sm)
+		}
+		return nil
+	}
+
 	// save the message
 	h.saveMessage(topic, b)
 
@@ -539,26 +660,8 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 	h.RUnlock()
 
 	pub := func(node *registry.Node, t string, b []byte) error {
-		scheme := "http"
-
-		// check if secure is added in metadata
-		if node.Metadata["secure"] == "true" {
-			scheme = "https"
-		}
-
-		vals := url.Values{}
-		vals.Add("id", node.Id)
-
-		uri := fmt.Sprintf("%s://%s%s?%s", scheme, node.Address, DefaultSubPath, vals.Encode())
-		r, err := h.c.Post(uri, "application/json", bytes.NewReader(b))
-		if err != nil {
-			return err
-		}
-
-		// discard response body
-		io.Copy(ioutil.Discard, r.Body)
-		r.Body.Close()
-		return nil
+		_, err := h.post(node, b, 0)
+		return err
 	}
 
 	srv := func(s []*registry.Service, b []byte) {
@@ -635,6 +738,121 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 	return nil
 }
 
+// post sends the message to the node and returns the response status.
+// The attempt of spooled messages is passed on to the retry handler.
+func (h *httpBroker) post(node *registry.Node, b []byte, attempt int) (int, error) {
+	scheme := "http"
+
+	// check if secure is added in metadata
+	if node.Metadata["secure"] == "true" {
+		scheme = "https"
+	}
+
+	vals := url.Values{}
+	vals.Add("id", node.Id)
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	if attempt > 0 {
+		header.Set(DeliveryAttemptHeader, strconv.Itoa(attempt))
+	}
+
+	// compress the message if the subscriber accepts the encoding
+	if len(b) >= h.opts.CompressionThreshold && compress.Accepts(node.Metadata["accept-encoding"], h.opts.Compression) {
+		cb, err := compress.Compress(h.opts.Compression, b)
+		if err != nil {
+			return 0, err
+		}
+		b = cb
+		header.Set(compress.ContentEncodingHeader, h.opts.Compression)
+	}
+
+	uri := fmt.Sprintf("%s://%s%s?%s", scheme, node.Address, DefaultSubPath, vals.Encode())
+	req, err := http.NewRequest("POST", uri, bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	req.Header = header
+
+	r, err := h.c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	// discard response body
+	io.Copy(ioutil.Discard, r.Body)
+	r.Body.Close()
+	return r.StatusCode, nil
+}
+
+// deliver sends a spooled message to the subscribers of its topic. The
+// message is removed once every broadcast subscriber and one member of
+// each queue has acked it, otherwise it is redelivered with backoff.
+func (h *httpBroker) deliver(m *spoolMessage) {
+	h.RLock()
+	services, err := h.r.GetService(serviceName)
+	h.RUnlock()
+	if err != nil {
+		h.spool.nack(m)
+		return
+	}
+
+	var pending, delivered int
+
+	for _, service := range services {
+		var nodes []*registry.Node
+
+		for _, node := range service.Nodes {
+			// only use nodes tagged with broker http for the topic
+			if node.Metadata["broker"] != "http" || node.Metadata["topic"] != m.Topic {
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+
+		if len(nodes) == 0 {
+			continue
+		}
+
+		switch service.Version {
+		// broadcast version means every node must ack
+		case broadcastVersion:
+			for _, node := range nodes {
+				if m.acked[node.Id] {
+					continue
+				}
+				if code, err := h.post(node, m.Body, m.attempts+1); err != nil || code != http.StatusOK {
+					pending++
+					continue
+				}
+				m.acked[node.Id] = true
+				delivered++
+			}
+		default:
+			// one member of the queue must ack
+			key := "queue:" + service.Version
+			if m.acked[key] {
+				continue
+			}
+			node := nodes[rand.Int()%len(nodes)]
+			if code, err := h.post(node, m.Body, m.attempts+1); err != nil || code != http.StatusOK {
+				pending++
+				continue
+			}
+			m.acked[key] = true
+			delivered++
+		}
+	}
+
+	// nobody to deliver to yet or someone failed to ack
+	if pending > 0 || (delivered == 0 && len(m.acked) == 0) {
+		h.spool.nack(m)
+		return
+	}
+
+	h.spool.ack(m)
+}
+
 func (h *httpBroker) Subscribe(topic string, handler Handler, opts ...SubscribeOption) (Subscriber, error) {
 	var err error
 	var host, port string
@@ -665,6 +883,8 @@ func (h *httpBroker) Subscribe(topic string, handler Handler, opts ...SubscribeO
 			"secure": fmt.Sprintf("%t", secure),
 			"broker": "http",
 			"topic":  topic,
+			// encodings the subscriber decompresses
+			"accept-encoding": compress.Accept(),
 		},
 	}
 
@@ -680,14 +900,17 @@ func (h *httpBroker) Subscribe(topic string, handler Handler, opts ...SubscribeO
 		Nodes:   []*registry.Node{node},
 	}
 
+	fn, stop := RetryHandler(h, handler, options)
+
 	// generate subscriber
 	subscriber := &httpSubscriber{
 		opts:  options,
 		hb:    h,
 		id:    node.Id,
 		topic: topic,
-		fn:    handler,
+		fn:    fn,
 		svc:   service,
+		stop:  stop,
 	}
 
 	// subscribe now
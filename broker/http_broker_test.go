@@ -1,12 +1,20 @@
 package broker
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	glog "github.com/go-log/log"
 	"github.com/google/uuid"
+	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/codec/cbor"
+	"github.com/micro/go-micro/codec/msgpack"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/registry/memory"
 	"github.com/micro/go-micro/util/log"
@@ -199,6 +207,367 @@ func TestBroker(t *testing.T) {
 	}
 }
 
+func TestCodecBroker(t *testing.T) {
+	for _, c := range []codec.Marshaler{msgpack.Marshaler{}, cbor.Marshaler{}} {
+		m := newTestRegistry()
+		b := NewBroker(Registry(m), Codec(c))
+
+		if err := b.Init(); err != nil {
+			t.Fatalf("Unexpected init error: %v", err)
+		}
+
+		if err := b.Connect(); err != nil {
+			t.Fatalf("Unexpected connect error: %v", err)
+		}
+
+		msg := &Message{
+			Header: map[string]string{
+				"Content-Type": "application/" + c.String(),
+			},
+			Body: []byte(`hello`),
+		}
+
+		done := make(chan bool)
+
+		sub, err := b.Subscribe("test", func(p Event) error {
+			m := p.Message()
+
+			if string(m.Body) != string(msg.Body) || m.Header["Content-Type"] != msg.Header["Content-Type"] {
+				t.Errorf("%s: unexpected msg %+v, expected %+v", c.String(), m, msg)
+			}
+
+			close(done)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected subscribe error: %v", err)
+		}
+
+		if err := b.Publish("test", msg); err != nil {
+			t.Fatalf("Unexpected publish error: %v", err)
+		}
+
+		<-done
+		sub.Unsubscribe()
+
+		if err := b.Disconnect(); err != nil {
+			t.Fatalf("Unexpected disconnect error: %v", err)
+		}
+	}
+}
+
+func TestCompressionBroker(t *testing.T) {
+	m := newTestRegistry()
+	b := NewBroker(Registry(m), Compression("gzip"), CompressionThreshold(0))
+
+	if err := b.Init(); err != nil {
+		t.Fatalf("Unexpected init error: %v", err)
+	}
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+
+	msg := &Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: []byte(`{"message": "Hello World"}`),
+	}
+
+	done := make(chan bool)
+
+	sub, err := b.Subscribe("test", func(p Event) error {
+		m := p.Message()
+
+		if string(m.Body) != string(msg.Body) {
+			t.Errorf("Unexpected msg %s, expected %s", string(m.Body), string(msg.Body))
+		}
+
+		close(done)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+
+	// the subscriber advertises the encodings it accepts
+	services, err := m.GetService(serviceName)
+	if err != nil {
+		t.Fatalf("Unexpected registry error: %v", err)
+	}
+	if enc := services[0].Nodes[0].Metadata["accept-encoding"]; !strings.Contains(enc, "gzip") {
+		t.Fatalf("Expected subscriber to accept gzip got %q", enc)
+	}
+
+	if err := b.Publish("test", msg); err != nil {
+		t.Fatalf("Unexpected publish error: %v", err)
+	}
+
+	<-done
+	sub.Unsubscribe()
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error: %v", err)
+	}
+}
+
+func TestSpoolBroker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	spool := func(o *Options) {
+		o.Context = context.WithValue(o.Context, "http_spool", dir)
+	}
+
+	m := newTestRegistry()
+	b := NewBroker(Registry(m), spool)
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+
+	msg := &Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: []byte(`{"message": "Hello World"}`),
+	}
+
+	// nobody is subscribed so the message stays in the spool
+	if err := b.Publish("spool", msg); err != nil {
+		t.Fatalf("Unexpected publish error: %v", err)
+	}
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error: %v", err)
+	}
+
+	// a new broker picks up the spooled message
+	b = NewBroker(Registry(m), spool)
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+
+	var calls int
+	done := make(chan bool)
+
+	sub, err := b.Subscribe("spool", func(p Event) error {
+		if string(p.Message().Body) != string(msg.Body) {
+			t.Fatalf("Unexpected msg %s, expected %s", string(p.Message().Body), string(msg.Body))
+		}
+
+		calls++
+
+		// not acking the first delivery causes a redelivery
+		if calls == 1 {
+			return nil
+		}
+
+		p.Ack()
+		close(done)
+		return nil
+	}, DisableAutoAck())
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for redelivery")
+	}
+
+	sub.Unsubscribe()
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error: %v", err)
+	}
+
+	// acked messages are removed from the spool
+	time.Sleep(10 * time.Millisecond)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("Expected empty spool got %d files", len(files))
+	}
+}
+
+func TestSpoolRetryBroker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	spool := func(o *Options) {
+		o.Context = context.WithValue(o.Context, "http_spool", dir)
+	}
+
+	b := NewBroker(Registry(newTestRegistry()), spool)
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+	defer b.Disconnect()
+
+	dead := make(chan *Message, 1)
+	if _, err := b.Subscribe("dead", func(p Event) error {
+		dead <- p.Message()
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+
+	attempts := make(chan string, 2)
+	if _, err := b.Subscribe("spool", func(p Event) error {
+		attempts <- p.Message().Header[DeliveryAttemptHeader]
+
+		// the failed message is still spooled for redelivery
+		files, err := ioutil.ReadDir(dir)
+		if err != nil || len(files) == 0 {
+			t.Errorf("Expected the message in the spool got %d files: %v", len(files), err)
+		}
+
+		return errors.New("failed")
+	}, MaxAttempts(2), DeadLetter("dead")); err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+
+	if err := b.Publish("spool", &Message{Body: []byte(`hello`)}); err != nil {
+		t.Fatalf("Unexpected publish error: %v", err)
+	}
+
+	// the spool redelivers the message with the attempt
+	for _, attempt := range []string{"1", "2"} {
+		select {
+		case a := <-attempts:
+			if a != attempt {
+				t.Fatalf("Expected attempt %s got %s", attempt, a)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for attempt %s", attempt)
+		}
+	}
+
+	select {
+	case m := <-dead:
+		if string(m.Body) != "hello" || m.Header[DeadLetterAttemptsHeader] != "2" {
+			t.Fatalf("Unexpected dead letter %+v", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the dead letter")
+	}
+}
+
+func TestSpoolDelayBroker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	spool := func(o *Options) {
+		o.Context = context.WithValue(o.Context, "http_spool", dir)
+	}
+
+	m := newTestRegistry()
+	b := NewBroker(Registry(m), spool)
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+
+	deliverAt := time.Now().Add(300 * time.Millisecond)
+
+	if err := b.Publish("delay", &Message{Body: []byte("later")}, DeliverAt(deliverAt)); err != nil {
+		t.Fatalf("Unexpected publish error: %v", err)
+	}
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error: %v", err)
+	}
+
+	// the delayed message survives a restart
+	b = NewBroker(Registry(m), spool)
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+
+	received := make(chan time.Time, 1)
+
+	sub, err := b.Subscribe("delay", func(p Event) error {
+		received <- time.Now()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+
+	select {
+	case at := <-received:
+		if at.Before(deliverAt) {
+			t.Fatalf("Message delivered %v early", deliverAt.Sub(at))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for delayed message")
+	}
+
+	sub.Unsubscribe()
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error: %v", err)
+	}
+}
+
+func TestSpoolExpiryBroker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	spool := func(o *Options) {
+		o.Context = context.WithValue(o.Context, "http_spool", dir)
+		o.Context = context.WithValue(o.Context, "http_spool_ttl", 100*time.Millisecond)
+	}
+
+	m := newTestRegistry()
+	b := NewBroker(Registry(m), spool)
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+	defer b.Disconnect()
+
+	// nobody is subscribed so the message is dropped once it expires
+	if err := b.Publish("expiry", &Message{Body: []byte("dropped")}); err != nil {
+		t.Fatalf("Unexpected publish error: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected empty spool got %d files", len(files))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
 func TestConcurrentSubBroker(t *testing.T) {
 	m := newTestRegistry()
 	b := NewBroker(Registry(m))
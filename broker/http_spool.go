@@ -0,0 +1,193 @@
+package broker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/util/log"
+)
+
+var (
+	// DefaultSpoolTTL is how long a spooled message is redelivered
+	// for before it's dropped, counted from when it's first due
+	DefaultSpoolTTL = 24 * time.Hour
+
+	// how often the spool is checked for messages to redeliver
+	spoolInterval = 100 * time.Millisecond
+)
+
+// spool persists published messages until they are acked
+type spool struct {
+	dir string
+	ttl time.Duration
+
+	sync.Mutex
+	messages map[string]*spoolMessage
+}
+
+type spoolMessage struct {
+	Id    string `json:"id"`
+	Topic string `json:"topic"`
+	// encoded message as posted to subscribers
+	Body []byte `json:"body"`
+	// message is not delivered before this time
+	DeliverAt time.Time `json:"deliver_at,omitempty"`
+	// message is dropped if it's not delivered by this time
+	Expires time.Time `json:"expires,omitempty"`
+
+	// nodes or queues which have acked the message
+	acked    map[string]bool
+	attempts int
+	next     time.Time
+	inflight bool
+}
+
+func newSpool(dir string, ttl time.Duration) (*spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spool{
+		dir:      dir,
+		ttl:      ttl,
+		messages: make(map[string]*spoolMessage),
+	}
+
+	// load undelivered messages from a previous run
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".msg") {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := new(spoolMessage)
+		if err := json.Unmarshal(b, m); err != nil {
+			// partially written message, it was never published
+			os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		m.acked = make(map[string]bool)
+		m.next = m.DeliverAt
+		if m.Expires.IsZero() {
+			m.Expires = time.Now().Add(ttl)
+		}
+		s.messages[m.Id] = m
+	}
+
+	return s, nil
+}
+
+func (s *spool) path(id string) string {
+	return filepath.Join(s.dir, id+".msg")
+}
+
+// save persists the message and marks it as being
+// delivered unless delivery is delayed
+func (s *spool) save(m *spoolMessage) error {
+	due := time.Now()
+	if m.DeliverAt.After(due) {
+		due = m.DeliverAt
+	}
+	m.Expires = due.Add(s.ttl)
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	// write then rename so a crash never leaves a partial message
+	tmp := s.path(m.Id) + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	f.Close()
+
+	if err := os.Rename(tmp, s.path(m.Id)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	m.acked = make(map[string]bool)
+	m.next = m.DeliverAt
+	m.inflight = !m.next.After(time.Now())
+
+	s.Lock()
+	s.messages[m.Id] = m
+	s.Unlock()
+
+	return nil
+}
+
+// ack removes a delivered message
+func (s *spool) ack(m *spoolMessage) error {
+	s.Lock()
+	delete(s.messages, m.Id)
+	s.Unlock()
+
+	return os.Remove(s.path(m.Id))
+}
+
+// nack schedules the message for redelivery or
+// drops it once it has expired
+func (s *spool) nack(m *spoolMessage) {
+	now := time.Now()
+
+	if now.After(m.Expires) {
+		log.Logf("Dropping message %s to %s undelivered after %d attempts", m.Id, m.Topic, m.attempts+1)
+		s.ack(m)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	m.attempts++
+	m.inflight = false
+
+	m.next = now.Add(DefaultBackoff(m.attempts))
+}
+
+// due returns the messages ready for redelivery
+// and marks them as being delivered
+func (s *spool) due() []*spoolMessage {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+
+	var messages []*spoolMessage
+	for _, m := range s.messages {
+		if m.inflight || m.next.After(now) {
+			continue
+		}
+		m.inflight = true
+		messages = append(messages, m)
+	}
+
+	return messages
+}
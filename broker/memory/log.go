@@ -0,0 +1,498 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/store"
+	smemory "github.com/micro/go-micro/store/memory"
+	"github.com/micro/go-micro/sync/event"
+	estore "github.com/micro/go-micro/sync/event/store"
+	"github.com/micro/go-micro/util/log"
+)
+
+var (
+	// DefaultPartitionKey is the header messages are partitioned by
+	DefaultPartitionKey = "Micro-Partition-Key"
+
+	// time to wait before retrying a failed read of the log
+	readRetryTime = time.Second
+)
+
+// partitionLog keeps each topic as a set of ordered partitions
+// in an event log. Every subscriber reads the partitions at its own
+// pace and members of a queue share the partitions between them.
+type partitionLog struct {
+	event      event.Event
+	offsets    store.Store
+	partitions int
+	key        string
+
+	// round robin counter for messages without a key
+	counter uint64
+	// closed to stop every consumer
+	exit chan bool
+
+	sync.Mutex
+	topics map[string]*topic
+	groups map[string]*group
+}
+
+type topic struct {
+	name string
+
+	sync.Mutex
+	writers []event.Log
+	// closed and replaced whenever a message is written
+	notify chan bool
+}
+
+// group is the set of subscribers sharing a queue
+type group struct {
+	queue string
+	topic *topic
+	exit  chan bool
+
+	sync.RWMutex
+	members []*memorySubscriber
+}
+
+func newPartitionLog(ctx context.Context) *partitionLog {
+	n, ok := ctx.Value(partitionsKey{}).(int)
+	if !ok || n < 1 {
+		n = 1
+	}
+
+	key, ok := ctx.Value(partitionKeyKey{}).(string)
+	if !ok {
+		key = DefaultPartitionKey
+	}
+
+	e, ok := ctx.Value(eventKey{}).(event.Event)
+	if !ok {
+		e = estore.NewEvent()
+	}
+
+	s, ok := ctx.Value(offsetsKey{}).(store.Store)
+	if !ok {
+		s = smemory.NewStore()
+	}
+
+	return &partitionLog{
+		event:      e,
+		offsets:    s,
+		partitions: n,
+		key:        key,
+		exit:       make(chan bool),
+		topics:     make(map[string]*topic),
+		groups:     make(map[string]*group),
+	}
+}
+
+// close stops the consumers and closes the partitions
+func (p *partitionLog) close() {
+	p.Lock()
+	defer p.Unlock()
+
+	close(p.exit)
+
+	for _, t := range p.topics {
+		t.Lock()
+		for _, w := range t.writers {
+			w.Close()
+		}
+		t.Unlock()
+	}
+
+	p.topics = make(map[string]*topic)
+	p.groups = make(map[string]*group)
+}
+
+func logId(topic string, partition int) string {
+	return topic + "/" + strconv.Itoa(partition)
+}
+
+// topic returns the topic creating its partitions if needed
+func (p *partitionLog) topic(name string) (*topic, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if t, ok := p.topics[name]; ok {
+		return t, nil
+	}
+
+	t := &topic{
+		name:   name,
+		notify: make(chan bool),
+	}
+
+	for i := 0; i < p.partitions; i++ {
+		w, err := p.event.Log(logId(name, i))
+		if err != nil {
+			for _, w := range t.writers {
+				w.Close()
+			}
+			return nil, err
+		}
+		t.writers = append(t.writers, w)
+	}
+
+	p.topics[name] = t
+	return t, nil
+}
+
+// partition picks the partition for a message from its key
+func (p *partitionLog) partition(msg *broker.Message) int {
+	key, ok := msg.Header[p.key]
+	if !ok || len(key) == 0 {
+		return int(atomic.AddUint64(&p.counter, 1) % uint64(p.partitions))
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(p.partitions))
+}
+
+func (p *partitionLog) publish(name string, msg *broker.Message) error {
+	t, err := p.topic(name)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	r := &event.Record{
+		Metadata: map[string]interface{}{
+			"timestamp": time.Now().Format(time.RFC3339Nano),
+		},
+		Data: b,
+	}
+
+	t.Lock()
+	if err := t.writers[p.partition(msg)].Write(r); err != nil {
+		t.Unlock()
+		return err
+	}
+	// wake up any waiting consumers
+	close(t.notify)
+	t.notify = make(chan bool)
+	t.Unlock()
+
+	return nil
+}
+
+func (p *partitionLog) subscribe(sub *memorySubscriber) error {
+	t, err := p.topic(sub.topic)
+	if err != nil {
+		return err
+	}
+
+	// every subscriber outside a queue reads all the partitions
+	if len(sub.opts.Queue) == 0 {
+		next := func(int) *memorySubscriber { return sub }
+		commit := func(int, int64) {}
+
+		for i := 0; i < p.partitions; i++ {
+			offset, err := p.start(t, i, sub.opts)
+			if err != nil {
+				return err
+			}
+			go p.consume(t, i, offset, sub.done, next, commit)
+		}
+		return nil
+	}
+
+	key := sub.topic + "/" + sub.opts.Queue
+
+	p.Lock()
+	defer p.Unlock()
+
+	if g, ok := p.groups[key]; ok {
+		g.Lock()
+		g.members = append(g.members, sub)
+		g.Unlock()
+		return nil
+	}
+
+	g := &group{
+		queue:   sub.opts.Queue,
+		topic:   t,
+		exit:    make(chan bool),
+		members: []*memorySubscriber{sub},
+	}
+
+	// resume from the committed offsets or the start position
+	offsets := make([]int64, p.partitions)
+	for i := range offsets {
+		offset, ok, err := p.committed(g, i)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if offset, err = p.start(t, i, sub.opts); err != nil {
+				return err
+			}
+			if err := p.commit(g, i, offset); err != nil {
+				return err
+			}
+		}
+		offsets[i] = offset
+	}
+
+	for i, offset := range offsets {
+		go p.consume(t, i, offset, g.exit, g.member, func(i int, offset int64) {
+			if err := p.commit(g, i, offset); err != nil {
+				log.Logf("Error committing offset for queue %s topic %s: %v", g.queue, t.name, err)
+			}
+		})
+	}
+
+	p.groups[key] = g
+	return nil
+}
+
+func (p *partitionLog) unsubscribe(sub *memorySubscriber) {
+	if len(sub.opts.Queue) == 0 {
+		close(sub.done)
+		return
+	}
+
+	key := sub.topic + "/" + sub.opts.Queue
+
+	p.Lock()
+	defer p.Unlock()
+
+	g, ok := p.groups[key]
+	if !ok {
+		return
+	}
+
+	g.Lock()
+	var members []*memorySubscriber
+	for _, m := range g.members {
+		if m != sub {
+			members = append(members, m)
+		}
+	}
+	g.members = members
+	g.Unlock()
+
+	// stop consuming once the queue is empty
+	if len(members) == 0 {
+		close(g.exit)
+		delete(p.groups, key)
+	}
+}
+
+// member returns the subscriber assigned the partition
+func (g *group) member(partition int) *memorySubscriber {
+	g.RLock()
+	defer g.RUnlock()
+
+	if len(g.members) == 0 {
+		return nil
+	}
+	return g.members[partition%len(g.members)]
+}
+
+func (p *partitionLog) offsetKey(g *group, partition int) string {
+	return fmt.Sprintf("%s/%s/%d", g.queue, g.topic.name, partition)
+}
+
+// committed returns the offset the queue has consumed the partition up to
+func (p *partitionLog) committed(g *group, partition int) (int64, bool, error) {
+	recs, err := p.offsets.Read(p.offsetKey(g, partition))
+	if err == store.ErrNotFound {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	offset, err := strconv.ParseInt(string(recs[0].Value), 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return offset, true, nil
+}
+
+func (p *partitionLog) commit(g *group, partition int, offset int64) error {
+	return p.offsets.Write(&store.Record{
+		Key:   p.offsetKey(g, partition),
+		Value: []byte(strconv.FormatInt(offset, 10)),
+	})
+}
+
+// start returns the offset a subscriber begins reading the partition from
+func (p *partitionLog) start(t *topic, partition int, opts broker.SubscribeOptions) (int64, error) {
+	if opts.StartPosition == broker.StartEarliest {
+		return 0, nil
+	}
+
+	l, err := p.event.Log(logId(t.name, partition))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	end, err := logEnd(l)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.StartPosition != broker.StartTimestamp {
+		return end, nil
+	}
+
+	// find the first message published at or after the start time
+	lo, hi := int64(0), end
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if err := l.Seek(mid); err != nil {
+			return 0, err
+		}
+		r, err := l.Read()
+		if err != nil {
+			return 0, err
+		}
+		if timestamp(r).Before(opts.StartTime) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
+
+// logEnd finds the offset after the last record in the log
+func logEnd(l event.Log) (int64, error) {
+	// seeking past the end of the log fails
+	lo, hi := int64(0), int64(1)
+	for l.Seek(hi) == nil {
+		lo = hi
+		hi *= 2
+	}
+
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		if l.Seek(mid) == nil {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
+
+func timestamp(r *event.Record) time.Time {
+	s, _ := r.Metadata["timestamp"].(string)
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+// consume delivers the messages in the partition from offset until exit is
+// closed. next picks the subscriber for the partition and commit records the
+// offset after a message has been acked. Unacked messages are redelivered so
+// the order within a partition is kept.
+func (p *partitionLog) consume(t *topic, partition int, offset int64, exit chan bool, next func(int) *memorySubscriber, commit func(int, int64)) {
+	l, err := p.event.Log(logId(t.name, partition))
+	if err != nil {
+		log.Logf("Error opening log for topic %s: %v", t.name, err)
+		return
+	}
+	defer l.Close()
+
+	if err := l.Seek(offset); err != nil {
+		log.Logf("Error seeking log for topic %s to %d: %v", t.name, offset, err)
+		return
+	}
+
+	for {
+		// get the notifier before reading so no write is missed
+		t.Lock()
+		notify := t.notify
+		t.Unlock()
+
+		r, err := l.Read()
+		if err == io.EOF {
+			select {
+			case <-notify:
+				continue
+			case <-exit:
+				return
+			case <-p.exit:
+				return
+			}
+		}
+		if err != nil {
+			log.Logf("Error reading log for topic %s: %v", t.name, err)
+			select {
+			case <-time.After(readRetryTime):
+				continue
+			case <-exit:
+				return
+			case <-p.exit:
+				return
+			}
+		}
+
+		var msg *broker.Message
+		if err := json.Unmarshal(r.Data, &msg); err != nil {
+			log.Logf("Error decoding message in topic %s at %d: %v", t.name, r.Offset, err)
+			commit(partition, r.Offset+1)
+			continue
+		}
+
+		for attempt := 1; ; attempt++ {
+			sub := next(partition)
+			if sub == nil {
+				return
+			}
+
+			// unacked messages are redelivered so the
+			// attempt is passed on to the retry handler
+			header := make(map[string]string, len(msg.Header)+1)
+			for k, v := range msg.Header {
+				header[k] = v
+			}
+			header[broker.DeliveryAttemptHeader] = strconv.Itoa(attempt)
+
+			e := &memoryEvent{
+				topic: t.name,
+				message: &broker.Message{
+					Header: header,
+					Body:   msg.Body,
+				},
+			}
+			if err := sub.handler(e); err == nil && sub.opts.AutoAck {
+				e.Ack()
+			}
+			if e.acked {
+				break
+			}
+
+			select {
+			case <-time.After(broker.DefaultBackoff(attempt)):
+			case <-exit:
+				return
+			case <-p.exit:
+				return
+			}
+		}
+
+		commit(partition, r.Offset+1)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/broker"
 	maddr "github.com/micro/go-micro/util/addr"
+	"github.com/micro/go-micro/util/log"
 	mnet "github.com/micro/go-micro/util/net"
 )
 
@@ -20,19 +21,26 @@ type memoryBroker struct {
 	sync.RWMutex
 	connected   bool
 	Subscribers map[string][]*memorySubscriber
+
+	// set when topics are partitioned
+	log *partitionLog
 }
 
 type memoryEvent struct {
 	topic   string
 	message *broker.Message
+	acked   bool
 }
 
 type memorySubscriber struct {
 	id      string
 	topic   string
 	exit    chan bool
+	done    chan bool
 	handler broker.Handler
 	opts    broker.SubscribeOptions
+	// cancels retries scheduled by the handler
+	stop func()
 }
 
 func (m *memoryBroker) Options() broker.Options {
@@ -43,6 +51,13 @@ func (m *memoryBroker) Address() string {
 	return m.addr
 }
 
+// Connected returns true if the broker is connected
+func (m *memoryBroker) Connected() bool {
+	m.RLock()
+	defer m.RUnlock()
+	return m.connected
+}
+
 func (m *memoryBroker) Connect() error {
 	m.Lock()
 	defer m.Unlock()
@@ -62,6 +77,12 @@ func (m *memoryBroker) Connect() error {
 	m.addr = addr
 	m.connected = true
 
+	if m.log == nil && m.opts.Context != nil {
+		if _, ok := m.opts.Context.Value(partitionsKey{}).(int); ok {
+			m.log = newPartitionLog(m.opts.Context)
+		}
+	}
+
 	return nil
 }
 
@@ -75,6 +96,19 @@ func (m *memoryBroker) Disconnect() error {
 
 	m.connected = false
 
+	// cancel the retries of messages
+	for _, subs := range m.Subscribers {
+		for _, sub := range subs {
+			sub.stop()
+		}
+	}
+
+	// stop consuming the partitions
+	if m.log != nil {
+		m.log.close()
+		m.log = nil
+	}
+
 	return nil
 }
 
@@ -86,12 +120,36 @@ func (m *memoryBroker) Init(opts ...broker.Option) error {
 }
 
 func (m *memoryBroker) Publish(topic string, message *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// hold delayed messages until they are due
+	if d := time.Until(options.DeliverAt); d > 0 {
+		time.AfterFunc(d, func() {
+			if err := m.publish(topic, message); err != nil {
+				log.Logf("Error publishing delayed message to %s: %v", topic, err)
+			}
+		})
+		return nil
+	}
+
+	return m.publish(topic, message)
+}
+
+func (m *memoryBroker) publish(topic string, message *broker.Message) error {
 	m.RLock()
 	if !m.connected {
 		m.RUnlock()
 		return errors.New("not connected")
 	}
 
+	if m.log != nil {
+		m.RUnlock()
+		return m.log.publish(topic, message)
+	}
+
 	subs, ok := m.Subscribers[topic]
 	m.RUnlock()
 	if !ok {
@@ -118,19 +176,34 @@ func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...b
 		m.RUnlock()
 		return nil, errors.New("not connected")
 	}
+	plog := m.log
 	m.RUnlock()
 
-	var options broker.SubscribeOptions
-	for _, o := range opts {
-		o(&options)
-	}
+	options := broker.NewSubscribeOptions(opts...)
+	handler, stop := broker.RetryHandler(m, handler, options)
 
 	sub := &memorySubscriber{
 		exit:    make(chan bool, 1),
+		done:    make(chan bool),
 		id:      uuid.New().String(),
 		topic:   topic,
 		handler: handler,
 		opts:    options,
+		stop:    stop,
+	}
+
+	// subscribers read the partitions from the log
+	if plog != nil {
+		if err := plog.subscribe(sub); err != nil {
+			return nil, err
+		}
+
+		go func() {
+			<-sub.exit
+			plog.unsubscribe(sub)
+		}()
+
+		return sub, nil
 	}
 
 	m.Lock()
@@ -167,6 +240,7 @@ func (m *memoryEvent) Message() *broker.Message {
 }
 
 func (m *memoryEvent) Ack() error {
+	m.acked = true
 	return nil
 }
 
@@ -179,6 +253,7 @@ func (m *memorySubscriber) Topic() string {
 }
 
 func (m *memorySubscriber) Unsubscribe() error {
+	m.stop()
 	m.exit <- true
 	return nil
 }
@@ -2,7 +2,10 @@ package memory
 
 import (
 	"fmt"
+	"runtime"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/micro/go-micro/broker"
 )
@@ -48,3 +51,334 @@ func TestMemoryBroker(t *testing.T) {
 		t.Fatalf("Unexpected connect error %v", err)
 	}
 }
+
+func TestMemoryBrokerDeadLetter(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	var attempts int32
+
+	fn := func(p broker.Event) error {
+		return fmt.Errorf("failed %d", atomic.AddInt32(&attempts, 1))
+	}
+
+	noBackoff := func(int) time.Duration { return 0 }
+
+	if _, err := b.Subscribe("test", fn, broker.MaxAttempts(3), broker.Backoff(noBackoff), broker.DeadLetter("dead")); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	deadc := make(chan *broker.Message, 1)
+
+	if _, err := b.Subscribe("dead", func(p broker.Event) error {
+		deadc <- p.Message()
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	if err := b.Publish("test", &broker.Message{
+		Header: map[string]string{"foo": "bar"},
+		Body:   []byte("hello"),
+	}); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+
+	var dead *broker.Message
+
+	// retries happen in the background
+	select {
+	case dead = <-deadc:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for dead letter")
+	}
+
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Fatalf("Expected 3 attempts got %d", n)
+	}
+	if dead == nil {
+		t.Fatal("Expected message to be dead lettered")
+	}
+	if dead.Header["foo"] != "bar" || string(dead.Body) != "hello" {
+		t.Fatalf("Expected original message got %+v", dead)
+	}
+	if dead.Header[broker.DeadLetterTopicHeader] != "test" {
+		t.Fatalf("Expected topic header got %s", dead.Header[broker.DeadLetterTopicHeader])
+	}
+	if dead.Header[broker.DeadLetterErrorHeader] != "failed 3" {
+		t.Fatalf("Expected error header got %s", dead.Header[broker.DeadLetterErrorHeader])
+	}
+}
+
+func TestMemoryBrokerRetryBackoff(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	fn := func(p broker.Event) error {
+		return fmt.Errorf("failed")
+	}
+
+	if _, err := b.Subscribe("test", fn, broker.MaxAttempts(2), broker.Backoff(func(int) time.Duration {
+		return time.Minute
+	})); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	// the backoff doesn't hold up the publisher
+	start := time.Now()
+	if err := b.Publish("test", &broker.Message{Body: []byte("hello")}); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Expected publish to return before the backoff, took %v", d)
+	}
+}
+
+func TestMemoryBrokerRetryUnsubscribe(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	calls := make(chan bool, 10)
+	fn := func(p broker.Event) error {
+		calls <- true
+		return fmt.Errorf("failed")
+	}
+
+	sub, err := b.Subscribe("test", fn, broker.MaxAttempts(3), broker.Backoff(func(int) time.Duration {
+		return 50 * time.Millisecond
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	if err := b.Publish("test", &broker.Message{Body: []byte("hello")}); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+	<-calls
+
+	// retries stop once unsubscribed
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unexpected error unsubscribing %v", err)
+	}
+
+	select {
+	case <-calls:
+		t.Fatal("Expected no retries after unsubscribing")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestMemoryBrokerDelay(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	received := make(chan time.Time, 1)
+
+	if _, err := b.Subscribe("test", func(p broker.Event) error {
+		received <- time.Now()
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	delay := 50 * time.Millisecond
+	start := time.Now()
+
+	if err := b.Publish("test", &broker.Message{Body: []byte("hello")}, broker.Delay(delay)); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+
+	select {
+	case at := <-received:
+		if at.Sub(start) < delay {
+			t.Fatalf("Message delivered after %v, expected at least %v", at.Sub(start), delay)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for delayed message")
+	}
+}
+
+func TestMemoryBrokerPartitions(t *testing.T) {
+	b := NewBroker(Partitions(4))
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	type result struct {
+		key string
+		seq int
+	}
+
+	recv := make(chan result, 100)
+	fn := func(p broker.Event) error {
+		var seq int
+		fmt.Sscanf(p.Message().Header["seq"], "%d", &seq)
+		recv <- result{p.Message().Header[DefaultPartitionKey], seq}
+		return nil
+	}
+
+	publish := func(from, to int) {
+		for i := from; i < to; i++ {
+			message := &broker.Message{
+				Header: map[string]string{
+					DefaultPartitionKey: fmt.Sprintf("key-%d", i%3),
+					"seq":               fmt.Sprintf("%d", i),
+				},
+			}
+			if err := b.Publish("test", message); err != nil {
+				t.Fatalf("Unexpected error publishing %d: %v", i, err)
+			}
+		}
+	}
+
+	wait := func(n int) []result {
+		var results []result
+		for i := 0; i < n; i++ {
+			select {
+			case r := <-recv:
+				results = append(results, r)
+			case <-time.After(time.Second):
+				t.Fatalf("Expected %d messages got %d", n, len(results))
+			}
+		}
+		return results
+	}
+
+	sub, err := b.Subscribe("test", fn, broker.Queue("q"))
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	publish(0, 10)
+
+	// messages with the same key are received in order
+	last := make(map[string]int)
+	for _, r := range wait(10) {
+		if seq, ok := last[r.key]; ok && seq > r.seq {
+			t.Fatalf("Received %d after %d for %s", r.seq, seq, r.key)
+		}
+		last[r.key] = r.seq
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unexpected error unsubscribing %v", err)
+	}
+	// let the queue stop consuming
+	time.Sleep(10 * time.Millisecond)
+
+	publish(10, 15)
+	mid := time.Now()
+	publish(15, 20)
+
+	// the queue resumes from its committed offsets
+	sub, err = b.Subscribe("test", fn, broker.Queue("q"))
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+	for _, r := range wait(10) {
+		if r.seq < 10 {
+			t.Fatalf("Unexpected redelivery of %d", r.seq)
+		}
+	}
+	sub.Unsubscribe()
+
+	// replay every message from the start of the log
+	sub, err = b.Subscribe("test", fn, broker.StartFromEarliest())
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+	if got := len(wait(20)); got != 20 {
+		t.Fatalf("Expected 20 replayed messages got %d", got)
+	}
+	sub.Unsubscribe()
+
+	// replay messages published after a time
+	sub, err = b.Subscribe("test", fn, broker.StartFromTime(mid))
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+	for _, r := range wait(5) {
+		if r.seq < 15 {
+			t.Fatalf("Unexpected message %d published before start time", r.seq)
+		}
+	}
+	sub.Unsubscribe()
+
+	select {
+	case r := <-recv:
+		t.Fatalf("Unexpected message %d", r.seq)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryBrokerPartitionsDisconnect(t *testing.T) {
+	b := NewBroker(Partitions(4))
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	before := runtime.NumGoroutine()
+
+	if _, err := b.Subscribe("test", func(broker.Event) error { return nil }); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	// a consumer per partition
+	if n := runtime.NumGoroutine(); n < before+4 {
+		t.Fatalf("Expected at least %d goroutines got %d", before+4, n)
+	}
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error %v", err)
+	}
+
+	// the consumers stop on disconnect
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before+1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected consumers to stop, %d goroutines left", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if b.(*memoryBroker).log != nil {
+		t.Fatal("Expected the partition log to be reset")
+	}
+
+	// a new log is used once connected again
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	recv := make(chan bool, 1)
+	if _, err := b.Subscribe("test", func(broker.Event) error {
+		recv <- true
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	if err := b.Publish("test", &broker.Message{Body: []byte("hello")}); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+
+	select {
+	case <-recv:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for message")
+	}
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/store"
+	"github.com/micro/go-micro/sync/event"
+)
+
+type partitionsKey struct{}
+type partitionKeyKey struct{}
+type eventKey struct{}
+type offsetsKey struct{}
+
+func setOption(k, v interface{}) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// Partitions splits each topic into n ordered partitions kept in a log.
+// Messages are replayable and queue subscribers track committed offsets.
+func Partitions(n int) broker.Option {
+	return setOption(partitionsKey{}, n)
+}
+
+// PartitionKey sets the message header used to pick the partition. Messages
+// with the same key are delivered in order. Defaults to DefaultPartitionKey.
+func PartitionKey(header string) broker.Option {
+	return setOption(partitionKeyKey{}, header)
+}
+
+// Log sets the event log partitions are kept in. Use a file
+// backed log for messages to survive restarts of the broker.
+func Log(e event.Event) broker.Option {
+	return setOption(eventKey{}, e)
+}
+
+// Offsets sets the store committed queue offsets are kept in
+func Offsets(s store.Store) broker.Option {
+	return setOption(offsetsKey{}, s)
+}
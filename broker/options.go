@@ -3,6 +3,7 @@ package broker
 import (
 	"context"
 	"crypto/tls"
+	"time"
 
 	"github.com/micro/go-micro/codec"
 	"github.com/micro/go-micro/registry"
@@ -13,12 +14,20 @@ type Options struct {
 	Secure    bool
 	Codec     codec.Marshaler
 	TLSConfig *tls.Config
+	// Compression is the encoding of messages for subscribers
+	// accepting it, empty disables it. Supported by the http broker.
+	Compression string
+	// CompressionThreshold is the message size in bytes
+	// from which messages are compressed
+	CompressionThreshold int
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
 }
 
 type PublishOptions struct {
+	// DeliverAt delays delivery of the message until the given time
+	DeliverAt time.Time
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -32,6 +41,21 @@ type SubscribeOptions struct {
 	// will create a shared subscription where each
 	// receives a subset of messages.
 	Queue string
+	// MaxAttempts is the number of times the handler is called
+	// for a message before giving up. Zero means no retries.
+	MaxAttempts int
+	// Backoff returns the time to wait before retrying. Brokers
+	// which redeliver unacked messages use their own backoff.
+	Backoff BackoffFunc
+	// DeadLetter is the topic messages are published
+	// to once every delivery attempt has failed.
+	DeadLetter string
+	// StartPosition is where a subscriber starts reading a
+	// topic when its queue has no committed offset. Only
+	// supported by brokers which keep a log of messages.
+	StartPosition StartPosition
+	// StartTime is the time to start from with StartTimestamp
+	StartTime time.Time
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -40,6 +64,18 @@ type SubscribeOptions struct {
 
 type Option func(*Options)
 
+// StartPosition is where a subscriber starts reading a topic
+type StartPosition int
+
+const (
+	// StartLatest receives messages published after subscribing
+	StartLatest StartPosition = iota
+	// StartEarliest receives every message in the log
+	StartEarliest
+	// StartTimestamp receives messages published from StartTime
+	StartTimestamp
+)
+
 type PublishOption func(*PublishOptions)
 
 type SubscribeOption func(*SubscribeOptions)
@@ -75,6 +111,34 @@ func Codec(c codec.Marshaler) Option {
 	}
 }
 
+// Compression sets the encoding used to compress messages e.g. gzip
+func Compression(name string) Option {
+	return func(o *Options) {
+		o.Compression = name
+	}
+}
+
+// CompressionThreshold sets the message size in bytes from which messages are compressed
+func CompressionThreshold(n int) Option {
+	return func(o *Options) {
+		o.CompressionThreshold = n
+	}
+}
+
+// Delay delivers the message after d
+func Delay(d time.Duration) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = time.Now().Add(d)
+	}
+}
+
+// DeliverAt delivers the message at t
+func DeliverAt(t time.Time) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = t
+	}
+}
+
 // DisableAutoAck will disable auto acking of messages
 // after they have been handled.
 func DisableAutoAck() SubscribeOption {
@@ -83,6 +147,51 @@ func DisableAutoAck() SubscribeOption {
 	}
 }
 
+// MaxAttempts sets the number of times a handler is called
+// for a message before it is dead lettered or dropped
+func MaxAttempts(n int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.MaxAttempts = n
+	}
+}
+
+// Backoff sets the time to wait between delivery attempts
+func Backoff(fn BackoffFunc) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Backoff = fn
+	}
+}
+
+// DeadLetter sets the topic messages are published
+// to once every delivery attempt has failed
+func DeadLetter(topic string) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.DeadLetter = topic
+	}
+}
+
+// StartFromLatest only receives messages published after subscribing
+func StartFromLatest() SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.StartPosition = StartLatest
+	}
+}
+
+// StartFromEarliest replays every message kept for the topic
+func StartFromEarliest() SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.StartPosition = StartEarliest
+	}
+}
+
+// StartFromTime replays messages published from t
+func StartFromTime(t time.Time) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.StartPosition = StartTimestamp
+		o.StartTime = t
+	}
+}
+
 // Queue sets the name of the queue to share messages on
 func Queue(name string) SubscribeOption {
 	return func(o *SubscribeOptions) {
@@ -0,0 +1,202 @@
+package broker
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/util/backoff"
+	"github.com/micro/go-micro/util/log"
+)
+
+const (
+	// DeadLetterTopicHeader is the topic a dead lettered message was published to
+	DeadLetterTopicHeader = "Micro-Dead-Letter-Topic"
+	// DeadLetterErrorHeader is the error returned by the last delivery attempt
+	DeadLetterErrorHeader = "Micro-Dead-Letter-Error"
+	// DeadLetterAttemptsHeader is the number of delivery attempts made
+	DeadLetterAttemptsHeader = "Micro-Dead-Letter-Attempts"
+	// DeliveryAttemptHeader is the delivery attempt of a message, starting
+	// at 1. It's set by brokers which redeliver unacked messages.
+	DeliveryAttemptHeader = "Micro-Delivery-Attempt"
+)
+
+// BackoffFunc returns the time to wait before a delivery attempt
+type BackoffFunc func(attempt int) time.Duration
+
+// DefaultBackoff waits ten times longer after each attempt, up to a minute
+func DefaultBackoff(attempt int) time.Duration {
+	// stop before the backoff overflows
+	if attempt > 4 {
+		return time.Minute
+	}
+	if d := backoff.Do(attempt); d < time.Minute {
+		return d
+	}
+	return time.Minute
+}
+
+// retryEvent is an event retried after the original was acked
+type retryEvent struct {
+	Event
+}
+
+func (r *retryEvent) Ack() error {
+	return nil
+}
+
+// Attempt returns the delivery attempt of a message set by a broker
+// which redelivers unacked messages, or false for other brokers
+func Attempt(e Event) (int, bool) {
+	msg := e.Message()
+	if msg == nil {
+		return 0, false
+	}
+	v, ok := msg.Header[DeliveryAttemptHeader]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 1, true
+	}
+	return n, true
+}
+
+// RetryHandler wraps the handler so failed deliveries are retried as set
+// by the MaxAttempts and Backoff options. Messages which fail every attempt
+// are published to the DeadLetter topic with the original headers and the
+// error, otherwise they're dropped.
+//
+// Brokers which redeliver unacked messages set the DeliveryAttemptHeader and
+// failed messages are left unacked for the broker to redeliver with its own
+// backoff, as are messages of subscribers acking themselves. For other brokers
+// the message is acked and retried in the background. The returned function
+// cancels the retries scheduled so far and is called on unsubscribing or
+// disconnecting.
+func RetryHandler(b Broker, h Handler, opts SubscribeOptions) (Handler, func()) {
+	if opts.MaxAttempts <= 1 && len(opts.DeadLetter) == 0 {
+		return h, func() {}
+	}
+
+	attempts := opts.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	bf := opts.Backoff
+	if bf == nil {
+		bf = DefaultBackoff
+	}
+
+	deadLetter := func(e Event, err error) error {
+		if len(opts.DeadLetter) == 0 {
+			return err
+		}
+
+		msg := e.Message()
+		header := make(map[string]string, len(msg.Header)+3)
+		for k, v := range msg.Header {
+			header[k] = v
+		}
+		delete(header, DeliveryAttemptHeader)
+		header[DeadLetterTopicHeader] = e.Topic()
+		header[DeadLetterErrorHeader] = err.Error()
+		header[DeadLetterAttemptsHeader] = strconv.Itoa(attempts)
+
+		return b.Publish(opts.DeadLetter, &Message{
+			Header: header,
+			Body:   msg.Body,
+		})
+	}
+
+	// retries scheduled in the background, cancelled
+	// by moving on to the next generation
+	var mtx sync.Mutex
+	var gen int
+	timers := make(map[*time.Timer]bool)
+
+	stop := func() {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		gen++
+		for t := range timers {
+			t.Stop()
+		}
+		timers = make(map[*time.Timer]bool)
+	}
+
+	var retry func(e Event, attempt, g int)
+	retry = func(e Event, attempt, g int) {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		if g != gen {
+			return
+		}
+
+		var t *time.Timer
+		t = time.AfterFunc(bf(attempt), func() {
+			mtx.Lock()
+			if g != gen {
+				mtx.Unlock()
+				return
+			}
+			delete(timers, t)
+			mtx.Unlock()
+
+			err := h(e)
+			if err == nil {
+				return
+			}
+			if attempt+1 < attempts {
+				retry(e, attempt+1, g)
+				return
+			}
+			if err := deadLetter(e, err); err != nil {
+				log.Logf("Dropping message to %s after %d attempts: %v", e.Topic(), attempts, err)
+			}
+		})
+		timers[t] = true
+	}
+
+	handler := func(e Event) error {
+		mtx.Lock()
+		g := gen
+		mtx.Unlock()
+
+		err := h(e)
+		if err == nil {
+			return nil
+		}
+
+		// leave the message unacked for the broker to redeliver
+		attempt, redelivered := Attempt(e)
+		if redelivered || !opts.AutoAck {
+			if attempt < 1 {
+				attempt = 1
+			}
+			if attempt < attempts {
+				return err
+			}
+			if derr := deadLetter(e, err); derr != nil {
+				return err
+			}
+			e.Ack()
+			return nil
+		}
+
+		if attempts > 1 {
+			retry(&retryEvent{e}, 1, g)
+		} else if derr := deadLetter(e, err); derr != nil {
+			return err
+		}
+
+		// the message is now dead lettered or will
+		// be delivered again by the retries
+		return nil
+	}
+
+	return handler, stop
+}
@@ -0,0 +1,248 @@
+// Package breaker is a circuit breaker for client calls. Nodes and
+// services which keep failing have their circuit opened so calls fail
+// fast and the selector stops picking them until they have recovered.
+package breaker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/registry"
+)
+
+// ErrorId is the id of the error calls fail with while the circuit is open
+const ErrorId = "go.micro.client.breaker"
+
+var (
+	DefaultThreshold        = 5
+	DefaultServiceThreshold = 0
+	DefaultTimeout          = 10 * time.Second
+	DefaultHalfOpenRequests = 1
+)
+
+// State is the state of a circuit
+type State int
+
+const (
+	// Closed circuits let every call through
+	Closed State = iota
+	// Open circuits fail calls without making them
+	Open
+	// HalfOpen circuits let probe calls through to
+	// decide whether to close or open again
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker tracks circuits per node and per service. It implements
+// selector.Breaker so it can be set on the registry selector.
+type Breaker struct {
+	opts Options
+
+	sync.Mutex
+	// circuits keyed by service and node id
+	nodes    map[string]map[string]*circuit
+	services map[string]*circuit
+}
+
+type circuit struct {
+	state     State
+	failures  int
+	opened    time.Time
+	probes    int
+	successes int
+}
+
+// available returns whether a call may be made without claiming a probe
+func (c *circuit) available(opts Options) bool {
+	switch c.state {
+	case Open:
+		return time.Since(c.opened) >= opts.Timeout
+	case HalfOpen:
+		return c.probes < opts.HalfOpenRequests
+	default:
+		return true
+	}
+}
+
+// allow claims a probe for the call when the circuit is half open
+func (c *circuit) allow() {
+	if c.state == Open {
+		c.state = HalfOpen
+		c.probes = 0
+		c.successes = 0
+	}
+	if c.state == HalfOpen {
+		c.probes++
+	}
+}
+
+func (c *circuit) mark(failed bool, threshold int, opts Options) {
+	switch {
+	case c.state == HalfOpen && failed:
+		c.open()
+	case c.state == HalfOpen:
+		c.successes++
+		if c.successes >= opts.HalfOpenRequests {
+			*c = circuit{}
+		}
+	case failed:
+		c.failures++
+		if threshold > 0 && c.failures >= threshold {
+			c.open()
+		}
+	default:
+		c.failures = 0
+	}
+}
+
+func (c *circuit) open() {
+	c.state = Open
+	c.opened = time.Now()
+	c.failures = 0
+}
+
+func (b *Breaker) node(service string, node *registry.Node) *circuit {
+	nodes, ok := b.nodes[service]
+	if !ok {
+		nodes = make(map[string]*circuit)
+		b.nodes[service] = nodes
+	}
+	c, ok := nodes[node.Id]
+	if !ok {
+		c = new(circuit)
+		nodes[node.Id] = c
+	}
+	return c
+}
+
+func (b *Breaker) service(service string) *circuit {
+	c, ok := b.services[service]
+	if !ok {
+		c = new(circuit)
+		b.services[service] = c
+	}
+	return c
+}
+
+// Available returns false while the circuit for the node or its service is open
+func (b *Breaker) Available(service string, node *registry.Node) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.service(service).available(b.opts) && b.node(service, node).available(b.opts)
+}
+
+// Allow returns whether a call may be made to the node.
+// Half open circuits only allow a limited number of probes.
+func (b *Breaker) Allow(service string, node *registry.Node) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	sc := b.service(service)
+	nc := b.node(service, node)
+
+	if !sc.available(b.opts) || !nc.available(b.opts) {
+		return false
+	}
+
+	sc.allow()
+	nc.allow()
+	return true
+}
+
+// Mark records the result of a call to the node
+func (b *Breaker) Mark(service string, node *registry.Node, err error) {
+	// calls failed by an open circuit weren't made
+	if err != nil {
+		if e := errors.Parse(err.Error()); e.Id == ErrorId {
+			return
+		}
+	}
+
+	failed := b.opts.Failure(err)
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.service(service).mark(failed, b.opts.ServiceThreshold, b.opts)
+	b.node(service, node).mark(failed, b.opts.Threshold, b.opts)
+}
+
+// Reset closes every circuit for the service
+func (b *Breaker) Reset(service string) {
+	b.Lock()
+	defer b.Unlock()
+
+	delete(b.services, service)
+	delete(b.nodes, service)
+}
+
+// State returns the state of the circuit for the node
+func (b *Breaker) State(service string, node *registry.Node) State {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.node(service, node).state
+}
+
+// ServiceState returns the state of the circuit for the service
+func (b *Breaker) ServiceState(service string) State {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.service(service).state
+}
+
+// NewCallWrapper returns a call wrapper which fails calls to nodes with
+// an open circuit with a 503 and records the result of every call it makes
+func NewCallWrapper(b *Breaker) client.CallWrapper {
+	return func(cf client.CallFunc) client.CallFunc {
+		return func(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
+			service := req.Service()
+
+			if !b.Allow(service, node) {
+				return errors.New(ErrorId, fmt.Sprintf("circuit open for %s node %s", service, node.Id), 503)
+			}
+
+			err := cf(ctx, node, req, rsp, opts)
+			b.Mark(service, node, err)
+			return err
+		}
+	}
+}
+
+// NewBreaker returns a circuit breaker
+func NewBreaker(opts ...Option) *Breaker {
+	options := Options{
+		Threshold:        DefaultThreshold,
+		ServiceThreshold: DefaultServiceThreshold,
+		Timeout:          DefaultTimeout,
+		HalfOpenRequests: DefaultHalfOpenRequests,
+		Failure:          IsFailure,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Breaker{
+		opts:     options,
+		nodes:    make(map[string]map[string]*circuit),
+		services: make(map[string]*circuit),
+	}
+}
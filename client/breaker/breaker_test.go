@@ -0,0 +1,164 @@
+package breaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/client/selector"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/registry"
+	"github.com/micro/go-micro/registry/memory"
+)
+
+func TestBreaker(t *testing.T) {
+	b := NewBreaker(Threshold(3), Timeout(10*time.Millisecond), HalfOpenRequests(2))
+	node := &registry.Node{Id: "foo-1"}
+	failure := errors.InternalServerError("foo", "failed")
+
+	for i := 0; i < 3; i++ {
+		if !b.Allow("foo", node) {
+			t.Fatalf("Expected call %d to be allowed", i)
+		}
+		b.Mark("foo", node, failure)
+	}
+
+	if s := b.State("foo", node); s != Open {
+		t.Fatalf("Expected open circuit got %s", s)
+	}
+	if b.Allow("foo", node) || b.Available("foo", node) {
+		t.Fatal("Expected open circuit to reject calls")
+	}
+
+	// client errors don't count against the node
+	b.Reset("foo")
+	for i := 0; i < 5; i++ {
+		b.Mark("foo", node, errors.NotFound("foo", "not found"))
+	}
+	if s := b.State("foo", node); s != Closed {
+		t.Fatalf("Expected closed circuit got %s", s)
+	}
+
+	for i := 0; i < 3; i++ {
+		b.Mark("foo", node, failure)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// the timeout lets a limited number of probes through
+	if !b.Allow("foo", node) || !b.Allow("foo", node) {
+		t.Fatal("Expected probes to be allowed")
+	}
+	if s := b.State("foo", node); s != HalfOpen {
+		t.Fatalf("Expected half open circuit got %s", s)
+	}
+	if b.Allow("foo", node) {
+		t.Fatal("Expected probes to be limited")
+	}
+
+	b.Mark("foo", node, nil)
+	b.Mark("foo", node, nil)
+	if s := b.State("foo", node); s != Closed {
+		t.Fatalf("Expected closed circuit got %s", s)
+	}
+
+	// a failed probe opens the circuit again
+	for i := 0; i < 3; i++ {
+		b.Mark("foo", node, failure)
+	}
+	time.Sleep(10 * time.Millisecond)
+	b.Allow("foo", node)
+	b.Mark("foo", node, failure)
+	if s := b.State("foo", node); s != Open {
+		t.Fatalf("Expected open circuit got %s", s)
+	}
+}
+
+func TestServiceBreaker(t *testing.T) {
+	b := NewBreaker(Threshold(10), ServiceThreshold(3))
+	failure := errors.InternalServerError("foo", "failed")
+
+	for _, id := range []string{"foo-1", "foo-2", "foo-3"} {
+		b.Mark("foo", &registry.Node{Id: id}, failure)
+	}
+
+	if s := b.ServiceState("foo"); s != Open {
+		t.Fatalf("Expected open service circuit got %s", s)
+	}
+	if b.Available("foo", &registry.Node{Id: "foo-new"}) {
+		t.Fatal("Expected open service circuit to reject every node")
+	}
+	if !b.Available("bar", &registry.Node{Id: "bar-1"}) {
+		t.Fatal("Expected other services to be available")
+	}
+}
+
+func TestCallWrapper(t *testing.T) {
+	b := NewBreaker(Threshold(2))
+	node := &registry.Node{Id: "foo-1"}
+
+	var calls int
+	cf := NewCallWrapper(b)(func(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
+		calls++
+		return errors.InternalServerError("foo", "failed")
+	})
+
+	req := client.NewRequest("foo", "Foo.Bar", nil)
+	var err error
+	for i := 0; i < 3; i++ {
+		err = cf(context.TODO(), node, req, nil, client.CallOptions{})
+	}
+
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls before the circuit opened got %d", calls)
+	}
+
+	// open circuits fail with an unavailable error the client retries
+	if e := errors.Parse(err.Error()); e.Code != 503 || e.Id != ErrorId {
+		t.Fatalf("Expected a 503 %s error got %v", ErrorId, err)
+	}
+	if retry, _ := client.RetryOnError(context.TODO(), req, 1, err); !retry {
+		t.Fatal("Expected the call to be retried on another node")
+	}
+}
+
+func TestSelector(t *testing.T) {
+	r := memory.NewRegistry(memory.Services(map[string][]*registry.Service{
+		"foo": {
+			{
+				Name:    "foo",
+				Version: "1.0.0",
+				Nodes: []*registry.Node{
+					{Id: "foo-1", Address: "localhost:9999"},
+					{Id: "foo-2", Address: "localhost:9998"},
+				},
+			},
+		},
+	}))
+
+	b := NewBreaker(Threshold(1))
+	s := selector.NewSelector(selector.Registry(r), selector.SetBreaker(b))
+
+	b.Mark("foo", &registry.Node{Id: "foo-1"}, errors.InternalServerError("foo", "failed"))
+
+	next, err := s.Select("foo")
+	if err != nil {
+		t.Fatalf("Unexpected error selecting %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		node, err := next()
+		if err != nil {
+			t.Fatalf("Unexpected error getting next node %v", err)
+		}
+		if node.Id != "foo-2" {
+			t.Fatalf("Expected node with open circuit to be skipped got %s", node.Id)
+		}
+	}
+
+	// circuits opened after select are skipped by next
+	b.Mark("foo", &registry.Node{Id: "foo-2"}, errors.InternalServerError("foo", "failed"))
+	if _, err := next(); err != selector.ErrNoneAvailable {
+		t.Fatalf("Expected none available got %v", err)
+	}
+}
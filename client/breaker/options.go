@@ -0,0 +1,84 @@
+package breaker
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/errors"
+)
+
+type Options struct {
+	// Threshold is the number of consecutive failures
+	// after which the circuit for a node opens
+	Threshold int
+	// ServiceThreshold is the number of consecutive failures
+	// across all nodes after which the circuit for a service
+	// opens. Zero disables the service circuit.
+	ServiceThreshold int
+	// Timeout is how long a circuit stays open
+	// before calls are let through to probe it
+	Timeout time.Duration
+	// HalfOpenRequests is the number of probe calls which
+	// must succeed before a half open circuit closes
+	HalfOpenRequests int
+	// Failure decides whether an error counts against a circuit
+	Failure func(err error) bool
+}
+
+type Option func(o *Options)
+
+// Threshold sets the consecutive failures which open a node circuit
+func Threshold(n int) Option {
+	return func(o *Options) {
+		o.Threshold = n
+	}
+}
+
+// ServiceThreshold sets the consecutive failures which open a service circuit
+func ServiceThreshold(n int) Option {
+	return func(o *Options) {
+		o.ServiceThreshold = n
+	}
+}
+
+// Timeout sets how long a circuit stays open
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// HalfOpenRequests sets the number of successful probes needed to close a circuit
+func HalfOpenRequests(n int) Option {
+	return func(o *Options) {
+		o.HalfOpenRequests = n
+	}
+}
+
+// Failure sets the func deciding which errors count as failures
+func Failure(fn func(err error) bool) Option {
+	return func(o *Options) {
+		o.Failure = fn
+	}
+}
+
+// IsFailure counts every error as a failure apart from those
+// caused by the request itself, such as bad requests or not found
+func IsFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	e := errors.Parse(err.Error())
+	if e.Code == 0 {
+		return true
+	}
+
+	switch {
+	case e.Code == 408, e.Code == 429:
+		return true
+	case e.Code >= 400 && e.Code < 500:
+		return false
+	default:
+		return true
+	}
+}
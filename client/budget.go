@@ -0,0 +1,80 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// seconds of calls the budget is calculated over
+	budgetWindow = 10
+)
+
+// Budget limits retries and hedged requests to a percentage of calls
+// so extra attempts don't overwhelm a service which is already failing.
+// A budget is shared by every call it's set on.
+type Budget struct {
+	ratio float64
+	min   int
+
+	sync.Mutex
+	// counts for each second of the window
+	requests [budgetWindow]int
+	retries  [budgetWindow]int
+	last     int64
+}
+
+// NewBudget returns a budget which allows retries up to percent of
+// calls, with minPerSecond retries always allowed when traffic is low
+func NewBudget(percent float64, minPerSecond int) *Budget {
+	return &Budget{
+		ratio: percent / 100,
+		min:   minPerSecond,
+		last:  time.Now().Unix(),
+	}
+}
+
+// advance clears the counts older than the window
+func (b *Budget) advance() int {
+	now := time.Now().Unix()
+
+	for s := b.last + 1; s <= now && s <= b.last+budgetWindow; s++ {
+		b.requests[s%budgetWindow] = 0
+		b.retries[s%budgetWindow] = 0
+	}
+	if now > b.last {
+		b.last = now
+	}
+
+	return int(now % budgetWindow)
+}
+
+// Record counts a call against the budget
+func (b *Budget) Record() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.requests[b.advance()]++
+}
+
+// Allow returns whether another attempt may be made
+// and counts it against the budget if so
+func (b *Budget) Allow() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	i := b.advance()
+
+	var requests, retries int
+	for j := 0; j < budgetWindow; j++ {
+		requests += b.requests[j]
+		retries += b.retries[j]
+	}
+
+	if float64(retries) >= float64(b.min*budgetWindow)+float64(requests)*b.ratio {
+		return false
+	}
+
+	b.retries[i]++
+	return true
+}
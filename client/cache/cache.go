@@ -0,0 +1,270 @@
+// Package cache is a client wrapper caching the responses of calls.
+// Services invalidate the cached responses of their endpoints by
+// publishing a message on the invalidation topic when they write.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/codec"
+	jsonc "github.com/micro/go-micro/codec/json"
+	protoc "github.com/micro/go-micro/codec/proto"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/store"
+	"github.com/micro/go-micro/store/memory"
+	"github.com/micro/go-micro/util/log"
+)
+
+var (
+	// DefaultTopic is the topic invalidation messages are published on
+	DefaultTopic = "go.micro.cache.invalidate"
+
+	// DefaultMetadata are the metadata keys responses are cached by
+	// so the response of one caller isn't returned to another
+	DefaultMetadata = []string{"Authorization"}
+
+	// how long to wait before subscribing again after an error
+	subscribeBackoff = time.Second * 10
+)
+
+// Invalidation drops the cached responses of a service
+type Invalidation struct {
+	Service string `json:"service"`
+	// Endpoints to drop, all the endpoints if empty
+	Endpoints []string `json:"endpoints,omitempty"`
+}
+
+type cacheWrapper struct {
+	client.Client
+	opts Options
+
+	sync.Mutex
+	// subscribed to invalidation messages
+	subscribed bool
+	// time to subscribe again after an error
+	retry time.Time
+
+	// generations are bumped when the responses of a service are
+	// invalidated so responses of calls made before aren't cached
+	gmtx        sync.Mutex
+	generations map[string]uint64
+}
+
+func key(service, endpoint string) string {
+	return service + "/" + endpoint
+}
+
+func marshaler(v interface{}) codec.Marshaler {
+	if _, ok := v.(proto.Message); ok {
+		return protoc.Marshaler{}
+	}
+	return jsonc.Marshaler{}
+}
+
+// ttl returns how long responses of the endpoint are cached
+func (c *cacheWrapper) ttl(service, endpoint string) time.Duration {
+	if d, ok := c.opts.Endpoints[key(service, endpoint)]; ok {
+		return d
+	}
+	return c.opts.TTL
+}
+
+// subscribe receives invalidation messages from the broker.
+// Failed subscriptions are retried on later calls.
+func (c *cacheWrapper) subscribe() {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.subscribed || time.Now().Before(c.retry) {
+		return
+	}
+
+	b := c.opts.Broker
+	if b == nil {
+		b = c.Client.Options().Broker
+	}
+
+	_, err := b.Subscribe(c.opts.Topic, func(e broker.Event) error {
+		var inv Invalidation
+		if err := json.Unmarshal(e.Message().Body, &inv); err != nil {
+			return err
+		}
+		return c.invalidate(inv)
+	})
+	if err != nil {
+		log.Logf("Error subscribing to cache invalidations on %s: %v", c.opts.Topic, err)
+		c.retry = time.Now().Add(subscribeBackoff)
+		return
+	}
+
+	c.subscribed = true
+}
+
+// metadata returns the values of the metadata keys responses are cached by
+func (c *cacheWrapper) metadata(ctx context.Context) []string {
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var vals []string
+	for _, k := range c.opts.Metadata {
+		for mk, mv := range md {
+			// grpc lower cases the headers
+			if strings.EqualFold(mk, k) {
+				vals = append(vals, k+"="+mv)
+			}
+		}
+	}
+	return vals
+}
+
+// generation returns the generation of the responses of the service
+func (c *cacheWrapper) generation(service string) uint64 {
+	c.gmtx.Lock()
+	defer c.gmtx.Unlock()
+	return c.generations[service]
+}
+
+// invalidate deletes the cached responses
+func (c *cacheWrapper) invalidate(inv Invalidation) error {
+	c.gmtx.Lock()
+	c.generations[inv.Service]++
+	c.gmtx.Unlock()
+
+	prefixes := []string{inv.Service + "/"}
+	if len(inv.Endpoints) > 0 {
+		prefixes = prefixes[:0]
+		for _, ep := range inv.Endpoints {
+			prefixes = append(prefixes, key(inv.Service, ep)+"/")
+		}
+	}
+
+	for _, prefix := range prefixes {
+		recs, err := c.opts.Store.List(store.ListPrefix(prefix))
+		if err != nil {
+			return err
+		}
+		if len(recs) == 0 {
+			continue
+		}
+
+		keys := make([]string, 0, len(recs))
+		for _, rec := range recs {
+			keys = append(keys, rec.Key)
+		}
+		if err := c.opts.Store.Delete(keys...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cacheWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	ttl := c.ttl(req.Service(), req.Endpoint())
+	if ttl <= 0 || req.Stream() {
+		return c.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	c.subscribe()
+
+	// key the response by the marshalled request and metadata
+	b, err := marshaler(req.Body()).Marshal(req.Body())
+	if err != nil {
+		return c.Client.Call(ctx, req, rsp, opts...)
+	}
+	for _, v := range c.metadata(ctx) {
+		b = append(b, '\n')
+		b = append(b, v...)
+	}
+	sum := sha256.Sum256(b)
+	k := key(req.Service(), req.Endpoint()) + "/" + hex.EncodeToString(sum[:])
+
+	if recs, err := c.opts.Store.Read(k); err == nil {
+		if err := marshaler(rsp).Unmarshal(recs[0].Value, rsp); err == nil {
+			return nil
+		}
+	}
+
+	gen := c.generation(req.Service())
+
+	if err := c.Client.Call(ctx, req, rsp, opts...); err != nil {
+		return err
+	}
+
+	v, err := marshaler(rsp).Marshal(rsp)
+	if err != nil {
+		return nil
+	}
+
+	// hold the lock while writing so an invalidation
+	// can't be missed between the check and the write
+	c.gmtx.Lock()
+	defer c.gmtx.Unlock()
+
+	// the response may be stale if it was invalidated during the call
+	if c.generations[req.Service()] != gen {
+		return nil
+	}
+
+	if err := c.opts.Store.Write(&store.Record{Key: k, Value: v, Expiry: ttl}); err != nil {
+		log.Logf("Error caching response of %s: %v", k, err)
+	}
+
+	return nil
+}
+
+// NewClientWrapper returns a client wrapper caching the responses of calls
+// keyed by service, endpoint, the marshalled request and the values of the
+// metadata keys. Only successful responses are cached.
+func NewClientWrapper(opts ...Option) client.Wrapper {
+	options := Options{
+		Topic:    DefaultTopic,
+		Metadata: append([]string(nil), DefaultMetadata...),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Store == nil {
+		options.Store = memory.NewStore()
+	}
+
+	return func(c client.Client) client.Client {
+		return &cacheWrapper{
+			Client:      c,
+			opts:        options,
+			generations: make(map[string]uint64),
+		}
+	}
+}
+
+// Invalidate publishes a message dropping the cached responses of the
+// endpoints of a service or all its endpoints if none are given
+func Invalidate(b broker.Broker, topic, service string, endpoints ...string) error {
+	body, err := json.Marshal(&Invalidation{
+		Service:   service,
+		Endpoints: endpoints,
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.Publish(topic, &broker.Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: body,
+	})
+}
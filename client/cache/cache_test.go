@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/broker"
+	bmemory "github.com/micro/go-micro/broker/memory"
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/metadata"
+)
+
+type testClient struct {
+	client.Client
+	broker broker.Broker
+	calls  int
+	// called during calls
+	during func()
+}
+
+type testRequest struct {
+	Name string
+}
+
+type testResponse struct {
+	Count int
+}
+
+func (c *testClient) Options() client.Options {
+	return client.Options{Broker: c.broker}
+}
+
+func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	c.calls++
+	rsp.(*testResponse).Count = c.calls
+	if c.during != nil {
+		c.during()
+	}
+	return nil
+}
+
+func newTestClient(t *testing.T, opts ...Option) (*testClient, client.Client) {
+	b := bmemory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := &testClient{
+		Client: client.NewClient(),
+		broker: b,
+	}
+
+	return tc, NewClientWrapper(opts...)(tc)
+}
+
+func call(t *testing.T, c client.Client, endpoint, name string) int {
+	rsp := new(testResponse)
+	req := c.NewRequest("foo", endpoint, &testRequest{Name: name})
+	if err := c.Call(context.TODO(), req, rsp); err != nil {
+		t.Fatal(err)
+	}
+	return rsp.Count
+}
+
+func TestCache(t *testing.T) {
+	_, c := newTestClient(t, EndpointTTL("foo", "Foo.Get", time.Minute), EndpointTTL("foo", "Foo.Fast", 10*time.Millisecond))
+
+	// responses are cached by request
+	if n := call(t, c, "Foo.Get", "a"); n != 1 {
+		t.Fatalf("Expected 1 got %d", n)
+	}
+	if n := call(t, c, "Foo.Get", "a"); n != 1 {
+		t.Fatalf("Expected cached response 1 got %d", n)
+	}
+	if n := call(t, c, "Foo.Get", "b"); n != 2 {
+		t.Fatalf("Expected 2 got %d", n)
+	}
+
+	// endpoints without a ttl aren't cached
+	if n := call(t, c, "Foo.Set", "a"); n != 3 {
+		t.Fatalf("Expected 3 got %d", n)
+	}
+	if n := call(t, c, "Foo.Set", "a"); n != 4 {
+		t.Fatalf("Expected 4 got %d", n)
+	}
+
+	// cached responses expire
+	if n := call(t, c, "Foo.Fast", "a"); n != 5 {
+		t.Fatalf("Expected 5 got %d", n)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := call(t, c, "Foo.Fast", "a"); n != 6 {
+		t.Fatalf("Expected expired response 6 got %d", n)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	tc, c := newTestClient(t, TTL(time.Minute))
+
+	call(t, c, "Foo.Get", "a")
+	call(t, c, "Foo.List", "a")
+
+	if err := Invalidate(tc.broker, DefaultTopic, "foo", "Foo.Get"); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the invalidated endpoint is called again
+	for i := 0; i < 100; i++ {
+		if n := call(t, c, "Foo.Get", "a"); n == 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if tc.calls != 3 {
+		t.Fatalf("Expected 3 calls got %d", tc.calls)
+	}
+	if n := call(t, c, "Foo.List", "a"); n != 2 {
+		t.Fatalf("Expected cached response 2 got %d", n)
+	}
+
+	// the whole service is invalidated
+	if err := Invalidate(tc.broker, DefaultTopic, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if n := call(t, c, "Foo.List", "a"); n == 4 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected 4 calls got %d", tc.calls)
+}
+
+func TestInvalidateDuringCall(t *testing.T) {
+	tc, c := newTestClient(t, TTL(time.Minute))
+
+	// the response of a call invalidated while in flight isn't cached
+	tc.during = func() {
+		c.(*cacheWrapper).invalidate(Invalidation{Service: "foo"})
+	}
+	call(t, c, "Foo.Get", "a")
+
+	tc.during = nil
+	if n := call(t, c, "Foo.Get", "a"); n != 2 {
+		t.Fatalf("Expected 2 got %d", n)
+	}
+	if n := call(t, c, "Foo.Get", "a"); n != 2 {
+		t.Fatalf("Expected cached response 2 got %d", n)
+	}
+}
+
+func TestCacheMetadata(t *testing.T) {
+	_, c := newTestClient(t, TTL(time.Minute))
+
+	callAs := func(token string) int {
+		ctx := metadata.NewContext(context.TODO(), metadata.Metadata{"Authorization": token})
+		rsp := new(testResponse)
+		req := c.NewRequest("foo", "Foo.Get", &testRequest{Name: "a"})
+		if err := c.Call(ctx, req, rsp); err != nil {
+			t.Fatal(err)
+		}
+		return rsp.Count
+	}
+
+	// responses are cached by the metadata of the caller
+	if n := callAs("a"); n != 1 {
+		t.Fatalf("Expected 1 got %d", n)
+	}
+	if n := callAs("b"); n != 2 {
+		t.Fatalf("Expected 2 got %d", n)
+	}
+	if n := callAs("a"); n != 1 {
+		t.Fatalf("Expected cached response 1 got %d", n)
+	}
+}
+
+func TestSubscribeRetry(t *testing.T) {
+	tc, c := newTestClient(t, TTL(time.Minute))
+
+	// subscribing fails while the broker is disconnected
+	tc.broker.Disconnect()
+	call(t, c, "Foo.Get", "a")
+
+	cw := c.(*cacheWrapper)
+	if cw.subscribed {
+		t.Fatal("Expected the subscription to fail")
+	}
+
+	// the subscription is retried on later calls
+	tc.broker.Connect()
+	cw.retry = time.Time{}
+	call(t, c, "Foo.Get", "a")
+
+	if !cw.subscribed {
+		t.Fatal("Expected the subscription to be retried")
+	}
+}
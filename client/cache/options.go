@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/store"
+)
+
+type Options struct {
+	// TTL is how long responses of every endpoint are cached.
+	// Zero only caches the endpoints with their own TTL.
+	TTL time.Duration
+	// Endpoints sets the TTL of individual endpoints keyed by service and endpoint
+	Endpoints map[string]time.Duration
+	// Store the responses are cached in
+	Store store.Store
+	// Broker invalidation messages are received from.
+	// Defaults to the broker of the client.
+	Broker broker.Broker
+	// Topic invalidation messages are published on
+	Topic string
+	// Metadata keys whose values responses are cached by
+	Metadata []string
+}
+
+type Option func(o *Options)
+
+// TTL caches the responses of every endpoint for d
+func TTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = d
+	}
+}
+
+// EndpointTTL caches the responses of an endpoint of a service e.g Greeter.Hello for d.
+// A negative duration disables caching the endpoint.
+func EndpointTTL(service, endpoint string, d time.Duration) Option {
+	return func(o *Options) {
+		if o.Endpoints == nil {
+			o.Endpoints = make(map[string]time.Duration)
+		}
+		o.Endpoints[key(service, endpoint)] = d
+	}
+}
+
+// Store sets the store responses are cached in. Defaults to a memory store.
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Broker sets the broker invalidation messages are received from
+func Broker(b broker.Broker) Option {
+	return func(o *Options) {
+		o.Broker = b
+	}
+}
+
+// Metadata caches responses by the values of the metadata keys as well as
+// the request e.g a header identifying the caller. Defaults to DefaultMetadata.
+func Metadata(keys ...string) Option {
+	return func(o *Options) {
+		o.Metadata = append(o.Metadata, keys...)
+	}
+}
+
+// Topic sets the topic invalidation messages are published on. Defaults to DefaultTopic
+func Topic(t string) Option {
+	return func(o *Options) {
+		o.Topic = t
+	}
+}
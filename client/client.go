@@ -110,6 +110,10 @@ var (
 	DefaultPoolSize = 100
 	// DefaultPoolTTL sets the connection pool ttl
 	DefaultPoolTTL = time.Minute
+	// HashKeyHeader is the metadata key calls are consistently hashed by
+	HashKeyHeader = "Micro-Hash-Key"
+	// IdempotencyKeyHeader is the metadata key servers deduplicate calls by
+	IdempotencyKeyHeader = "Micro-Idempotency-Key"
 )
 
 // Makes a synchronous call to a service using the default client
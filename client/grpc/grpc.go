@@ -14,11 +14,17 @@ import (
 	"github.com/micro/go-micro/client/selector"
 	"github.com/micro/go-micro/codec"
 	raw "github.com/micro/go-micro/codec/bytes"
+	"github.com/micro/go-micro/codec/compress"
 	"github.com/micro/go-micro/errors"
 	"github.com/micro/go-micro/metadata"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/transport"
+	mls "github.com/micro/go-micro/util/tls"
 
+	// negotiate compression through the grpc-encoding headers
+	_ "github.com/micro/go-micro/util/grpc"
+
+	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding"
@@ -37,18 +43,48 @@ func init() {
 	encoding.RegisterCodec(wrapCodec{bytesCodec{}})
 }
 
+// compress returns whether a request body of at
+// least the compression threshold is compressed
+func (g *grpcClient) compress(cf encoding.Codec, body interface{}) bool {
+	if len(g.opts.Compression) == 0 {
+		return false
+	}
+	if m, ok := body.(proto.Message); ok {
+		return proto.Size(m) >= g.opts.CompressionThreshold
+	}
+	b, err := cf.Marshal(body)
+	if err != nil {
+		return false
+	}
+	return len(b) >= g.opts.CompressionThreshold
+}
+
 // secure returns the dial option for whether its a secure or insecure connection
-func (g *grpcClient) secure() grpc.DialOption {
+// secure returns the credentials of conns to the service. The
+// peer certificate is checked against the service if it's set.
+func (g *grpcClient) secure(service string) grpc.DialOption {
 	if g.opts.Context != nil {
 		if v := g.opts.Context.Value(tlsAuth{}); v != nil {
 			tls := v.(*tls.Config)
-			creds := credentials.NewTLS(tls)
+			creds := credentials.NewTLS(mls.VerifyService(tls, service))
 			return grpc.WithTransportCredentials(creds)
 		}
 	}
 	return grpc.WithInsecure()
 }
 
+// service returns the service expected at the nodes of the request.
+// It's empty when calling addresses which may be any service.
+func (g *grpcClient) service(request client.Request, opts client.CallOptions) string {
+	if len(opts.Address) > 0 || len(os.Getenv("MICRO_PROXY_ADDRESS")) > 0 {
+		return ""
+	}
+	if prx := os.Getenv("MICRO_PROXY"); len(prx) > 0 {
+		return prx
+	}
+	return request.Service()
+}
+
 func (g *grpcClient) next(request client.Request, opts client.CallOptions) (selector.Next, error) {
 	service := request.Service()
 
@@ -85,6 +121,7 @@ func (g *grpcClient) next(request client.Request, opts client.CallOptions) (sele
 
 func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
 	address := node.Address
+	service := g.service(req, opts)
 
 	header := make(map[string]string)
 	if md, ok := metadata.FromContext(ctx); ok {
@@ -95,6 +132,17 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 
 	// set timeout in nanoseconds
 	header["timeout"] = fmt.Sprintf("%d", opts.RequestTimeout)
+	// send the time remaining so nested calls share the deadline
+	if d, ok := ctx.Deadline(); ok {
+		if !d.After(time.Now()) {
+			return errors.Timeout("go.micro.client", "deadline exceeded")
+		}
+		header["timeout"] = fmt.Sprintf("%d", d.Sub(time.Now()))
+	}
+	// set the key servers deduplicate the call by
+	if len(opts.IdempotencyKey) > 0 {
+		header[client.IdempotencyKeyHeader] = opts.IdempotencyKey
+	}
 	// set the content type for the request
 	header["x-content-type"] = req.ContentType()
 
@@ -114,7 +162,7 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 	grpcDialOptions := []grpc.DialOption{
 		grpc.WithDefaultCallOptions(grpc.ForceCodec(cf)),
 		grpc.WithTimeout(opts.DialTimeout),
-		g.secure(),
+		g.secure(service),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(maxRecvMsgSize),
 			grpc.MaxCallSendMsgSize(maxSendMsgSize),
@@ -125,19 +173,22 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 		grpcDialOptions = append(grpcDialOptions, opts...)
 	}
 
-	cc, err := g.pool.getConn(address, grpcDialOptions...)
+	cc, err := g.pool.getConn(address, service, grpcDialOptions...)
 	if err != nil {
 		return errors.InternalServerError("go.micro.client", fmt.Sprintf("Error sending request: %v", err))
 	}
 	defer func() {
 		// defer execution of release
-		g.pool.release(address, cc, grr)
+		g.pool.release(cc, grr)
 	}()
 
 	ch := make(chan error, 1)
 
 	go func() {
 		grpcCallOptions := []grpc.CallOption{grpc.CallContentSubtype(cf.Name())}
+		if g.compress(cf, req.Body()) {
+			grpcCallOptions = append(grpcCallOptions, grpc.UseCompressor(g.opts.Compression))
+		}
 		if opts := g.getGrpcCallOptions(); opts != nil {
 			grpcCallOptions = append(grpcCallOptions, opts...)
 		}
@@ -167,6 +218,13 @@ func (g *grpcClient) stream(ctx context.Context, node *registry.Node, req client
 
 	// set timeout in nanoseconds
 	header["timeout"] = fmt.Sprintf("%d", opts.RequestTimeout)
+	// send the time remaining so nested calls share the deadline
+	if d, ok := ctx.Deadline(); ok {
+		if !d.After(time.Now()) {
+			return nil, errors.Timeout("go.micro.client", "deadline exceeded")
+		}
+		header["timeout"] = fmt.Sprintf("%d", d.Sub(time.Now()))
+	}
 	// set the content type for the request
 	header["x-content-type"] = req.ContentType()
 
@@ -191,7 +249,7 @@ func (g *grpcClient) stream(ctx context.Context, node *registry.Node, req client
 
 	grpcDialOptions := []grpc.DialOption{
 		grpc.WithDefaultCallOptions(grpc.ForceCodec(wc)),
-		g.secure(),
+		g.secure(g.service(req, opts)),
 	}
 
 	if opts := g.getGrpcDialOptions(); opts != nil {
@@ -210,6 +268,11 @@ func (g *grpcClient) stream(ctx context.Context, node *registry.Node, req client
 	}
 
 	grpcCallOptions := []grpc.CallOption{}
+	// the size of stream messages isn't known up front
+	// so they're all compressed if compression is set
+	if len(g.opts.Compression) > 0 {
+		grpcCallOptions = append(grpcCallOptions, grpc.UseCompressor(g.opts.Compression))
+	}
 	if opts := g.getGrpcCallOptions(); opts != nil {
 		grpcCallOptions = append(grpcCallOptions, opts...)
 	}
@@ -316,6 +379,14 @@ func (g *grpcClient) NewRequest(service, method string, req interface{}, reqOpts
 func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
 	// make a copy of call opts
 	callOpts := g.opts.CallOptions
+	// select nodes using the metadata of the request
+	if md, ok := metadata.FromContext(ctx); ok {
+		callOpts.SelectOptions = append([]selector.SelectOption{selector.WithMetadata(md)}, callOpts.SelectOptions...)
+	}
+	// route by the hash key in the metadata
+	if key, ok := metadata.Get(ctx, client.HashKeyHeader); ok {
+		client.WithHashKey(key)(&callOpts)
+	}
 	for _, opt := range opts {
 		opt(&callOpts)
 	}
@@ -354,71 +425,20 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 		gcall = callOpts.CallWrappers[i-1](gcall)
 	}
 
-	// return errors.New("go.micro.client", "request timeout", 408)
-	call := func(i int) error {
-		// call backoff first. Someone may want an initial start delay
-		t, err := callOpts.Backoff(ctx, req, i)
-		if err != nil {
-			return errors.InternalServerError("go.micro.client", err.Error())
-		}
-
-		// only sleep if greater than 0
-		if t.Seconds() > 0 {
-			time.Sleep(t)
-		}
-
-		// select next node
-		node, err := next()
-		service := req.Service()
-		if err != nil {
-			if err == selector.ErrNotFound {
-				return errors.InternalServerError("go.micro.client", "service %s: %s", service, err.Error())
-			}
-			return errors.InternalServerError("go.micro.client", "error selecting %s node: %s", service, err.Error())
-		}
-
-		// make the call
-		err = gcall(ctx, node, req, rsp, callOpts)
-		g.opts.Selector.Mark(service, node, err)
-		return err
-	}
-
-	ch := make(chan error, callOpts.Retries+1)
-	var gerr error
-
-	for i := 0; i <= callOpts.Retries; i++ {
-		go func(i int) {
-			ch <- call(i)
-		}(i)
-
-		select {
-		case <-ctx.Done():
-			return errors.New("go.micro.client", fmt.Sprintf("%v", ctx.Err()), 408)
-		case err := <-ch:
-			// if the call succeeded lets bail early
-			if err == nil {
-				return nil
-			}
-
-			retry, rerr := callOpts.Retry(ctx, req, i, err)
-			if rerr != nil {
-				return rerr
-			}
-
-			if !retry {
-				return err
-			}
-
-			gerr = err
-		}
-	}
-
-	return gerr
+	return client.Attempt(ctx, req, rsp, next, gcall, g.opts.Selector, callOpts)
 }
 
 func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
 	// make a copy of call opts
 	callOpts := g.opts.CallOptions
+	// select nodes using the metadata of the request
+	if md, ok := metadata.FromContext(ctx); ok {
+		callOpts.SelectOptions = append([]selector.SelectOption{selector.WithMetadata(md)}, callOpts.SelectOptions...)
+	}
+	// route by the hash key in the metadata
+	if key, ok := metadata.Get(ctx, client.HashKeyHeader); ok {
+		client.WithHashKey(key)(&callOpts)
+	}
 	for _, opt := range opts {
 		opt(&callOpts)
 	}
@@ -459,8 +479,12 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 		}
 
 		stream, err := g.stream(ctx, node, req, callOpts)
-		g.opts.Selector.Mark(service, node, err)
-		return stream, err
+		if err != nil {
+			g.opts.Selector.Mark(service, node, err)
+			return nil, err
+		}
+		// the node is marked once the stream ends
+		return client.MarkStream(stream, g.opts.Selector, service, node), nil
 	}
 
 	type response struct {
@@ -550,10 +574,15 @@ func (g *grpcClient) Publish(ctx context.Context, p client.Message, opts ...clie
 		topic = options.Exchange
 	}
 
+	var pubOpts []broker.PublishOption
+	if !options.DeliverAt.IsZero() {
+		pubOpts = append(pubOpts, broker.DeliverAt(options.DeliverAt))
+	}
+
 	return g.opts.Broker.Publish(topic, &broker.Message{
 		Header: md,
 		Body:   body,
-	})
+	}, pubOpts...)
 }
 
 func (g *grpcClient) String() string {
@@ -610,8 +639,9 @@ func newClient(opts ...client.Option) client.Client {
 			RequestTimeout: client.DefaultRequestTimeout,
 			DialTimeout:    transport.DefaultDialTimeout,
 		},
-		PoolSize: client.DefaultPoolSize,
-		PoolTTL:  client.DefaultPoolTTL,
+		PoolSize:             client.DefaultPoolSize,
+		PoolTTL:              client.DefaultPoolTTL,
+		CompressionThreshold: compress.DefaultThreshold,
 	}
 
 	for _, o := range opts {
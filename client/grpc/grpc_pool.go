@@ -17,6 +17,7 @@ type pool struct {
 
 type poolConn struct {
 	*grpc.ClientConn
+	key     string
 	created int64
 }
 
@@ -28,9 +29,16 @@ func newPool(size int, ttl time.Duration) *pool {
 	}
 }
 
-func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error) {
+// getConn returns a conn to the address. Conns verified
+// for a service aren't shared with other services.
+func (p *pool) getConn(addr, service string, opts ...grpc.DialOption) (*poolConn, error) {
+	key := addr
+	if len(service) > 0 {
+		key = service + "@" + addr
+	}
+
 	p.Lock()
-	conns := p.conns[addr]
+	conns := p.conns[key]
 	now := time.Now().Unix()
 
 	// while we have conns check age and then return one
@@ -38,7 +46,7 @@ func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error)
 	for len(conns) > 0 {
 		conn := conns[len(conns)-1]
 		conns = conns[:len(conns)-1]
-		p.conns[addr] = conns
+		p.conns[key] = conns
 
 		// if conn is old kill it and move on
 		if d := now - conn.created; d > p.ttl {
@@ -60,10 +68,10 @@ func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error)
 		return nil, err
 	}
 
-	return &poolConn{cc, time.Now().Unix()}, nil
+	return &poolConn{cc, key, time.Now().Unix()}, nil
 }
 
-func (p *pool) release(addr string, conn *poolConn, err error) {
+func (p *pool) release(conn *poolConn, err error) {
 	// don't store the conn if it has errored
 	if err != nil {
 		conn.ClientConn.Close()
@@ -72,12 +80,12 @@ func (p *pool) release(addr string, conn *poolConn, err error) {
 
 	// otherwise put it back for reuse
 	p.Lock()
-	conns := p.conns[addr]
+	conns := p.conns[conn.key]
 	if len(conns) >= p.size {
 		p.Unlock()
 		conn.ClientConn.Close()
 		return
 	}
-	p.conns[addr] = append(conns, conn)
+	p.conns[conn.key] = append(conns, conn)
 	p.Unlock()
 }
@@ -30,7 +30,7 @@ func testPool(t *testing.T, size int, ttl time.Duration) {
 
 	for i := 0; i < 10; i++ {
 		// get a conn
-		cc, err := p.getConn(l.Addr().String(), grpc.WithInsecure())
+		cc, err := p.getConn(l.Addr().String(), "", grpc.WithInsecure())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -47,7 +47,7 @@ func testPool(t *testing.T, size int, ttl time.Duration) {
 		}
 
 		// release the conn
-		p.release(l.Addr().String(), cc, nil)
+		p.release(cc, nil)
 
 		p.Lock()
 		if i := len(p.conns[l.Addr().String()]); i > size {
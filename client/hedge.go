@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/micro/go-micro/client/selector"
+	"github.com/micro/go-micro/errors"
+)
+
+var (
+	// times to call next looking for an unused node for a hedged request
+	hedgeSelectAttempts = 3
+)
+
+// newResponse returns a new value of the response type for an
+// attempt to decode into, or the response if it's not a pointer
+func newResponse(rsp interface{}) interface{} {
+	v := reflect.ValueOf(rsp)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return rsp
+	}
+	return reflect.New(v.Elem().Type()).Interface()
+}
+
+// setResponse copies the response of the successful attempt
+func setResponse(rsp, attempt interface{}) {
+	v, a := reflect.ValueOf(rsp), reflect.ValueOf(attempt)
+	if v.Kind() != reflect.Ptr || a.Kind() != reflect.Ptr || v.IsNil() || v.Pointer() == a.Pointer() {
+		return
+	}
+	v.Elem().Set(a.Elem())
+}
+
+// Attempt calls the nodes returned by next until an attempt succeeds,
+// retrying and hedging as set by the call options, and marks the result
+// of each attempt with the selector. It's shared by client implementations
+// which pass their call func wrapped by the call wrappers.
+func Attempt(ctx context.Context, req Request, rsp interface{}, next selector.Next, cf CallFunc, sel selector.Selector, opts CallOptions) error {
+	// attempts still running after a timeout or hedged attempts
+	// may be decoding so each gets its own response to decode into
+	isolate := opts.HedgeDelay > 0 || opts.AttemptTimeout > 0
+
+	type result struct {
+		attempt  int
+		response interface{}
+		err      error
+	}
+
+	ch := make(chan result, opts.Retries+1)
+	// nodes attempts have been sent to
+	used := make(map[string]bool)
+
+	// cancel attempts which are still running on return
+	var cancels []context.CancelFunc
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	call := func(i int, hedged bool) {
+		service := req.Service()
+
+		// select next node, preferring a different node for hedged requests
+		node, err := next()
+		for j := 0; hedged && err == nil && used[node.Id] && j < hedgeSelectAttempts; j++ {
+			// the node won't be called
+			selector.Release(node)
+			node, err = next()
+		}
+		if err != nil {
+			if err == selector.ErrNotFound {
+				err = errors.InternalServerError("go.micro.client", "service %s: %s", service, err.Error())
+			} else {
+				err = errors.InternalServerError("go.micro.client", "error getting next %s node: %s", service, err.Error())
+			}
+			ch <- result{attempt: i, err: err}
+			return
+		}
+		used[node.Id] = true
+
+		actx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+
+		arsp := rsp
+		if isolate {
+			arsp = newResponse(rsp)
+		}
+
+		go func() {
+			// call backoff first. Someone may want an initial start delay
+			if !hedged {
+				t, err := opts.Backoff(ctx, req, i)
+				if err != nil {
+					ch <- result{attempt: i, err: errors.InternalServerError("go.micro.client", "backoff error: %v", err.Error())}
+					return
+				}
+
+				// only sleep if greater than 0
+				if t.Seconds() > 0 {
+					time.Sleep(t)
+				}
+			}
+
+			aopts := opts
+			if d := opts.AttemptTimeout; d > 0 && d < opts.RequestTimeout {
+				var cancel context.CancelFunc
+				actx, cancel = context.WithTimeout(actx, d)
+				defer cancel()
+				aopts.RequestTimeout = d
+			}
+
+			// make the call
+			err := cf(actx, node, req, arsp, aopts)
+			sel.Mark(service, node, err)
+			ch <- result{attempt: i, response: arsp, err: err}
+		}()
+	}
+
+	if opts.Budget != nil {
+		opts.Budget.Record()
+	}
+
+	// another returns whether there's another attempt left to make
+	another := func(i int) bool {
+		return i < opts.Retries && (opts.Budget == nil || opts.Budget.Allow())
+	}
+
+	var gerr error
+	var hedge <-chan time.Time
+	var i, pending int
+
+	launch := func(hedged bool) {
+		pending++
+		call(i, hedged)
+
+		hedge = nil
+		if opts.HedgeDelay > 0 && i < opts.Retries {
+			hedge = time.After(opts.HedgeDelay)
+		}
+	}
+
+	launch(false)
+
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			return errors.Timeout("go.micro.client", fmt.Sprintf("call timeout: %v", ctx.Err()))
+		case <-hedge:
+			hedge = nil
+			if another(i) {
+				i++
+				launch(true)
+			}
+		case res := <-ch:
+			pending--
+
+			// if the call succeeded lets bail early
+			if res.err == nil {
+				setResponse(rsp, res.response)
+				return nil
+			}
+
+			retry, rerr := opts.Retry(ctx, req, res.attempt, res.err)
+			if rerr != nil {
+				return rerr
+			}
+
+			if !retry {
+				return res.err
+			}
+
+			gerr = res.err
+
+			// wait for hedged requests before retrying
+			if pending == 0 && another(i) {
+				i++
+				launch(false)
+			}
+		}
+	}
+
+	return gerr
+}
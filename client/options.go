@@ -7,6 +7,7 @@ import (
 	"github.com/micro/go-micro/broker"
 	"github.com/micro/go-micro/client/selector"
 	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/codec/compress"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/transport"
 )
@@ -29,6 +30,12 @@ type Options struct {
 	PoolSize int
 	PoolTTL  time.Duration
 
+	// Compression is the encoding of request bodies, empty disables it
+	Compression string
+	// CompressionThreshold is the body size in bytes
+	// from which requests are compressed
+	CompressionThreshold int
+
 	// Middleware for client
 	Wrappers []Wrapper
 
@@ -55,6 +62,16 @@ type CallOptions struct {
 	Retries int
 	// Request/Response timeout
 	RequestTimeout time.Duration
+	// Timeout for each attempt, zero uses the request timeout
+	AttemptTimeout time.Duration
+	// Delay before sending a hedged request to another
+	// node while the first is outstanding, zero disables it
+	HedgeDelay time.Duration
+	// Budget limits retries and hedged requests
+	Budget *Budget
+	// IdempotencyKey identifies the call so servers
+	// can deduplicate retries of it
+	IdempotencyKey string
 
 	// Middleware for low level call func
 	CallWrappers []CallWrapper
@@ -67,6 +84,8 @@ type CallOptions struct {
 type PublishOptions struct {
 	// Exchange is the routing exchange for the message
 	Exchange string
+	// DeliverAt delays delivery of the message until the given time
+	DeliverAt time.Time
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -95,8 +114,9 @@ func newOptions(options ...Option) Options {
 			RequestTimeout: DefaultRequestTimeout,
 			DialTimeout:    transport.DefaultDialTimeout,
 		},
-		PoolSize: DefaultPoolSize,
-		PoolTTL:  DefaultPoolTTL,
+		PoolSize:             DefaultPoolSize,
+		PoolTTL:              DefaultPoolTTL,
+		CompressionThreshold: compress.DefaultThreshold,
 	}
 
 	for _, o := range options {
@@ -167,6 +187,21 @@ func PoolTTL(d time.Duration) Option {
 	}
 }
 
+// Compression sets the encoding used to compress request bodies
+// e.g. gzip. Responses are compressed if the server is configured to.
+func Compression(name string) Option {
+	return func(o *Options) {
+		o.Compression = name
+	}
+}
+
+// CompressionThreshold sets the body size in bytes from which requests are compressed
+func CompressionThreshold(n int) Option {
+	return func(o *Options) {
+		o.CompressionThreshold = n
+	}
+}
+
 // Registry to find nodes for a given service
 func Registry(r registry.Registry) Option {
 	return func(o *Options) {
@@ -233,6 +268,28 @@ func RequestTimeout(d time.Duration) Option {
 	}
 }
 
+// AttemptTimeout sets the timeout for each attempt at a call
+func AttemptTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.CallOptions.AttemptTimeout = d
+	}
+}
+
+// HedgeDelay sends a duplicate request to another node when a call
+// hasn't completed after d. The first response is used.
+func HedgeDelay(d time.Duration) Option {
+	return func(o *Options) {
+		o.CallOptions.HedgeDelay = d
+	}
+}
+
+// RetryBudget limits retries and hedged requests to the budget
+func RetryBudget(b *Budget) Option {
+	return func(o *Options) {
+		o.CallOptions.Budget = b
+	}
+}
+
 // Transport dial timeout
 func DialTimeout(d time.Duration) Option {
 	return func(o *Options) {
@@ -249,6 +306,20 @@ func WithExchange(e string) PublishOption {
 	}
 }
 
+// PublishDelay delivers the message after d
+func PublishDelay(d time.Duration) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = time.Now().Add(d)
+	}
+}
+
+// PublishAt delivers the message at t
+func PublishAt(t time.Time) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = t
+	}
+}
+
 // WithAddress sets the remote addresses to use rather than using service discovery
 func WithAddress(a ...string) CallOption {
 	return func(o *CallOptions) {
@@ -262,6 +333,24 @@ func WithSelectOption(so ...selector.SelectOption) CallOption {
 	}
 }
 
+// WithHashKey routes the call to a node by consistently hashing the key
+// so calls with the same key go to the same node. The key can also be set
+// in the metadata of the context using the HashKeyHeader.
+func WithHashKey(key string) CallOption {
+	return func(o *CallOptions) {
+		o.SelectOptions = append(o.SelectOptions, selector.WithStrategy(selector.ConsistentHash(key)))
+	}
+}
+
+// WithIdempotencyKey sets a key identifying the call which is sent in the
+// IdempotencyKeyHeader. Servers deduplicating requests replay the response
+// to retries of the call rather than handling it again.
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *CallOptions) {
+		o.IdempotencyKey = key
+	}
+}
+
 // WithCallWrapper is a CallOption which adds to the existing CallFunc wrappers
 func WithCallWrapper(cw ...CallWrapper) CallOption {
 	return func(o *CallOptions) {
@@ -301,6 +390,30 @@ func WithRequestTimeout(d time.Duration) CallOption {
 	}
 }
 
+// WithAttemptTimeout is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithAttemptTimeout(d time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.AttemptTimeout = d
+	}
+}
+
+// WithHedgeDelay is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithHedgeDelay(d time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.HedgeDelay = d
+	}
+}
+
+// WithRetryBudget is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithRetryBudget(b *Budget) CallOption {
+	return func(o *CallOptions) {
+		o.Budget = b
+	}
+}
+
 // WithDialTimeout is a CallOption which overrides that which
 // set in Options.CallOptions
 func WithDialTimeout(d time.Duration) CallOption {
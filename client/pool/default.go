@@ -20,6 +20,7 @@ type pool struct {
 type poolConn struct {
 	transport.Client
 	id      string
+	key     string
 	created time.Time
 }
 
@@ -57,16 +58,31 @@ func (p *poolConn) Created() time.Time {
 	return p.created
 }
 
+// key returns the key of conns to the address. Conns
+// verified for a service aren't shared with other services.
+func key(addr string, opts ...transport.DialOption) string {
+	var options transport.DialOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Service) > 0 {
+		return options.Service + "@" + addr
+	}
+	return addr
+}
+
 func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
+	k := key(addr, opts...)
+
 	p.Lock()
-	conns := p.conns[addr]
+	conns := p.conns[k]
 
 	// while we have conns check age and then return one
 	// otherwise we'll create a new conn
 	for len(conns) > 0 {
 		conn := conns[len(conns)-1]
 		conns = conns[:len(conns)-1]
-		p.conns[addr] = conns
+		p.conns[k] = conns
 
 		// if conn is old kill it and move on
 		if d := time.Since(conn.Created()); d > p.ttl {
@@ -90,6 +106,7 @@ func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 	return &poolConn{
 		Client:  c,
 		id:      uuid.New().String(),
+		key:     k,
 		created: time.Now(),
 	}, nil
 }
@@ -101,13 +118,15 @@ func (p *pool) Release(conn Conn, err error) error {
 	}
 
 	// otherwise put it back for reuse
+	pc := conn.(*poolConn)
+
 	p.Lock()
-	conns := p.conns[conn.Remote()]
+	conns := p.conns[pc.key]
 	if len(conns) >= p.size {
 		p.Unlock()
-		return conn.(*poolConn).Client.Close()
+		return pc.Client.Close()
 	}
-	p.conns[conn.Remote()] = append(conns, conn.(*poolConn))
+	p.conns[pc.key] = append(conns, pc)
 	p.Unlock()
 
 	return nil
@@ -14,7 +14,15 @@ func RetryAlways(ctx context.Context, req Request, retryCount int, err error) (b
 	return true, nil
 }
 
-// RetryOnError retries a request on a 500 or timeout error
+const (
+	// the id of the error servers reject requests with while shutting down
+	shutdownErrorId = "go.micro.server.shutdown"
+	// the id of the error the breaker fails calls to nodes with an open circuit
+	breakerErrorId = "go.micro.client.breaker"
+)
+
+// RetryOnError retries a request on a 500 or timeout error, when the
+// server is shutting down or when the circuit of the node is open
 func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
 	if err == nil {
 		return false, nil
@@ -29,6 +37,11 @@ func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (
 	// retry on timeout or internal server error
 	case 408, 500:
 		return true, nil
+	// retry on the next node when the server is shutting down or the
+	// call wasn't made as the circuit of the node is open, other
+	// services may be unavailable on every node
+	case 503:
+		return e.Id == shutdownErrorId || e.Id == breakerErrorId, nil
 	default:
 		return false, nil
 	}
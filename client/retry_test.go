@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/server"
+)
+
+func TestRetryOnError(t *testing.T) {
+	testData := []struct {
+		err   error
+		retry bool
+	}{
+		{errors.InternalServerError("foo", "error"), true},
+		{errors.Timeout("foo", "timeout"), true},
+		{errors.BadRequest("foo", "bad request"), false},
+		{errors.New("foo", "unavailable", 503), false},
+		{server.ErrShuttingDown, true},
+		{errors.New(breakerErrorId, "circuit open", 503), true},
+	}
+
+	for _, d := range testData {
+		retry, err := RetryOnError(context.TODO(), nil, 1, d.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry != d.retry {
+			t.Fatalf("Expected retry %v for %v", d.retry, d.err)
+		}
+	}
+}
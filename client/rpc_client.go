@@ -14,6 +14,7 @@ import (
 	"github.com/micro/go-micro/client/selector"
 	"github.com/micro/go-micro/codec"
 	raw "github.com/micro/go-micro/codec/bytes"
+	"github.com/micro/go-micro/codec/compress"
 	"github.com/micro/go-micro/errors"
 	"github.com/micro/go-micro/metadata"
 	"github.com/micro/go-micro/registry"
@@ -54,6 +55,15 @@ func newRpcClient(opt ...Option) Client {
 	return c
 }
 
+// compression returns the encoding of requests to a node. Nodes
+// using the old protocol don't understand compressed requests.
+func (r *rpcClient) compression(node *registry.Node) string {
+	if len(node.Metadata["protocol"]) == 0 {
+		return ""
+	}
+	return r.opts.Compression
+}
+
 func (r *rpcClient) newCodec(contentType string) (codec.NewCodec, error) {
 	if c, ok := r.opts.Codecs[contentType]; ok {
 		return c, nil
@@ -80,11 +90,25 @@ func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request,
 
 	// set timeout in nanoseconds
 	msg.Header["Timeout"] = fmt.Sprintf("%d", opts.RequestTimeout)
+	// send the time remaining so nested calls share the deadline
+	if d, ok := ctx.Deadline(); ok {
+		if !d.After(time.Now()) {
+			return errors.Timeout("go.micro.client", "deadline exceeded")
+		}
+		msg.Header["Timeout"] = fmt.Sprintf("%d", d.Sub(time.Now()))
+	}
+	// set the key servers deduplicate the call by
+	if len(opts.IdempotencyKey) > 0 {
+		msg.Header[IdempotencyKeyHeader] = opts.IdempotencyKey
+	}
 	// set the content type for the request
 	msg.Header["Content-Type"] = req.ContentType()
 	// set the accept header
 	msg.Header["Accept"] = req.ContentType()
 
+	// set the encodings accepted for the response
+	msg.Header[compress.AcceptEncodingHeader] = compress.Accept()
+
 	// setup old protocol
 	cf := setupProtocol(msg, node)
 
@@ -105,6 +129,10 @@ func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request,
 		dOpts = append(dOpts, transport.WithTimeout(opts.DialTimeout))
 	}
 
+	if service := r.service(req, opts); len(service) > 0 {
+		dOpts = append(dOpts, transport.WithService(service))
+	}
+
 	c, err := r.pool.Get(address, dOpts...)
 	if err != nil {
 		return errors.InternalServerError("go.micro.client", "connection error: %v", err)
@@ -112,7 +140,7 @@ func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request,
 
 	seq := atomic.LoadUint64(&r.seq)
 	atomic.AddUint64(&r.seq, 1)
-	codec := newRpcCodec(msg, c, cf, "")
+	codec := newRpcCodec(msg, c, cf, "", r.compression(node), r.opts.CompressionThreshold)
 
 	rsp := &rpcResponse{
 		socket: c,
@@ -195,11 +223,21 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 
 	// set timeout in nanoseconds
 	msg.Header["Timeout"] = fmt.Sprintf("%d", opts.RequestTimeout)
+	// send the time remaining so nested calls share the deadline
+	if d, ok := ctx.Deadline(); ok {
+		if !d.After(time.Now()) {
+			return nil, errors.Timeout("go.micro.client", "deadline exceeded")
+		}
+		msg.Header["Timeout"] = fmt.Sprintf("%d", d.Sub(time.Now()))
+	}
 	// set the content type for the request
 	msg.Header["Content-Type"] = req.ContentType()
 	// set the accept header
 	msg.Header["Accept"] = req.ContentType()
 
+	// set the encodings accepted for the response
+	msg.Header[compress.AcceptEncodingHeader] = compress.Accept()
+
 	// set old codecs
 	cf := setupProtocol(msg, node)
 
@@ -220,6 +258,10 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 		dOpts = append(dOpts, transport.WithTimeout(opts.DialTimeout))
 	}
 
+	if service := r.service(req, opts); len(service) > 0 {
+		dOpts = append(dOpts, transport.WithService(service))
+	}
+
 	c, err := r.opts.Transport.Dial(address, dOpts...)
 	if err != nil {
 		return nil, errors.InternalServerError("go.micro.client", "connection error: %v", err)
@@ -231,7 +273,7 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 	id := fmt.Sprintf("%v", seq)
 
 	// create codec with stream id
-	codec := newRpcCodec(msg, c, cf, id)
+	codec := newRpcCodec(msg, c, cf, id, r.compression(node), r.opts.CompressionThreshold)
 
 	rsp := &rpcResponse{
 		socket: c,
@@ -316,6 +358,18 @@ func (r *rpcClient) Options() Options {
 	return r.opts
 }
 
+// service returns the service expected at the nodes of the request.
+// It's empty when calling addresses which may be any service.
+func (r *rpcClient) service(request Request, opts CallOptions) string {
+	if len(opts.Address) > 0 || len(os.Getenv("MICRO_PROXY_ADDRESS")) > 0 {
+		return ""
+	}
+	if prx := os.Getenv("MICRO_PROXY"); len(prx) > 0 {
+		return prx
+	}
+	return request.Service()
+}
+
 func (r *rpcClient) next(request Request, opts CallOptions) (selector.Next, error) {
 	service := request.Service()
 
@@ -364,6 +418,14 @@ func (r *rpcClient) next(request Request, opts CallOptions) (selector.Next, erro
 func (r *rpcClient) Call(ctx context.Context, request Request, response interface{}, opts ...CallOption) error {
 	// make a copy of call opts
 	callOpts := r.opts.CallOptions
+	// select nodes using the metadata of the request
+	if md, ok := metadata.FromContext(ctx); ok {
+		callOpts.SelectOptions = append([]selector.SelectOption{selector.WithMetadata(md)}, callOpts.SelectOptions...)
+	}
+	// route by the hash key in the metadata
+	if key, ok := metadata.Get(ctx, HashKeyHeader); ok {
+		WithHashKey(key)(&callOpts)
+	}
 	for _, opt := range opts {
 		opt(&callOpts)
 	}
@@ -402,71 +464,20 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 		rcall = callOpts.CallWrappers[i-1](rcall)
 	}
 
-	// return errors.New("go.micro.client", "request timeout", 408)
-	call := func(i int) error {
-		// call backoff first. Someone may want an initial start delay
-		t, err := callOpts.Backoff(ctx, request, i)
-		if err != nil {
-			return errors.InternalServerError("go.micro.client", "backoff error: %v", err.Error())
-		}
-
-		// only sleep if greater than 0
-		if t.Seconds() > 0 {
-			time.Sleep(t)
-		}
-
-		// select next node
-		node, err := next()
-		service := request.Service()
-		if err != nil {
-			if err == selector.ErrNotFound {
-				return errors.InternalServerError("go.micro.client", "service %s: %s", service, err.Error())
-			}
-			return errors.InternalServerError("go.micro.client", "error getting next %s node: %s", service, err.Error())
-		}
-
-		// make the call
-		err = rcall(ctx, node, request, response, callOpts)
-		r.opts.Selector.Mark(service, node, err)
-		return err
-	}
-
-	ch := make(chan error, callOpts.Retries+1)
-	var gerr error
-
-	for i := 0; i <= callOpts.Retries; i++ {
-		go func(i int) {
-			ch <- call(i)
-		}(i)
-
-		select {
-		case <-ctx.Done():
-			return errors.Timeout("go.micro.client", fmt.Sprintf("call timeout: %v", ctx.Err()))
-		case err := <-ch:
-			// if the call succeeded lets bail early
-			if err == nil {
-				return nil
-			}
-
-			retry, rerr := callOpts.Retry(ctx, request, i, err)
-			if rerr != nil {
-				return rerr
-			}
-
-			if !retry {
-				return err
-			}
-
-			gerr = err
-		}
-	}
-
-	return gerr
+	return Attempt(ctx, request, response, next, rcall, r.opts.Selector, callOpts)
 }
 
 func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOption) (Stream, error) {
 	// make a copy of call opts
 	callOpts := r.opts.CallOptions
+	// select nodes using the metadata of the request
+	if md, ok := metadata.FromContext(ctx); ok {
+		callOpts.SelectOptions = append([]selector.SelectOption{selector.WithMetadata(md)}, callOpts.SelectOptions...)
+	}
+	// route by the hash key in the metadata
+	if key, ok := metadata.Get(ctx, HashKeyHeader); ok {
+		WithHashKey(key)(&callOpts)
+	}
 	for _, opt := range opts {
 		opt(&callOpts)
 	}
@@ -505,8 +516,12 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 		}
 
 		stream, err := r.stream(ctx, node, request, callOpts)
-		r.opts.Selector.Mark(service, node, err)
-		return stream, err
+		if err != nil {
+			r.opts.Selector.Mark(service, node, err)
+			return nil, err
+		}
+		// the node is marked once the stream ends
+		return MarkStream(stream, r.opts.Selector, service, node), nil
 	}
 
 	type response struct {
@@ -613,10 +628,15 @@ func (r *rpcClient) Publish(ctx context.Context, msg Message, opts ...PublishOpt
 		r.opts.Broker.Connect()
 	})
 
+	var pubOpts []broker.PublishOption
+	if !options.DeliverAt.IsZero() {
+		pubOpts = append(pubOpts, broker.DeliverAt(options.DeliverAt))
+	}
+
 	return r.opts.Broker.Publish(topic, &broker.Message{
 		Header: md,
 		Body:   body,
-	})
+	}, pubOpts...)
 }
 
 func (r *rpcClient) NewMessage(topic string, message interface{}, opts ...MessageOption) Message {
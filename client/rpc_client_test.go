@@ -3,7 +3,9 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/micro/go-micro/client/selector"
 	"github.com/micro/go-micro/errors"
@@ -159,3 +161,141 @@ func TestCallWrapper(t *testing.T) {
 		t.Fatal("wrapper not called")
 	}
 }
+
+func TestCallHedge(t *testing.T) {
+	var mtx sync.Mutex
+	var called int
+	cancelled := make(chan bool, 1)
+
+	wrap := func(cf CallFunc) CallFunc {
+		return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+			mtx.Lock()
+			called++
+			n := called
+			mtx.Unlock()
+
+			// the first attempt hangs until it's cancelled
+			if n == 1 {
+				<-ctx.Done()
+				cancelled <- true
+				return errors.Timeout("test.error", "cancelled")
+			}
+
+			*(rsp.(*map[string]string)) = map[string]string{"attempt": fmt.Sprintf("%d", n)}
+			return nil
+		}
+	}
+
+	c := NewClient(WrapCall(wrap))
+	req := c.NewRequest("test.service", "Test.Endpoint", nil)
+
+	rsp := make(map[string]string)
+	if err := c.Call(context.Background(), req, &rsp, WithAddress("10.1.10.1"), WithHedgeDelay(10*time.Millisecond)); err != nil {
+		t.Fatal("hedged call error", err)
+	}
+
+	if rsp["attempt"] != "2" {
+		t.Fatalf("expected response from hedged request got %v", rsp)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("first attempt not cancelled")
+	}
+}
+
+func TestCallAttemptTimeout(t *testing.T) {
+	var called int
+
+	wrap := func(cf CallFunc) CallFunc {
+		return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+			called++
+			if called == 1 {
+				<-ctx.Done()
+				return errors.Timeout("test.error", "attempt timeout")
+			}
+			return nil
+		}
+	}
+
+	c := NewClient(WrapCall(wrap), Retries(1), Backoff(func(context.Context, Request, int) (time.Duration, error) {
+		return 0, nil
+	}))
+	req := c.NewRequest("test.service", "Test.Endpoint", nil)
+
+	start := time.Now()
+	if err := c.Call(context.Background(), req, nil, WithAddress("10.1.10.1"), WithAttemptTimeout(10*time.Millisecond)); err != nil {
+		t.Fatal("call error", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("expected attempt to time out got call taking %v", d)
+	}
+	if called != 2 {
+		t.Fatalf("expected 2 attempts got %d", called)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(10, 0)
+
+	for i := 0; i < 20; i++ {
+		b.Record()
+	}
+
+	if !b.Allow() || !b.Allow() {
+		t.Fatal("expected retries within budget")
+	}
+	if b.Allow() {
+		t.Fatal("expected retry over budget to be denied")
+	}
+
+	if !NewBudget(10, 1).Allow() {
+		t.Fatal("expected minimum retries to be allowed")
+	}
+}
+
+type markSelector struct {
+	selector.Selector
+	marks int
+}
+
+func (m *markSelector) Mark(service string, node *registry.Node, err error) {
+	m.marks++
+}
+
+type testStream struct {
+	Stream
+	err error
+}
+
+func (t *testStream) Recv(interface{}) error {
+	return t.err
+}
+
+func (t *testStream) Close() error {
+	return nil
+}
+
+func TestMarkStream(t *testing.T) {
+	sel := new(markSelector)
+	ts := new(testStream)
+	s := MarkStream(ts, sel, "test.service", &registry.Node{Id: "test-1"})
+
+	// the node is in use until the stream ends
+	if err := s.Recv(nil); err != nil {
+		t.Fatal(err)
+	}
+	if sel.marks != 0 {
+		t.Fatalf("expected no marks while the stream is open got %d", sel.marks)
+	}
+
+	ts.err = errors.InternalServerError("test.error", "failed")
+	s.Recv(nil)
+	s.Close()
+
+	if sel.marks != 1 {
+		t.Fatalf("expected 1 mark got %d", sel.marks)
+	}
+}
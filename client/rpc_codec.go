@@ -6,9 +6,12 @@ import (
 
 	"github.com/micro/go-micro/codec"
 	raw "github.com/micro/go-micro/codec/bytes"
+	"github.com/micro/go-micro/codec/cbor"
+	"github.com/micro/go-micro/codec/compress"
 	"github.com/micro/go-micro/codec/grpc"
 	"github.com/micro/go-micro/codec/json"
 	"github.com/micro/go-micro/codec/jsonrpc"
+	"github.com/micro/go-micro/codec/msgpack"
 	"github.com/micro/go-micro/codec/proto"
 	"github.com/micro/go-micro/codec/protorpc"
 	"github.com/micro/go-micro/errors"
@@ -42,6 +45,10 @@ type rpcCodec struct {
 
 	// signify if its a stream
 	stream string
+
+	// encoding of request bodies of at least threshold bytes
+	compression string
+	threshold   int
 }
 
 type readWriteCloser struct {
@@ -61,6 +68,9 @@ var (
 		"application/json-rpc":     jsonrpc.NewCodec,
 		"application/proto-rpc":    protorpc.NewCodec,
 		"application/octet-stream": raw.NewCodec,
+		"application/msgpack":      msgpack.NewCodec,
+		"application/x-msgpack":    msgpack.NewCodec,
+		"application/cbor":         cbor.NewCodec,
 	}
 
 	// TODO: remove legacy codec list
@@ -162,17 +172,19 @@ func setupProtocol(msg *transport.Message, node *registry.Node) codec.NewCodec {
 	return defaultCodecs[msg.Header["Content-Type"]]
 }
 
-func newRpcCodec(req *transport.Message, client transport.Client, c codec.NewCodec, stream string) codec.Codec {
+func newRpcCodec(req *transport.Message, client transport.Client, c codec.NewCodec, stream, compression string, threshold int) codec.Codec {
 	rwc := &readWriteCloser{
 		wbuf: bytes.NewBuffer(nil),
 		rbuf: bytes.NewBuffer(nil),
 	}
 	r := &rpcCodec{
-		buf:    rwc,
-		client: client,
-		codec:  c(rwc),
-		req:    req,
-		stream: stream,
+		buf:         rwc,
+		client:      client,
+		codec:       c(rwc),
+		req:         req,
+		stream:      stream,
+		compression: compression,
+		threshold:   threshold,
 	}
 	return r
 }
@@ -212,10 +224,17 @@ func (c *rpcCodec) Write(m *codec.Message, body interface{}) error {
 		m.Body = c.buf.wbuf.Bytes()
 	}
 
+	// compress the body
+	delete(m.Header, compress.ContentEncodingHeader)
+	b, err := compress.Encode(c.compression, c.threshold, m.Header, m.Body)
+	if err != nil {
+		return errors.InternalServerError("go.micro.client.codec", err.Error())
+	}
+
 	// create new transport message
 	msg := transport.Message{
 		Header: m.Header,
-		Body:   m.Body,
+		Body:   b,
 	}
 	// send the request
 	if err := c.client.Send(&msg); err != nil {
@@ -232,14 +251,20 @@ func (c *rpcCodec) ReadHeader(m *codec.Message, r codec.MessageType) error {
 		return errors.InternalServerError("go.micro.client.transport", err.Error())
 	}
 
+	// decompress the body
+	body, err := compress.Decode(tm.Header, tm.Body)
+	if err != nil {
+		return errors.InternalServerError("go.micro.client.codec", err.Error())
+	}
+
 	c.buf.rbuf.Reset()
-	c.buf.rbuf.Write(tm.Body)
+	c.buf.rbuf.Write(body)
 
 	// set headers from transport
 	m.Header = tm.Header
 
 	// read header
-	err := c.codec.ReadHeader(m, r)
+	err = c.codec.ReadHeader(m, r)
 
 	// get headers
 	getHeaders(m)
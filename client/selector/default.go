@@ -57,6 +57,16 @@ func (c *registrySelector) Select(service string, opts ...SelectOption) (Next, e
 		return nil, err
 	}
 
+	// drop nodes with an open circuit
+	if c.so.Breaker != nil {
+		services = FilterAvailable(service, c.so.Breaker)(services)
+	}
+
+	// route the request to versions by the rules
+	if c.so.Rules != nil {
+		services = c.so.Rules.Route(services, sopts.Metadata)
+	}
+
 	// apply the filters
 	for _, filter := range sopts.Filters {
 		services = filter(services)
@@ -67,10 +77,17 @@ func (c *registrySelector) Select(service string, opts ...SelectOption) (Next, e
 		return nil, ErrNoneAvailable
 	}
 
-	return sopts.Strategy(services), nil
+	// prefer nodes closest to us
+	localities := [][]*registry.Service{services}
+	if len(c.so.Region) > 0 || len(c.so.Zone) > 0 {
+		localities = Localities(c.so.Region, c.so.Zone, services)
+	}
+
+	return Failover(service, c.so.Breaker, sopts.Strategy, localities), nil
 }
 
 func (c *registrySelector) Mark(service string, node *registry.Node, err error) {
+	Release(node)
 }
 
 func (c *registrySelector) Reset(service string) {
@@ -1,8 +1,10 @@
 package selector
 
 import (
+	"sync"
 	"testing"
 
+	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/registry/memory"
 )
 
@@ -27,3 +29,76 @@ func TestRegistrySelector(t *testing.T) {
 
 	t.Logf("Selector Counts %v", counts)
 }
+
+type testBreaker struct {
+	sync.Mutex
+	open map[string]bool
+}
+
+func (b *testBreaker) Available(service string, node *registry.Node) bool {
+	b.Lock()
+	defer b.Unlock()
+	return !b.open[node.Id]
+}
+
+func (b *testBreaker) trip(id string) {
+	b.Lock()
+	b.open[id] = true
+	b.Unlock()
+}
+
+func TestRegistrySelectorFailover(t *testing.T) {
+	node := func(id, region, zone string) *registry.Node {
+		return &registry.Node{
+			Id: id,
+			Metadata: map[string]string{
+				registry.MetadataRegion: region,
+				registry.MetadataZone:   zone,
+			},
+		}
+	}
+
+	r := memory.NewRegistry(memory.Services(map[string][]*registry.Service{
+		"bar": {
+			{
+				Name:    "bar",
+				Version: "1.0.0",
+				Nodes: []*registry.Node{
+					node("bar-1", "eu-west", "eu-west-a"),
+					node("bar-2", "eu-west", "eu-west-b"),
+					node("bar-3", "us-east", "us-east-a"),
+				},
+			},
+		},
+	}))
+
+	b := &testBreaker{open: make(map[string]bool)}
+	s := NewSelector(Registry(r), SetStrategy(RoundRobin), SetBreaker(b), Locality("eu-west", "eu-west-a"))
+
+	next, err := s.Select("bar")
+	if err != nil {
+		t.Fatalf("Unexpected error selecting %v", err)
+	}
+
+	// nodes in the same zone are preferred
+	if node, err := next(); err != nil || node.Id != "bar-1" {
+		t.Fatalf("Expected bar-1 got %v %v", node, err)
+	}
+
+	// fail over to the region when the zone is unavailable
+	b.trip("bar-1")
+	if node, err := next(); err != nil || node.Id != "bar-2" {
+		t.Fatalf("Expected bar-2 got %v %v", node, err)
+	}
+
+	// then to every node
+	b.trip("bar-2")
+	if node, err := next(); err != nil || node.Id != "bar-3" {
+		t.Fatalf("Expected bar-3 got %v %v", node, err)
+	}
+
+	b.trip("bar-3")
+	if _, err := next(); err != ErrNoneAvailable {
+		t.Fatalf("Expected %v got %v", ErrNoneAvailable, err)
+	}
+}
@@ -99,7 +99,10 @@ func (d *dnsSelector) Select(service string, opts ...selector.SelectOption) (sel
 	return sopts.Strategy(services), nil
 }
 
-func (d *dnsSelector) Mark(service string, node *registry.Node, err error) {}
+func (d *dnsSelector) Mark(service string, node *registry.Node, err error) {
+	// end the call for the load aware strategies
+	selector.Release(node)
+}
 
 func (d *dnsSelector) Reset(service string) {}
 
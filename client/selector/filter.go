@@ -71,3 +71,114 @@ func FilterVersion(version string) Filter {
 		return services
 	}
 }
+
+// FilterLocality is a locality based Select Filter which prefers nodes
+// in the same zone, then nodes in the same region. Every node is returned
+// only when there are none nearby. Nodes are placed by their zone and
+// region metadata. Selectors fail over to the next locality themselves
+// when their breaker finds every node nearby unavailable.
+func FilterLocality(region, zone string) Filter {
+	return func(old []*registry.Service) []*registry.Service {
+		return Localities(region, zone, old)[0]
+	}
+}
+
+// Localities returns the services split by how close their nodes are
+// to the region and zone. The nodes in the same zone come first, then
+// the nodes in the same region and finally every node. Localities
+// without any nodes are left out.
+func Localities(region, zone string, old []*registry.Service) [][]*registry.Service {
+	var localities [][]*registry.Service
+
+	// add the services unless they're empty or the same
+	// as the nodes of the locality closer to us
+	add := func(services []*registry.Service) {
+		n := count(services)
+		if n == 0 {
+			return
+		}
+		if l := len(localities); l > 0 && count(localities[l-1]) == n {
+			return
+		}
+		localities = append(localities, services)
+	}
+
+	if len(zone) > 0 {
+		add(filterNodes(old, registry.MetadataZone, zone))
+	}
+
+	if len(region) > 0 {
+		add(filterNodes(old, registry.MetadataRegion, region))
+	}
+
+	add(old)
+
+	// there are no nodes at all
+	if len(localities) == 0 {
+		localities = append(localities, old)
+	}
+
+	return localities
+}
+
+// FilterAvailable is a Select Filter which drops the nodes of the
+// service the breaker doesn't allow calls to
+func FilterAvailable(name string, b Breaker) Filter {
+	return func(old []*registry.Service) []*registry.Service {
+		var services []*registry.Service
+
+		for _, service := range old {
+			var nodes []*registry.Node
+
+			for _, node := range service.Nodes {
+				if b.Available(name, node) {
+					nodes = append(nodes, node)
+				}
+			}
+
+			// only add service if there's some nodes
+			if len(nodes) > 0 {
+				serv := new(registry.Service)
+				*serv = *service
+				serv.Nodes = nodes
+				services = append(services, serv)
+			}
+		}
+
+		return services
+	}
+}
+
+// count returns the number of nodes of the services
+func count(services []*registry.Service) int {
+	var n int
+	for _, service := range services {
+		n += len(service.Nodes)
+	}
+	return n
+}
+
+// filterNodes returns the services with only the nodes whose metadata key is val
+func filterNodes(old []*registry.Service, key, val string) []*registry.Service {
+	var services []*registry.Service
+
+	for _, service := range old {
+		var nodes []*registry.Node
+
+		for _, node := range service.Nodes {
+			if node.Metadata != nil && node.Metadata[key] == val {
+				nodes = append(nodes, node)
+			}
+		}
+
+		// only add service if there's some nodes
+		if len(nodes) > 0 {
+			serv := new(registry.Service)
+			*serv = *service
+			serv.Nodes = nodes
+			services = append(services, serv)
+		}
+	}
+
+	return services
+}
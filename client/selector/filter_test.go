@@ -237,3 +237,60 @@ func TestFilterVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterLocality(t *testing.T) {
+	node := func(id, region, zone string) *registry.Node {
+		return &registry.Node{
+			Id: id,
+			Metadata: map[string]string{
+				registry.MetadataRegion: region,
+				registry.MetadataZone:   zone,
+			},
+		}
+	}
+
+	services := []*registry.Service{
+		{
+			Name:    "test",
+			Version: "1.0.0",
+			Nodes: []*registry.Node{
+				node("test-1", "eu-west", "eu-west-a"),
+				node("test-2", "eu-west", "eu-west-b"),
+				node("test-3", "us-east", "us-east-a"),
+			},
+		},
+	}
+
+	testData := []struct {
+		region string
+		zone   string
+		nodes  []string
+	}{
+		// same zone
+		{"eu-west", "eu-west-a", []string{"test-1"}},
+		// no nodes in the zone so fail over to the region
+		{"eu-west", "eu-west-c", []string{"test-1", "test-2"}},
+		// no nodes nearby so use every node
+		{"ap-south", "ap-south-a", []string{"test-1", "test-2", "test-3"}},
+	}
+
+	for _, data := range testData {
+		filtered := FilterLocality(data.region, data.zone)(services)
+
+		var nodes []string
+		for _, service := range filtered {
+			for _, node := range service.Nodes {
+				nodes = append(nodes, node.Id)
+			}
+		}
+
+		if len(nodes) != len(data.nodes) {
+			t.Fatalf("Expected %v for %s/%s got %v", data.nodes, data.region, data.zone, nodes)
+		}
+		for i, id := range data.nodes {
+			if nodes[i] != id {
+				t.Fatalf("Expected %v for %s/%s got %v", data.nodes, data.region, data.zone, nodes)
+			}
+		}
+	}
+}
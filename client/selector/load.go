@@ -0,0 +1,168 @@
+package selector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/registry"
+)
+
+var (
+	// weight of the latest call in the average latency of a node
+	loadDecay = 0.3
+	// nodes idle for longer are forgotten
+	loadIdleTime = 10 * time.Minute
+	// latency assumed for nodes without calls
+	loadDefaultLatency = time.Millisecond
+	// calls in flight for longer are assumed to have been
+	// lost without the selector being marked
+	loadMaxCallTime = time.Minute
+)
+
+// calls maps the nodes returned by the load aware strategies to the
+// call they started. Each call gets its own copy of the node so the
+// node passed to Mark or Release identifies the call which finished.
+var calls = struct {
+	sync.Mutex
+	m map[*registry.Node]*loadCall
+}{
+	m: make(map[*registry.Node]*loadCall),
+}
+
+// loadTracker tracks the calls to nodes for a load aware strategy.
+// Calls start when the strategy returns a node and end when the
+// selector is marked with the result.
+type loadTracker struct {
+	sync.Mutex
+	nodes  map[string]*nodeLoad
+	pruned time.Time
+}
+
+type loadCall struct {
+	tracker *loadTracker
+	load    *nodeLoad
+	start   time.Time
+}
+
+type nodeLoad struct {
+	// calls in flight keyed by the node returned for them
+	inflight map[*registry.Node]*loadCall
+	latency  time.Duration
+	updated  time.Time
+}
+
+func newLoadTracker() *loadTracker {
+	return &loadTracker{
+		nodes:  make(map[string]*nodeLoad),
+		pruned: time.Now(),
+	}
+}
+
+func (l *loadTracker) get(id string) *nodeLoad {
+	n, ok := l.nodes[id]
+	if !ok {
+		n = &nodeLoad{inflight: make(map[*registry.Node]*loadCall)}
+		l.nodes[id] = n
+	}
+	return n
+}
+
+// Release records that a call to a node returned by a load aware
+// strategy has finished. Selectors call it when they're marked and
+// clients for nodes they were given but didn't call. Nodes which
+// weren't returned by a load aware strategy are ignored.
+func Release(node *registry.Node) {
+	calls.Lock()
+	call, ok := calls.m[node]
+	delete(calls.m, node)
+	calls.Unlock()
+
+	if ok {
+		call.tracker.done(node, call)
+	}
+}
+
+// expire drops the calls started before the time
+func (n *nodeLoad) expire(before time.Time) {
+	for node, call := range n.inflight {
+		if call.start.Before(before) {
+			delete(n.inflight, node)
+			calls.Lock()
+			delete(calls.m, node)
+			calls.Unlock()
+		}
+	}
+}
+
+// score returns the expected wait for a new call to the node
+func (l *loadTracker) score(node *registry.Node) time.Duration {
+	n, ok := l.nodes[node.Id]
+	if !ok {
+		return loadDefaultLatency
+	}
+
+	n.expire(time.Now().Add(-loadMaxCallTime))
+
+	latency := n.latency
+	if latency == 0 {
+		latency = loadDefaultLatency
+	}
+
+	return time.Duration(len(n.inflight)+1) * latency
+}
+
+// start records a call being made to the node. It returns
+// the copy of the node which identifies the call.
+func (l *loadTracker) start(node *registry.Node) *registry.Node {
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+
+	token := new(registry.Node)
+	*token = *node
+
+	n := l.get(node.Id)
+	call := &loadCall{tracker: l, load: n, start: now}
+	n.inflight[token] = call
+	n.updated = now
+
+	calls.Lock()
+	calls.m[token] = call
+	calls.Unlock()
+
+	// forget nodes which have gone away
+	if now.Sub(l.pruned) > loadIdleTime {
+		for id, n := range l.nodes {
+			if len(n.inflight) == 0 && now.Sub(n.updated) > loadIdleTime {
+				delete(l.nodes, id)
+			}
+		}
+		l.pruned = now
+	}
+
+	return token
+}
+
+// done records the call completing
+func (l *loadTracker) done(token *registry.Node, call *loadCall) {
+	l.Lock()
+	defer l.Unlock()
+
+	n := call.load
+	if _, ok := n.inflight[token]; !ok {
+		// the call expired
+		return
+	}
+	delete(n.inflight, token)
+
+	now := time.Now()
+	took := now.Sub(call.start)
+	n.updated = now
+
+	if n.latency == 0 {
+		n.latency = took
+	} else {
+		n.latency = time.Duration(loadDecay*float64(took) + (1-loadDecay)*float64(n.latency))
+	}
+}
@@ -9,6 +9,14 @@ import (
 type Options struct {
 	Registry registry.Registry
 	Strategy Strategy
+	// Breaker is used to skip nodes with an open circuit
+	Breaker Breaker
+	// Rules route requests to versions of services
+	Rules Rules
+	// Region and Zone of the client. Nodes in the
+	// same zone then region are preferred.
+	Region string
+	Zone   string
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -18,6 +26,8 @@ type Options struct {
 type SelectOptions struct {
 	Filters  []Filter
 	Strategy Strategy
+	// Metadata of the request used by the rules
+	Metadata map[string]string
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -44,6 +54,29 @@ func SetStrategy(fn Strategy) Option {
 	}
 }
 
+// SetBreaker sets the circuit breaker consulted when selecting nodes
+func SetBreaker(b Breaker) Option {
+	return func(o *Options) {
+		o.Breaker = b
+	}
+}
+
+// SetRules sets the rules routing requests to versions of services
+func SetRules(r Rules) Option {
+	return func(o *Options) {
+		o.Rules = r
+	}
+}
+
+// Locality sets the region and zone of the client
+// so nodes closest to it are selected first
+func Locality(region, zone string) Option {
+	return func(o *Options) {
+		o.Region = region
+		o.Zone = zone
+	}
+}
+
 // WithFilter adds a filter function to the list of filters
 // used during the Select call.
 func WithFilter(fn ...Filter) SelectOption {
@@ -52,6 +85,13 @@ func WithFilter(fn ...Filter) SelectOption {
 	}
 }
 
+// WithMetadata sets the metadata of the request being routed
+func WithMetadata(md map[string]string) SelectOption {
+	return func(o *SelectOptions) {
+		o.Metadata = md
+	}
+}
+
 // Strategy sets the selector strategy
 func WithStrategy(fn Strategy) SelectOption {
 	return func(o *SelectOptions) {
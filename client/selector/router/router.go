@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"sort"
+	"strconv"
 	"sync"
 
 	"github.com/micro/go-micro/client"
@@ -106,12 +107,13 @@ func (r *routerSelector) getRoutes(service string) ([]router.Route, error) {
 	// convert from pb to []*router.Route
 	for _, r := range pbRoutes.Routes {
 		routes = append(routes, router.Route{
-			Service: r.Service,
-			Address: r.Address,
-			Gateway: r.Gateway,
-			Network: r.Network,
-			Link:    r.Link,
-			Metric:  r.Metric,
+			Service:  r.Service,
+			Address:  r.Address,
+			Gateway:  r.Gateway,
+			Network:  r.Network,
+			Link:     r.Link,
+			Metric:   r.Metric,
+			Metadata: r.Metadata,
 		})
 	}
 
@@ -119,7 +121,9 @@ func (r *routerSelector) getRoutes(service string) ([]router.Route, error) {
 }
 
 func (r *routerSelector) Init(opts ...selector.Option) error {
-	// no op
+	for _, o := range opts {
+		o(&r.opts)
+	}
 	return nil
 }
 
@@ -139,42 +143,102 @@ func (r *routerSelector) Select(service string, opts ...selector.SelectOption) (
 		return nil, selector.ErrNotFound
 	}
 
-	// TODO: apply filters by pseudo constructing service
+	sopts := selector.SelectOptions{
+		Strategy: r.opts.Strategy,
+	}
+
+	for _, opt := range opts {
+		opt(&sopts)
+	}
 
 	// sort the routes based on metric
 	sort.Slice(routes, func(i, j int) bool {
 		return routes[i].Metric < routes[j].Metric
 	})
 
-	// roundrobin assuming routes are in metric preference order
+	// pseudo construct a service from the routes to filter them
+	nodes := make([]*registry.Node, 0, len(routes))
+
+	for _, route := range routes {
+		// defaults to gateway and no port
+		address := route.Address
+		if len(route.Gateway) > 0 {
+			address = route.Gateway
+		}
+
+		nodes = append(nodes, &registry.Node{
+			Id:       strconv.FormatUint(route.Hash(), 10),
+			Address:  address,
+			Metadata: route.Metadata,
+		})
+	}
+
+	services := []*registry.Service{{Name: service, Nodes: nodes}}
+
+	// drop nodes with an open circuit
+	if r.opts.Breaker != nil {
+		services = selector.FilterAvailable(service, r.opts.Breaker)(services)
+	}
+
+	// route the request to versions by the rules
+	if r.opts.Rules != nil {
+		services = r.opts.Rules.Route(services, sopts.Metadata)
+	}
+
+	// apply the filters
+	for _, filter := range sopts.Filters {
+		services = filter(services)
+	}
+
+	// if there's nothing left, return
+	if len(services) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	// prefer nodes closest to us
+	localities := [][]*registry.Service{services}
+	if len(r.opts.Region) > 0 || len(r.opts.Zone) > 0 {
+		localities = selector.Localities(r.opts.Region, r.opts.Zone, services)
+	}
+
+	strategy := sopts.Strategy
+	if strategy == nil {
+		strategy = byMetric
+	}
+
+	return selector.Failover(service, r.opts.Breaker, strategy, localities), nil
+}
+
+// byMetric is a roundrobin strategy assuming the
+// nodes of the routes are in metric preference order
+func byMetric(services []*registry.Service) selector.Next {
+	var filtered []*registry.Node
+	for _, service := range services {
+		filtered = append(filtered, service.Nodes...)
+	}
+
 	var i int
 	var mtx sync.Mutex
 
 	return func() (*registry.Node, error) {
+		if len(filtered) == 0 {
+			return nil, selector.ErrNoneAvailable
+		}
+
 		// get index and increment counter with every call to next
 		mtx.Lock()
 		idx := i
 		i++
 		mtx.Unlock()
 
-		// get route based on idx
-		route := routes[idx%len(routes)]
-
-		// defaults to gateway and no port
-		address := route.Address
-		if len(route.Gateway) > 0 {
-			address = route.Gateway
-		}
-
-		// return as a node
-		return &registry.Node{
-			// TODO: add id and metadata if we can
-			Address: address,
-		}, nil
-	}, nil
+		// get node based on idx
+		return filtered[idx%len(filtered)], nil
+	}
 }
 
 func (r *routerSelector) Mark(service string, node *registry.Node, err error) {
+	// end the call for the load aware strategies
+	selector.Release(node)
 	// TODO: pass back metrics or information to the router
 }
 
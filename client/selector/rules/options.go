@@ -0,0 +1,28 @@
+package rules
+
+import (
+	"github.com/micro/go-micro/config"
+)
+
+type Options struct {
+	// Config the rules are loaded from
+	Config config.Config
+	// Path of the rules in the config
+	Path []string
+}
+
+type Option func(o *Options)
+
+// Config sets the config the rules are loaded from. Defaults to config.DefaultConfig
+func Config(c config.Config) Option {
+	return func(o *Options) {
+		o.Config = c
+	}
+}
+
+// Path sets the path of the rules in the config. Defaults to DefaultPath
+func Path(path ...string) Option {
+	return func(o *Options) {
+		o.Path = path
+	}
+}
@@ -0,0 +1,299 @@
+// Package rules routes requests to versions of a service using rules loaded from config
+package rules
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/client/selector"
+	"github.com/micro/go-micro/config"
+	"github.com/micro/go-micro/config/reader"
+	"github.com/micro/go-micro/registry"
+	"github.com/micro/go-micro/util/backoff"
+	"github.com/micro/go-micro/util/log"
+)
+
+var (
+	// DefaultPath is the path of the rules in the config
+	DefaultPath = []string{"micro", "routes"}
+
+	// CallerHeader is the metadata header holding the calling service
+	CallerHeader = "Micro-From-Service"
+
+	// longest time to wait before watching the rules again
+	watchMaxBackoff = 30 * time.Second
+)
+
+// Rule routes matching requests for a service to its versions. A rule with
+// no caller or header matches every request for the service.
+type Rule struct {
+	// Service the rule applies to
+	Service string `json:"service"`
+	// Caller only matches requests from the service
+	Caller string `json:"caller,omitempty"`
+	// Header only matches requests with all the metadata values
+	Header map[string]string `json:"header,omitempty"`
+	// Versions the requests are split between
+	Versions []Destination `json:"versions"`
+}
+
+// Destination is a version receiving a share of the requests
+type Destination struct {
+	Version string `json:"version"`
+	// Weight is the share of requests relative to the other versions.
+	// Versions are weighted equally when none of them has a weight.
+	Weight int `json:"weight"`
+}
+
+// Rules are evaluated in order and the first matching rule is used.
+// They're reloaded whenever the config changes.
+type Rules struct {
+	opts Options
+
+	sync.RWMutex
+	rules map[string][]Rule
+
+	w    config.Watcher
+	exit chan bool
+}
+
+// NewRules loads the rules from the config and watches them for changes
+func NewRules(opts ...Option) (*Rules, error) {
+	options := Options{
+		Config: config.DefaultConfig,
+		Path:   DefaultPath,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	r := &Rules{
+		opts: options,
+		exit: make(chan bool),
+	}
+
+	if err := r.load(options.Config.Get(options.Path...)); err != nil {
+		return nil, err
+	}
+
+	w, err := options.Config.Watch(options.Path...)
+	if err != nil {
+		return nil, err
+	}
+	r.w = w
+
+	go r.watch()
+
+	return r, nil
+}
+
+func (r *Rules) load(v reader.Value) error {
+	var list []Rule
+	if err := v.Scan(&list); err != nil {
+		return err
+	}
+
+	rules := make(map[string][]Rule)
+	for _, rule := range list {
+		rules[rule.Service] = append(rules[rule.Service], rule)
+	}
+
+	r.Lock()
+	r.rules = rules
+	r.Unlock()
+
+	return nil
+}
+
+// watch reloads the rules when they change. The watcher
+// is created again with backoff if it fails.
+func (r *Rules) watch() {
+	var attempts int
+
+	for {
+		r.RLock()
+		w := r.w
+		r.RUnlock()
+
+		v, err := w.Next()
+		if err == nil {
+			attempts = 0
+			if err := r.load(v); err != nil {
+				log.Logf("Error loading routing rules: %v", err)
+			}
+			continue
+		}
+
+		w.Stop()
+
+		for {
+			select {
+			case <-r.exit:
+				return
+			default:
+			}
+
+			log.Logf("Error watching routing rules: %v", err)
+
+			attempts++
+			select {
+			case <-r.exit:
+				return
+			case <-time.After(watchBackoff(attempts)):
+			}
+
+			if w, err = r.opts.Config.Watch(r.opts.Path...); err == nil {
+				break
+			}
+		}
+
+		r.Lock()
+		r.w = w
+		r.Unlock()
+
+		// pick up changes made while there was no watcher
+		if err := r.load(r.opts.Config.Get(r.opts.Path...)); err != nil {
+			log.Logf("Error loading routing rules: %v", err)
+		}
+	}
+}
+
+// watchBackoff returns the time to wait before watching again
+func watchBackoff(attempts int) time.Duration {
+	if attempts > 4 {
+		return watchMaxBackoff
+	}
+	if d := backoff.Do(attempts); d < watchMaxBackoff {
+		return d
+	}
+	return watchMaxBackoff
+}
+
+// Set replaces the rules
+func (r *Rules) Set(rules ...Rule) {
+	m := make(map[string][]Rule)
+	for _, rule := range rules {
+		m[rule.Service] = append(m[rule.Service], rule)
+	}
+
+	r.Lock()
+	r.rules = m
+	r.Unlock()
+}
+
+// Route returns the versions of the services the request is routed to.
+// All the services are returned if no rule matches or the chosen
+// version has no nodes.
+func (r *Rules) Route(services []*registry.Service, md map[string]string) []*registry.Service {
+	if len(services) == 0 {
+		return services
+	}
+
+	r.RLock()
+	rules := r.rules[services[0].Name]
+	r.RUnlock()
+
+	for _, rule := range rules {
+		if !rule.matches(md) {
+			continue
+		}
+
+		version, ok := rule.pick()
+		if !ok {
+			return services
+		}
+
+		var routed []*registry.Service
+		for _, service := range services {
+			if service.Version == version && len(service.Nodes) > 0 {
+				routed = append(routed, service)
+			}
+		}
+
+		if len(routed) == 0 {
+			return services
+		}
+
+		return routed
+	}
+
+	return services
+}
+
+// Stop stops watching the config for changes
+func (r *Rules) Stop() error {
+	select {
+	case <-r.exit:
+		return nil
+	default:
+		close(r.exit)
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+	return r.w.Stop()
+}
+
+func (r Rule) matches(md map[string]string) bool {
+	if len(r.Caller) > 0 && get(md, CallerHeader) != r.Caller {
+		return false
+	}
+
+	for k, v := range r.Header {
+		if get(md, k) != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pick chooses a version at random by weight. Versions
+// are weighted equally when none of them has a weight.
+func (r Rule) pick() (string, bool) {
+	if len(r.Versions) == 0 {
+		return "", false
+	}
+
+	var total int
+	for _, d := range r.Versions {
+		if d.Weight > 0 {
+			total += d.Weight
+		}
+	}
+
+	if total == 0 {
+		return r.Versions[rand.Intn(len(r.Versions))].Version, true
+	}
+
+	n := rand.Intn(total)
+	for _, d := range r.Versions {
+		if d.Weight <= 0 {
+			continue
+		}
+		if n < d.Weight {
+			return d.Version, true
+		}
+		n -= d.Weight
+	}
+
+	return "", false
+}
+
+// get returns the metadata value matching the key case insensitively
+func get(md map[string]string, key string) string {
+	if v, ok := md[key]; ok {
+		return v
+	}
+	for k, v := range md {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+var _ selector.Rules = (*Rules)(nil)
@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/config"
+	"github.com/micro/go-micro/config/source"
+	"github.com/micro/go-micro/config/source/memory"
+	"github.com/micro/go-micro/registry"
+)
+
+var testServices = []*registry.Service{
+	{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "10.0.0.1:8080"}},
+	},
+	{
+		Name:    "foo",
+		Version: "2.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-2", Address: "10.0.0.2:8080"}},
+	},
+}
+
+var testRules = []byte(`{
+	"micro": {
+		"routes": [
+			{"service": "foo", "header": {"X-Canary": "true"}, "versions": [{"version": "2.0.0"}]},
+			{"service": "foo", "caller": "bar", "versions": [{"version": "2.0.0"}]},
+			{"service": "foo", "versions": [{"version": "1.0.0", "weight": 1}, {"version": "3.0.0", "weight": 0}]}
+		]
+	}
+}`)
+
+func newTestRules(t *testing.T, data []byte) (*Rules, source.Source) {
+	src := memory.NewSource(memory.WithJSON(data))
+	c := config.NewConfig()
+	if err := c.Load(src); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRules(Config(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, src
+}
+
+func version(services []*registry.Service) string {
+	if len(services) != 1 {
+		return ""
+	}
+	return services[0].Version
+}
+
+func TestRules(t *testing.T) {
+	r, _ := newTestRules(t, testRules)
+	defer r.Stop()
+
+	testData := []struct {
+		md      map[string]string
+		version string
+	}{
+		{map[string]string{"x-canary": "true"}, "2.0.0"},
+		{map[string]string{CallerHeader: "bar"}, "2.0.0"},
+		{map[string]string{CallerHeader: "baz"}, "1.0.0"},
+		{nil, "1.0.0"},
+	}
+
+	for _, d := range testData {
+		if v := version(r.Route(testServices, d.md)); v != d.version {
+			t.Fatalf("Expected version %s for %v got %q", d.version, d.md, v)
+		}
+	}
+
+	// services without rules are untouched
+	other := []*registry.Service{{Name: "bar", Version: "1.0.0"}, {Name: "bar", Version: "2.0.0"}}
+	if got := r.Route(other, nil); len(got) != 2 {
+		t.Fatalf("Expected 2 services got %d", len(got))
+	}
+
+	// fall back to all services when the version has no nodes
+	r.Set(Rule{Service: "foo", Versions: []Destination{{Version: "3.0.0"}}})
+	if got := r.Route(testServices, nil); len(got) != 2 {
+		t.Fatalf("Expected 2 services got %d", len(got))
+	}
+}
+
+func TestRulesWeight(t *testing.T) {
+	r, _ := newTestRules(t, nil)
+	defer r.Stop()
+
+	r.Set(Rule{
+		Service: "foo",
+		Versions: []Destination{
+			{Version: "1.0.0", Weight: 90},
+			{Version: "2.0.0", Weight: 10},
+		},
+	})
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		counts[version(r.Route(testServices, nil))]++
+	}
+
+	if c := counts["2.0.0"]; c < 700 || c > 1300 {
+		t.Fatalf("Expected around 1000 requests to 2.0.0 got %d", c)
+	}
+	if c := counts["1.0.0"] + counts["2.0.0"]; c != 10000 {
+		t.Fatalf("Expected 10000 routed requests got %d", c)
+	}
+}
+
+func TestRulesReload(t *testing.T) {
+	r, src := newTestRules(t, testRules)
+	defer r.Stop()
+
+	if v := version(r.Route(testServices, nil)); v != "1.0.0" {
+		t.Fatalf("Expected version 1.0.0 got %q", v)
+	}
+
+	update := src.(interface{ Update(*source.ChangeSet) })
+
+	for i := 0; i < 100; i++ {
+		// keep updating until the config is watching the source
+		update.Update(&source.ChangeSet{
+			Data:   []byte(`{"micro": {"routes": [{"service": "foo", "versions": [{"version": "2.0.0"}]}]}}`),
+			Format: "json",
+		})
+		time.Sleep(10 * time.Millisecond)
+
+		if v := version(r.Route(testServices, nil)); v == "2.0.0" {
+			return
+		}
+	}
+
+	t.Fatal("Expected rules to be reloaded")
+}
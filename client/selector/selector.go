@@ -35,9 +35,25 @@ type Filter func([]*registry.Service) []*registry.Service
 // Strategy is a selection strategy e.g random, round robin
 type Strategy func([]*registry.Service) Next
 
+// Rules route requests to versions of a service
+type Rules interface {
+	// Route returns the services a request with the metadata is routed to
+	Route(services []*registry.Service, md map[string]string) []*registry.Service
+}
+
+// Breaker tracks the circuit state of nodes. Nodes which
+// are not available are skipped during selection.
+type Breaker interface {
+	// Available returns false while the node's circuit is open
+	Available(service string, node *registry.Node) bool
+}
+
 var (
 	DefaultSelector = NewSelector()
 
+	// DefaultWeight is the weight of nodes without weight metadata
+	DefaultWeight = 100
+
 	ErrNotFound      = errors.New("not found")
 	ErrNoneAvailable = errors.New("none available")
 )
@@ -1,7 +1,10 @@
 package selector
 
 import (
+	"hash/fnv"
 	"math/rand"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -54,3 +57,239 @@ func RoundRobin(services []*registry.Service) Next {
 		return node, nil
 	}
 }
+
+// Weighted is a strategy which picks nodes at random in proportion to
+// their weight metadata. Nodes without a weight have DefaultWeight so
+// a canary with a weight of 5 receives around 5% of their traffic.
+func Weighted(services []*registry.Service) Next {
+	nodes := make([]*registry.Node, 0, len(services))
+	weights := make([]int, 0, len(services))
+	var total int
+
+	for _, service := range services {
+		for _, node := range service.Nodes {
+			w := weight(node)
+			nodes = append(nodes, node)
+			weights = append(weights, w)
+			total += w
+		}
+	}
+
+	return func() (*registry.Node, error) {
+		if len(nodes) == 0 {
+			return nil, ErrNoneAvailable
+		}
+
+		// every node is drained so spread the traffic evenly
+		if total == 0 {
+			return nodes[rand.Int()%len(nodes)], nil
+		}
+
+		n := rand.Intn(total)
+		for i, w := range weights {
+			if n < w {
+				return nodes[i], nil
+			}
+			n -= w
+		}
+
+		return nodes[len(nodes)-1], nil
+	}
+}
+
+// weight returns the weight of the node from its metadata
+func weight(node *registry.Node) int {
+	w, err := strconv.Atoi(node.Metadata[registry.MetadataWeight])
+	if err != nil || w < 0 {
+		return DefaultWeight
+	}
+	return w
+}
+
+// LeastLoaded returns a strategy which picks the node with the lowest
+// expected wait based on its calls in flight and average latency.
+// Calls are tracked until the selector is marked with their result,
+// so each node returned is a copy identifying its call. Each strategy
+// returned tracks its own calls so selectors don't share their loads.
+func LeastLoaded() Strategy {
+	loads := newLoadTracker()
+
+	return func(services []*registry.Service) Next {
+		nodes := make([]*registry.Node, 0, len(services))
+
+		for _, service := range services {
+			nodes = append(nodes, service.Nodes...)
+		}
+
+		return func() (*registry.Node, error) {
+			if len(nodes) == 0 {
+				return nil, ErrNoneAvailable
+			}
+
+			loads.Lock()
+			// start at a random node so ties are spread out
+			offset := rand.Int()
+			node := nodes[offset%len(nodes)]
+			score := loads.score(node)
+			for i := 1; i < len(nodes); i++ {
+				n := nodes[(offset+i)%len(nodes)]
+				if s := loads.score(n); s < score {
+					node, score = n, s
+				}
+			}
+			loads.Unlock()
+
+			return loads.start(node), nil
+		}
+	}
+}
+
+// PowerOfTwo returns a strategy which picks two nodes at random and
+// uses the one with the lower load as tracked by LeastLoaded
+func PowerOfTwo() Strategy {
+	loads := newLoadTracker()
+
+	return func(services []*registry.Service) Next {
+		nodes := make([]*registry.Node, 0, len(services))
+
+		for _, service := range services {
+			nodes = append(nodes, service.Nodes...)
+		}
+
+		return func() (*registry.Node, error) {
+			if len(nodes) == 0 {
+				return nil, ErrNoneAvailable
+			}
+
+			node := nodes[rand.Int()%len(nodes)]
+			if len(nodes) > 1 {
+				i := rand.Int() % len(nodes)
+				j := (i + 1 + rand.Int()%(len(nodes)-1)) % len(nodes)
+
+				loads.Lock()
+				if loads.score(nodes[i]) <= loads.score(nodes[j]) {
+					node = nodes[i]
+				} else {
+					node = nodes[j]
+				}
+				loads.Unlock()
+			}
+
+			return loads.start(node), nil
+		}
+	}
+}
+
+// ConsistentHash returns a strategy which maps the key onto a hash ring
+// of the nodes so requests for the same key go to the same node. Only
+// keys owned by nodes which come or go move. Subsequent calls to Next
+// walk the ring returning each of the other nodes in turn.
+func ConsistentHash(key string) Strategy {
+	return func(services []*registry.Service) Next {
+		var ring []ringPoint
+		ids := make(map[string]bool)
+
+		for _, service := range services {
+			for _, node := range service.Nodes {
+				if ids[node.Id] {
+					continue
+				}
+				ids[node.Id] = true
+				for i := 0; i < hashReplicas; i++ {
+					ring = append(ring, ringPoint{
+						hash: hash(node.Id + "-" + strconv.Itoa(i)),
+						node: node,
+					})
+				}
+			}
+		}
+
+		sort.Slice(ring, func(i, j int) bool {
+			return ring[i].hash < ring[j].hash
+		})
+
+		h := hash(key)
+		pos := sort.Search(len(ring), func(i int) bool {
+			return ring[i].hash >= h
+		})
+
+		var mtx sync.Mutex
+		seen := make(map[string]bool)
+
+		return func() (*registry.Node, error) {
+			if len(ring) == 0 {
+				return nil, ErrNoneAvailable
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			// start over once every node has been returned
+			if len(seen) == len(ids) {
+				seen = make(map[string]bool)
+			}
+
+			for {
+				p := ring[pos%len(ring)]
+				if !seen[p.node.Id] {
+					seen[p.node.Id] = true
+					return p.node, nil
+				}
+				pos++
+			}
+		}
+	}
+}
+
+// points on the hash ring for each node
+var hashReplicas = 64
+
+type ringPoint struct {
+	hash uint32
+	node *registry.Node
+}
+
+func hash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// Failover returns a Next which picks nodes of the first locality with
+// the strategy, skipping those the breaker doesn't allow calls to. Nodes
+// of the next locality are picked once every node of the one before is
+// unavailable. Circuits may open between calls to next so the breaker is
+// checked for every node picked.
+func Failover(service string, b Breaker, strategy Strategy, localities [][]*registry.Service) Next {
+	nexts := make([]Next, len(localities))
+	var mtx sync.Mutex
+
+	return func() (*registry.Node, error) {
+		for i, services := range localities {
+			mtx.Lock()
+			if nexts[i] == nil {
+				nexts[i] = strategy(services)
+			}
+			next := nexts[i]
+			mtx.Unlock()
+
+			if b == nil {
+				return next()
+			}
+
+			for j := count(services); j > 0; j-- {
+				node, err := next()
+				if err != nil {
+					return nil, err
+				}
+				if b.Available(service, node) {
+					return node, nil
+				}
+				// the node won't be called
+				Release(node)
+			}
+		}
+
+		return nil, ErrNoneAvailable
+	}
+}
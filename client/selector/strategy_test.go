@@ -1,7 +1,9 @@
 package selector
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/micro/go-micro/registry"
 )
@@ -38,7 +40,15 @@ func TestStrategies(t *testing.T) {
 		},
 	}
 
-	for name, strategy := range map[string]Strategy{"random": Random, "roundrobin": RoundRobin} {
+	strategies := map[string]Strategy{
+		"random":         Random,
+		"roundrobin":     RoundRobin,
+		"leastloaded":    LeastLoaded(),
+		"poweroftwo":     PowerOfTwo(),
+		"consistenthash": ConsistentHash("key"),
+	}
+
+	for name, strategy := range strategies {
 		next := strategy(testData)
 		counts := make(map[string]int)
 
@@ -48,8 +58,182 @@ func TestStrategies(t *testing.T) {
 				t.Fatal(err)
 			}
 			counts[node.Id]++
+			Release(node)
 		}
 
 		t.Logf("%s: %+v\n", name, counts)
 	}
 }
+
+func TestLeastLoaded(t *testing.T) {
+	services := []*registry.Service{
+		{
+			Name: "test2",
+			Nodes: []*registry.Node{
+				{Id: "test2-1"},
+				{Id: "test2-2"},
+			},
+		},
+	}
+
+	next := LeastLoaded()(services)
+
+	// calls in flight spread across the nodes
+	n1, _ := next()
+	n2, _ := next()
+	if n1.Id == n2.Id {
+		t.Fatalf("Expected calls on different nodes got %s twice", n1.Id)
+	}
+
+	// the node which completes its call is picked next
+	Release(n2)
+	n3, _ := next()
+	if n3.Id != n2.Id {
+		t.Fatalf("Expected %s got %s", n2.Id, n3.Id)
+	}
+
+	// the call released is the one which finished
+	loads := newLoadTracker()
+	c1 := loads.start(n1)
+	c2 := loads.start(n1)
+
+	Release(c2)
+	load := loads.nodes[n1.Id]
+	if _, ok := load.inflight[c1]; !ok || len(load.inflight) != 1 {
+		t.Fatalf("Expected only the released call to end got %d in flight", len(load.inflight))
+	}
+
+	// releasing a call twice has no effect
+	Release(c2)
+	if len(load.inflight) != 1 {
+		t.Fatalf("Expected 1 call in flight got %d", len(load.inflight))
+	}
+	Release(c1)
+
+	Release(n1)
+	Release(n3)
+
+	// calls which are never released expire
+	n4, _ := next()
+	calls.Lock()
+	call := calls.m[n4]
+	calls.Unlock()
+	call.tracker.Lock()
+	call.start = time.Now().Add(-2 * loadMaxCallTime)
+	call.tracker.score(n4)
+	inflight := len(call.load.inflight)
+	call.tracker.Unlock()
+	if inflight != 0 {
+		t.Fatalf("Expected expired call to be dropped got %d in flight", inflight)
+	}
+
+	calls.Lock()
+	_, ok := calls.m[n4]
+	calls.Unlock()
+	if ok {
+		t.Fatal("Expected expired call to be forgotten")
+	}
+}
+
+func TestLoadTrackers(t *testing.T) {
+	services := []*registry.Service{
+		{
+			Name:  "test4",
+			Nodes: []*registry.Node{{Id: "test4-1"}},
+		},
+	}
+
+	n1, _ := LeastLoaded()(services)()
+	n2, _ := LeastLoaded()(services)()
+
+	calls.Lock()
+	c1, c2 := calls.m[n1], calls.m[n2]
+	calls.Unlock()
+
+	// strategies don't share their loads
+	if c1.tracker == c2.tracker || len(c1.load.inflight) != 1 || len(c2.load.inflight) != 1 {
+		t.Fatal("Expected separate trackers")
+	}
+
+	Release(n1)
+	Release(n2)
+}
+
+func TestConsistentHash(t *testing.T) {
+	var nodes []*registry.Node
+	for i := 0; i < 5; i++ {
+		nodes = append(nodes, &registry.Node{Id: fmt.Sprintf("test3-%d", i)})
+	}
+
+	pick := func(key string, nodes []*registry.Node) *registry.Node {
+		next := ConsistentHash(key)([]*registry.Service{{Name: "test3", Nodes: nodes}})
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		node := pick(key, nodes)
+
+		if n := pick(key, nodes); n.Id != node.Id {
+			t.Fatalf("Expected %s for %s got %s", node.Id, key, n.Id)
+		}
+
+		// removing another node doesn't move the key
+		var others []*registry.Node
+		for _, n := range nodes {
+			if n.Id != node.Id && len(others) < 3 {
+				others = append(others, n)
+			}
+		}
+		if n := pick(key, append(others, node)); n.Id != node.Id {
+			t.Fatalf("Expected %s for %s after churn got %s", node.Id, key, n.Id)
+		}
+	}
+
+	// next walks every node
+	next := ConsistentHash("key")([]*registry.Service{{Name: "test3", Nodes: nodes}})
+	seen := make(map[string]bool)
+	for range nodes {
+		node, _ := next()
+		seen[node.Id] = true
+	}
+	if len(seen) != len(nodes) {
+		t.Fatalf("Expected %d nodes got %d", len(nodes), len(seen))
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	services := []*registry.Service{
+		{
+			Name: "test4",
+			Nodes: []*registry.Node{
+				{Id: "test4-stable"},
+				{Id: "test4-canary", Metadata: map[string]string{registry.MetadataWeight: "10"}},
+				{Id: "test4-drained", Metadata: map[string]string{registry.MetadataWeight: "0"}},
+			},
+		},
+	}
+
+	next := Weighted(services)
+	counts := make(map[string]int)
+
+	for i := 0; i < 11000; i++ {
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[node.Id]++
+	}
+
+	if counts["test4-drained"] > 0 {
+		t.Fatalf("Expected no traffic to drained node got %d", counts["test4-drained"])
+	}
+	// the canary has 1 in 11 of the traffic
+	if c := counts["test4-canary"]; c < 500 || c > 1500 {
+		t.Fatalf("Expected around 1000 calls to canary got %d", c)
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/micro/go-micro/client/selector"
+	"github.com/micro/go-micro/registry"
+)
+
+// markedStream marks the selector with the node of the stream once it ends
+type markedStream struct {
+	Stream
+	once sync.Once
+	mark func()
+}
+
+// MarkStream returns the stream which marks the selector with the node
+// it was opened to once the stream is closed or fails, so selectors see
+// the stream in flight for as long as it lasts. It's used by client
+// implementations in place of marking the selector when the stream opens.
+func MarkStream(s Stream, sel selector.Selector, service string, node *registry.Node) Stream {
+	m := &markedStream{Stream: s}
+	m.mark = func() {
+		sel.Mark(service, node, nil)
+	}
+	return m
+}
+
+func (m *markedStream) done(err error) error {
+	if err != nil {
+		m.once.Do(m.mark)
+	}
+	return err
+}
+
+func (m *markedStream) Send(msg interface{}) error {
+	return m.done(m.Stream.Send(msg))
+}
+
+func (m *markedStream) Recv(msg interface{}) error {
+	return m.done(m.Stream.Recv(msg))
+}
+
+func (m *markedStream) Close() error {
+	err := m.Stream.Close()
+	m.once.Do(m.mark)
+	return err
+}
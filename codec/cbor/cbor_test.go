@@ -0,0 +1,181 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testStruct struct {
+	Name  string            `json:"name"`
+	Count int               `cbor:"n"`
+	Neg   int64             `json:"neg"`
+	Big   uint64            `json:"big"`
+	Ratio float64           `json:"ratio"`
+	Ok    bool              `json:"ok"`
+	Data  []byte            `json:"data"`
+	Tags  []string          `json:"tags"`
+	Meta  map[string]string `json:"meta"`
+	Ptr   *string           `json:"ptr"`
+	Time  time.Time         `json:"time"`
+	Empty []int             `json:"empty,omitempty"`
+}
+
+// examples from appendix A of RFC 8949
+func TestEncode(t *testing.T) {
+	testData := []struct {
+		value interface{}
+		hex   string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{1000000000000, "1b000000e8d4a51000"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{float32(100000.0), "fa47c35000"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{"", "60"},
+		{"IETF", "6449455446"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]int{1, 2, 3}, "83010203"},
+		{map[string]int{"a": 1}, "a1616101"},
+		{time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "c074323031332d30332d32315432303a30343a30305a"},
+	}
+
+	for _, d := range testData {
+		b, err := marshal(d.value)
+		if err != nil {
+			t.Fatalf("Unexpected error encoding %v: %v", d.value, err)
+		}
+		if h := hex.EncodeToString(b); h != d.hex {
+			t.Fatalf("Expected %v to encode to %s got %s", d.value, d.hex, h)
+		}
+	}
+}
+
+// examples from appendix A of RFC 8949
+func TestDecode(t *testing.T) {
+	testData := []struct {
+		hex   string
+		value interface{}
+	}{
+		{"1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"3863", int64(-100)},
+		{"f93c00", float64(1)},
+		{"f97bff", float64(65504)},
+		{"f9c400", float64(-4)},
+		{"f90001", 5.960464477539063e-8},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9fff", []interface{}{}},
+		{"9f018202039f0405ffff", []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{"bf61610161629f0203ffff", map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		{"a201020304", map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}},
+		{"d82076687474703a2f2f7777772e6578616d706c652e636f6d", "http://www.example.com"},
+	}
+
+	for _, d := range testData {
+		b, _ := hex.DecodeString(d.hex)
+		var v interface{}
+		if err := unmarshal(b, &v); err != nil {
+			t.Fatalf("Unexpected error decoding %s: %v", d.hex, err)
+		}
+		if !reflect.DeepEqual(v, d.value) {
+			t.Fatalf("Expected %s to decode to %#v got %#v", d.hex, d.value, v)
+		}
+	}
+
+	// times as seconds since the epoch
+	for _, h := range []string{"c11a514b67b0", "c1fb41d452d9ec200000"} {
+		b, _ := hex.DecodeString(h)
+		var tm time.Time
+		if err := unmarshal(b, &tm); err != nil {
+			t.Fatalf("Unexpected error decoding %s: %v", h, err)
+		}
+		if tm.Unix() != 1363896240 {
+			t.Fatalf("Expected %s to decode to 1363896240 got %v", h, tm.Unix())
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	s := "bar"
+
+	in := testStruct{
+		Name:  "foo",
+		Count: 100000,
+		Neg:   math.MinInt64,
+		Big:   math.MaxUint64,
+		Ratio: 0.25,
+		Ok:    true,
+		Data:  bytes.Repeat([]byte{1}, 30),
+		Tags:  []string{"a", "b"},
+		Meta:  map[string]string{"foo": "bar"},
+		Ptr:   &s,
+		Time:  time.Unix(1, 123).UTC(),
+	}
+
+	var m Marshaler
+
+	b, err := m.Marshal(in)
+	if err != nil {
+		t.Fatalf("Unexpected error marshaling %v", err)
+	}
+
+	var out testStruct
+	if err := m.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unexpected error unmarshaling %v", err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("Expected %+v got %+v", in, out)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testData := []string{
+		"",
+		"1a0000",
+		"62ff",
+		"830102",
+		"0101",
+		"1c",
+		"ff",
+		"5f6161ff",
+		"3bffffffffffffffff",
+	}
+
+	for _, d := range testData {
+		b, _ := hex.DecodeString(d)
+		var v interface{}
+		if err := unmarshal(b, &v); err == nil {
+			t.Fatalf("Expected error decoding %s", d)
+		}
+	}
+}
+
+func TestDecodeDepth(t *testing.T) {
+	nested := func(n int) []byte {
+		return append(bytes.Repeat([]byte{0x81}, n), 0xf6)
+	}
+
+	var v interface{}
+	if err := unmarshal(nested(maxDepth-1), &v); err != nil {
+		t.Fatalf("Unexpected error decoding %v", err)
+	}
+
+	// arrays nested too deeply fail rather than exhausting the stack
+	if err := unmarshal(nested(maxDepth), &v); err == nil {
+		t.Fatal("Expected error decoding nested arrays")
+	}
+}
@@ -0,0 +1,316 @@
+package cbor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/micro/go-micro/codec/internal/value"
+)
+
+// tag of times as seconds since the epoch
+const epochTag = 1
+
+// additional information of items of indefinite length
+const indefinite = 31
+
+// maxDepth is how deeply arrays, maps and tags can be nested
+const maxDepth = 1000
+
+var (
+	errShort = errors.New("unexpected end of data")
+	errDepth = errors.New("exceeded max depth")
+
+	// marks the end of an item of indefinite length
+	errBreak = errors.New("unexpected break")
+)
+
+type decoder struct {
+	buf []byte
+	off int
+	// nesting of the current item
+	depth int
+}
+
+func unmarshal(b []byte, v interface{}) error {
+	d := &decoder{buf: b}
+	src, err := d.value()
+	if err != nil {
+		return fmt.Errorf("cbor: %v", err)
+	}
+	if d.off != len(d.buf) {
+		return errors.New("cbor: invalid data after top-level value")
+	}
+	if err := value.Decode(src, v, "cbor"); err != nil {
+		return fmt.Errorf("cbor: %v", err)
+	}
+	return nil
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.buf)-d.off) {
+		return nil, errShort
+	}
+	b := d.buf[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+func bigEndian(b []byte) uint64 {
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u
+}
+
+// head reads the major type, additional information and argument of an item
+func (d *decoder) head() (byte, byte, uint64, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major, info := b[0]>>5, b[0]&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		b, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, bigEndian(b), nil
+	case info == indefinite:
+		return major, info, 0, nil
+	}
+
+	return 0, 0, 0, fmt.Errorf("invalid additional information %d", info)
+}
+
+// value decodes the next item into a tree
+func (d *decoder) value() (interface{}, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, errDepth
+	}
+	defer func() { d.depth-- }()
+
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	if info == indefinite {
+		switch major {
+		case majorBytes, majorString:
+			return d.chunks(major)
+		case majorArray:
+			return d.array(-1)
+		case majorMap:
+			return d.mapping(-1)
+		case majorSimple:
+			return nil, errBreak
+		}
+		return nil, fmt.Errorf("invalid indefinite length of major type %d", major)
+	}
+
+	switch major {
+	case majorUint:
+		// unsigned values are only kept for those over an int64
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case majorNegInt:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer -1-%d overflows int64", n)
+		}
+		return -1 - int64(n), nil
+	case majorBytes:
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case majorString:
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case majorArray:
+		return d.array(int64(n))
+	case majorMap:
+		return d.mapping(int64(n))
+	case majorTag:
+		return d.tag(n)
+	}
+
+	// simple values and floats
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return float64(float16(uint16(n))), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 27:
+		return math.Float64frombits(n), nil
+	}
+
+	return nil, fmt.Errorf("unsupported simple value %d", n)
+}
+
+// chunks decodes a byte or text string of indefinite length
+func (d *decoder) chunks(major byte) (interface{}, error) {
+	var b []byte
+
+	for {
+		v, err := d.value()
+		if err == errBreak {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch c := v.(type) {
+		case []byte:
+			if major != majorBytes {
+				return nil, errors.New("invalid chunk of text string")
+			}
+			b = append(b, c...)
+		case string:
+			if major != majorString {
+				return nil, errors.New("invalid chunk of byte string")
+			}
+			b = append(b, c...)
+		default:
+			return nil, fmt.Errorf("invalid chunk %T", v)
+		}
+	}
+
+	if major == majorString {
+		return string(b), nil
+	}
+	return b, nil
+}
+
+// array decodes n items or until a break if n is negative
+func (d *decoder) array(n int64) (interface{}, error) {
+	// every item is at least a byte
+	if n > int64(len(d.buf)-d.off) {
+		return nil, errShort
+	}
+
+	var a []interface{}
+	if n > 0 {
+		a = make([]interface{}, 0, n)
+	}
+
+	for i := int64(0); n < 0 || i < n; i++ {
+		v, err := d.value()
+		if err == errBreak && n < 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+
+	if a == nil {
+		a = []interface{}{}
+	}
+
+	return a, nil
+}
+
+// mapping decodes n pairs or until a break if n is negative
+func (d *decoder) mapping(n int64) (interface{}, error) {
+	// every key and value is at least a byte
+	if n > int64(len(d.buf)-d.off)/2 {
+		return nil, errShort
+	}
+
+	m := value.Map{}
+	if n > 0 {
+		m = make(value.Map, 0, n)
+	}
+
+	for i := int64(0); n < 0 || i < n; i++ {
+		k, err := d.value()
+		if err == errBreak && n < 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, value.Pair{Key: k, Value: v})
+	}
+
+	return m, nil
+}
+
+// tag decodes a tagged item. Times are decoded and the
+// content of other tags is returned as is.
+func (d *decoder) tag(n uint64) (interface{}, error) {
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	switch n {
+	case timeTag:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid time %T", v)
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case epochTag:
+		switch t := v.(type) {
+		case int64:
+			return time.Unix(t, 0), nil
+		case uint64:
+			return nil, fmt.Errorf("time %d overflows int64", t)
+		case float64:
+			sec, frac := math.Modf(t)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+		return nil, fmt.Errorf("invalid time %T", v)
+	}
+
+	return v, nil
+}
+
+// float16 converts a half precision float
+func float16(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		// zero and subnormal numbers
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			return -f
+		}
+		return f
+	case 0x1f:
+		// infinity and not a number
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	}
+
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
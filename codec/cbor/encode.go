@@ -0,0 +1,115 @@
+package cbor
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/micro/go-micro/codec/internal/value"
+)
+
+// major types
+const (
+	majorUint = iota
+	majorNegInt
+	majorBytes
+	majorString
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+// tag of times as RFC 3339 strings
+const timeTag = 0
+
+type encoder struct {
+	buf []byte
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	e := new(encoder)
+	if err := value.Encode(e, v, "cbor"); err != nil {
+		return nil, fmt.Errorf("cbor: %v", err)
+	}
+	return e.buf, nil
+}
+
+// head writes the major type and argument of a data item
+func (e *encoder) head(major byte, n uint64) {
+	m := major << 5
+
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, m|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, m|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, m|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, m|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		e.buf = append(e.buf, m|27,
+			byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func (e *encoder) Nil() {
+	e.buf = append(e.buf, 0xf6)
+}
+
+func (e *encoder) Bool(b bool) {
+	if b {
+		e.buf = append(e.buf, 0xf5)
+	} else {
+		e.buf = append(e.buf, 0xf4)
+	}
+}
+
+func (e *encoder) Int(n int64) {
+	if n < 0 {
+		e.head(majorNegInt, uint64(-1-n))
+		return
+	}
+	e.head(majorUint, uint64(n))
+}
+
+func (e *encoder) Uint(n uint64) {
+	e.head(majorUint, n)
+}
+
+func (e *encoder) Float32(f float32) {
+	n := math.Float32bits(f)
+	e.buf = append(e.buf, 0xfa, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func (e *encoder) Float64(f float64) {
+	n := math.Float64bits(f)
+	e.buf = append(e.buf, 0xfb,
+		byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+		byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func (e *encoder) String(s string) {
+	e.head(majorString, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) Bytes(b []byte) {
+	e.head(majorBytes, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) Time(t time.Time) {
+	e.head(majorTag, timeTag)
+	e.String(t.Format(time.RFC3339Nano))
+}
+
+func (e *encoder) Array(n int) {
+	e.head(majorArray, uint64(n))
+}
+
+func (e *encoder) Map(n int) {
+	e.head(majorMap, uint64(n))
+}
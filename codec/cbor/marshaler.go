@@ -0,0 +1,15 @@
+package cbor
+
+type Marshaler struct{}
+
+func (m Marshaler) Marshal(v interface{}) ([]byte, error) {
+	return marshal(v)
+}
+
+func (m Marshaler) Unmarshal(d []byte, v interface{}) error {
+	return unmarshal(d, v)
+}
+
+func (m Marshaler) String() string {
+	return "cbor"
+}
@@ -0,0 +1,229 @@
+// Package compress provides compression of message bodies negotiated
+// through the Content-Encoding and Accept-Encoding headers
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// ContentEncodingHeader names the compressor of a message body
+	ContentEncodingHeader = "Content-Encoding"
+	// AcceptEncodingHeader lists the compressors understood by the sender
+	AcceptEncodingHeader = "Accept-Encoding"
+)
+
+var (
+	// DefaultThreshold is the body size in bytes from which bodies are compressed
+	DefaultThreshold = 1024
+
+	// DefaultMaxSize is the largest size in bytes a body is decompressed
+	// to. Larger bodies fail to decompress. Zero doesn't limit the size.
+	DefaultMaxSize = 1024 * 1024 * 64
+
+	// ErrNotFound is returned for an encoding without a compressor
+	ErrNotFound = errors.New("compressor not found")
+	// ErrTooLarge is returned for bodies decompressing to more than DefaultMaxSize
+	ErrTooLarge = errors.New("decompressed body too large")
+
+	mtx         sync.RWMutex
+	compressors = map[string]Compressor{
+		"gzip":    new(gzipCompressor),
+		"deflate": new(flateCompressor),
+	}
+)
+
+// Compressor compresses and decompresses message bodies. Compressors
+// such as snappy or zstd can be added with Register.
+type Compressor interface {
+	// Compress returns a writer compressing to w. The
+	// compressed data is complete once it's closed.
+	Compress(w io.Writer) (io.WriteCloser, error)
+	// Decompress returns a reader decompressing r
+	Decompress(r io.Reader) (io.Reader, error)
+	// String is the name used in the encoding headers
+	String() string
+}
+
+// Register adds a compressor under its name
+func Register(c Compressor) {
+	mtx.Lock()
+	compressors[c.String()] = c
+	mtx.Unlock()
+}
+
+// Get returns the compressor of an encoding
+func Get(name string) (Compressor, error) {
+	mtx.RLock()
+	c, ok := compressors[name]
+	mtx.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return c, nil
+}
+
+// Compressors returns the names of the registered compressors
+func Compressors() []string {
+	mtx.RLock()
+	names := make([]string, 0, len(compressors))
+	for name := range compressors {
+		names = append(names, name)
+	}
+	mtx.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Accept returns the value of the Accept-Encoding header
+// listing the registered compressors
+func Accept() string {
+	return strings.Join(Compressors(), ",")
+}
+
+// Accepts reports whether an Accept-Encoding header lists the encoding
+func Accepts(header, name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for _, v := range strings.Split(header, ",") {
+		// ignore quality values
+		if i := strings.Index(v, ";"); i >= 0 {
+			v = v[:i]
+		}
+		if strings.TrimSpace(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Compress compresses b with the compressor of an encoding
+func Compress(name string, b []byte) ([]byte, error) {
+	c, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	w, err := c.Compress(buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(b); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses b with the compressor of an encoding. It
+// returns ErrTooLarge if b decompresses to more than DefaultMaxSize.
+func Decompress(name string, b []byte) ([]byte, error) {
+	c, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := c.Decompress(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	max := DefaultMaxSize
+	if max <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	// read a byte more than the maximum to tell if it's exceeded
+	db, err := ioutil.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(db) > max {
+		return nil, ErrTooLarge
+	}
+
+	return db, nil
+}
+
+// Encode compresses a body of at least threshold bytes and sets the
+// Content-Encoding header. The body is returned as is if name is empty.
+func Encode(name string, threshold int, header map[string]string, b []byte) ([]byte, error) {
+	if len(name) == 0 || len(b) < threshold {
+		return b, nil
+	}
+
+	cb, err := Compress(name, b)
+	if err != nil {
+		return nil, err
+	}
+
+	header[ContentEncodingHeader] = name
+
+	return cb, nil
+}
+
+// Decode decompresses a body according to its Content-Encoding
+// header and removes the header
+func Decode(header map[string]string, b []byte) ([]byte, error) {
+	name, ok := header[ContentEncodingHeader]
+	if !ok {
+		return b, nil
+	}
+
+	if len(name) == 0 || name == "identity" {
+		delete(header, ContentEncodingHeader)
+		return b, nil
+	}
+
+	db, err := Decompress(name, b)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(header, ContentEncodingHeader)
+
+	return db, nil
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+func (gzipCompressor) String() string {
+	return "gzip"
+}
+
+type flateCompressor struct{}
+
+func (flateCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.DefaultCompression)
+}
+
+func (flateCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return flate.NewReader(r), nil
+}
+
+func (flateCompressor) String() string {
+	return "deflate"
+}
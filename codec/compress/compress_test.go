@@ -0,0 +1,114 @@
+package compress
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressors(t *testing.T) {
+	b := bytes.Repeat([]byte(`{"message": "Hello World"}`), 100)
+
+	for _, name := range Compressors() {
+		cb, err := Compress(name, b)
+		if err != nil {
+			t.Fatalf("%s: unexpected error compressing %v", name, err)
+		}
+		if len(cb) >= len(b) {
+			t.Fatalf("%s: expected compressed size below %d got %d", name, len(b), len(cb))
+		}
+
+		db, err := Decompress(name, cb)
+		if err != nil {
+			t.Fatalf("%s: unexpected error decompressing %v", name, err)
+		}
+		if !bytes.Equal(db, b) {
+			t.Fatalf("%s: expected %s got %s", name, b, db)
+		}
+	}
+
+	if _, err := Compress("snappy", b); err != ErrNotFound {
+		t.Fatalf("Expected %v got %v", ErrNotFound, err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	b := bytes.Repeat([]byte(`a`), 10)
+
+	testData := []struct {
+		name      string
+		threshold int
+		encoding  string
+	}{
+		{"gzip", 10, "gzip"},
+		{"gzip", 11, ""},
+		{"", 0, ""},
+	}
+
+	for _, d := range testData {
+		header := make(map[string]string)
+
+		eb, err := Encode(d.name, d.threshold, header, b)
+		if err != nil {
+			t.Fatalf("Unexpected error encoding %v", err)
+		}
+		if enc := header[ContentEncodingHeader]; enc != d.encoding {
+			t.Fatalf("Expected encoding %q got %q", d.encoding, enc)
+		}
+
+		db, err := Decode(header, eb)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding %v", err)
+		}
+		if !bytes.Equal(db, b) {
+			t.Fatalf("Expected %s got %s", b, db)
+		}
+		if _, ok := header[ContentEncodingHeader]; ok {
+			t.Fatal("Expected the encoding header to be removed")
+		}
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	testData := []struct {
+		header  string
+		name    string
+		accepts bool
+	}{
+		{"deflate,gzip", "gzip", true},
+		{"deflate, gzip;q=0.5", "gzip", true},
+		{"deflate", "gzip", false},
+		{"", "gzip", false},
+		{"gzip", "", false},
+		{Accept(), "deflate", true},
+	}
+
+	for _, d := range testData {
+		if ok := Accepts(d.header, d.name); ok != d.accepts {
+			t.Fatalf("Expected %q accepting %q to be %t", d.header, d.name, d.accepts)
+		}
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	b := bytes.Repeat([]byte(`a`), 100)
+
+	cb, err := Compress("gzip", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	max := DefaultMaxSize
+	defer func() {
+		DefaultMaxSize = max
+	}()
+
+	DefaultMaxSize = 100
+	if _, err := Decompress("gzip", cb); err != nil {
+		t.Fatalf("Unexpected error decompressing %v", err)
+	}
+
+	DefaultMaxSize = 99
+	if _, err := Decompress("gzip", cb); err != ErrTooLarge {
+		t.Fatalf("Expected %v got %v", ErrTooLarge, err)
+	}
+}
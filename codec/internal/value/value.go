@@ -0,0 +1,516 @@
+// Package value walks and fills go values for the binary codecs. Codecs
+// write values through a Writer and decode their format into a tree of
+// nil, bool, int64, uint64, float64, string, []byte, time.Time,
+// []interface{} and Map values which is then assigned to a go value.
+package value
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Writer writes the values of a format
+type Writer interface {
+	Nil()
+	Bool(bool)
+	Int(int64)
+	Uint(uint64)
+	Float32(float32)
+	Float64(float64)
+	String(string)
+	Bytes([]byte)
+	Time(time.Time)
+	// Array starts an array of n values
+	Array(n int)
+	// Map starts a map of n keys and values
+	Map(n int)
+}
+
+// Pair is a key and value of a map
+type Pair struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Map is a decoded map in the order of its keys
+type Map []Pair
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+type fieldsKey struct {
+	t   reflect.Type
+	tag string
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	fieldCache sync.Map
+)
+
+// fields returns the encoded fields of a struct. Names come from the
+// tag of the format, then the json tag, then the field name.
+func fields(t reflect.Type, tag string) []field {
+	key := fieldsKey{t, tag}
+	if f, ok := fieldCache.Load(key); ok {
+		return f.([]field)
+	}
+
+	var fs []field
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		// skip unexported and protobuf internal fields
+		if len(sf.PkgPath) > 0 && !sf.Anonymous {
+			continue
+		}
+		if strings.HasPrefix(sf.Name, "XXX_") {
+			continue
+		}
+
+		name, opts := sf.Tag.Get(tag), ""
+		if len(name) == 0 {
+			name = sf.Tag.Get("json")
+		}
+		if name == "-" {
+			continue
+		}
+		if i := strings.Index(name, ","); i >= 0 {
+			name, opts = name[:i], name[i:]
+		}
+
+		// flatten embedded structs without a name
+		if sf.Anonymous && len(name) == 0 {
+			if sf.Type.Kind() == reflect.Struct {
+				for _, f := range fields(sf.Type, tag) {
+					f.index = append([]int{i}, f.index...)
+					fs = append(fs, f)
+				}
+				continue
+			}
+			if len(sf.PkgPath) > 0 {
+				continue
+			}
+		}
+
+		if len(name) == 0 {
+			name = sf.Name
+		}
+
+		fs = append(fs, field{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(opts, ",omitempty"),
+		})
+	}
+
+	fieldCache.Store(key, fs)
+	return fs
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v.IsZero()
+	}
+	return false
+}
+
+// Encode writes v. Struct fields are named by the given tag.
+func Encode(w Writer, v interface{}, tag string) error {
+	return encode(w, reflect.ValueOf(v), tag)
+}
+
+func encode(w Writer, v reflect.Value, tag string) error {
+	if !v.IsValid() {
+		w.Nil()
+		return nil
+	}
+
+	if v.Type() == timeType {
+		w.Time(v.Interface().(time.Time))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		w.Bool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.Int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.Uint(v.Uint())
+	case reflect.Float32:
+		w.Float32(float32(v.Float()))
+	case reflect.Float64:
+		w.Float64(v.Float())
+	case reflect.String:
+		w.String(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			w.Nil()
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			w.Bytes(v.Bytes())
+			return nil
+		}
+		return encodeArray(w, v, tag)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			w.Bytes(b)
+			return nil
+		}
+		return encodeArray(w, v, tag)
+	case reflect.Map:
+		if v.IsNil() {
+			w.Nil()
+			return nil
+		}
+		w.Map(v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := encode(w, iter.Key(), tag); err != nil {
+				return err
+			}
+			if err := encode(w, iter.Value(), tag); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return encodeStruct(w, v, tag)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			w.Nil()
+			return nil
+		}
+		return encode(w, v.Elem(), tag)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func encodeArray(w Writer, v reflect.Value, tag string) error {
+	w.Array(v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if err := encode(w, v.Index(i), tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeStruct(w Writer, v reflect.Value, tag string) error {
+	fs := fields(v.Type(), tag)
+
+	// count the fields which are written
+	values := make([]reflect.Value, len(fs))
+	var n int
+	for i, f := range fs {
+		fv := v.FieldByIndex(f.index)
+		if f.omitEmpty && isEmpty(fv) {
+			continue
+		}
+		values[i] = fv
+		n++
+	}
+
+	w.Map(n)
+	for i, f := range fs {
+		if !values[i].IsValid() {
+			continue
+		}
+		w.String(f.name)
+		if err := encode(w, values[i], tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decode assigns a decoded tree to the value v points to.
+// Struct fields are named by the given tag.
+func Decode(src interface{}, v interface{}, tag string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("cannot decode into %T", v)
+	}
+	return decode(src, rv.Elem(), tag)
+}
+
+func decode(src interface{}, v reflect.Value, tag string) error {
+	if src == nil {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(src, v.Elem(), tag)
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(generic(src)))
+		return nil
+	}
+
+	if v.Type() == timeType {
+		t, ok := src.(time.Time)
+		if !ok {
+			return typeError(src, v)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch s := src.(type) {
+	case bool:
+		if v.Kind() != reflect.Bool {
+			return typeError(src, v)
+		}
+		v.SetBool(s)
+	case int64:
+		return decodeInt(s, v)
+	case uint64:
+		return decodeUint(s, v)
+	case float64:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(s)
+		default:
+			return typeError(src, v)
+		}
+	case string:
+		switch {
+		case v.Kind() == reflect.String:
+			v.SetString(s)
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes([]byte(s))
+		default:
+			return typeError(src, v)
+		}
+	case []byte:
+		switch {
+		case v.Kind() == reflect.String:
+			v.SetString(string(s))
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(append([]byte(nil), s...))
+		case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+			reflect.Copy(v, reflect.ValueOf(s))
+		default:
+			return typeError(src, v)
+		}
+	case []interface{}:
+		return decodeArray(s, v, tag)
+	case Map:
+		switch v.Kind() {
+		case reflect.Map:
+			return decodeMap(s, v, tag)
+		case reflect.Struct:
+			return decodeStruct(s, v, tag)
+		default:
+			return typeError(src, v)
+		}
+	default:
+		return typeError(src, v)
+	}
+
+	return nil
+}
+
+func decodeInt(n int64, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(n))
+	default:
+		return typeError(n, v)
+	}
+	return nil
+}
+
+func decodeUint(n uint64, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n > 1<<63-1 || v.OverflowInt(int64(n)) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.OverflowUint(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(n))
+	default:
+		return typeError(n, v)
+	}
+	return nil
+}
+
+func decodeArray(s []interface{}, v reflect.Value, tag string) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		sv := reflect.MakeSlice(v.Type(), len(s), len(s))
+		for i, e := range s {
+			if err := decode(e, sv.Index(i), tag); err != nil {
+				return err
+			}
+		}
+		v.Set(sv)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if i >= len(s) {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+				continue
+			}
+			if err := decode(s[i], v.Index(i), tag); err != nil {
+				return err
+			}
+		}
+	default:
+		return typeError(s, v)
+	}
+	return nil
+}
+
+func decodeMap(m Map, v reflect.Value, tag string) error {
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(m)))
+	}
+
+	for _, p := range m {
+		key := reflect.New(t.Key()).Elem()
+		if err := decode(p.Key, key, tag); err != nil {
+			return err
+		}
+		val := reflect.New(t.Elem()).Elem()
+		if err := decode(p.Value, val, tag); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, val)
+	}
+
+	return nil
+}
+
+func decodeStruct(m Map, v reflect.Value, tag string) error {
+	fs := fields(v.Type(), tag)
+
+	for _, p := range m {
+		var name string
+		switch k := p.Key.(type) {
+		case string:
+			name = k
+		case []byte:
+			name = string(k)
+		default:
+			continue
+		}
+
+		// match the name exactly then ignoring case
+		var f *field
+		for i := range fs {
+			if fs[i].name == name {
+				f = &fs[i]
+				break
+			}
+		}
+		if f == nil {
+			for i := range fs {
+				if strings.EqualFold(fs[i].name, name) {
+					f = &fs[i]
+					break
+				}
+			}
+		}
+
+		// ignore unknown fields
+		if f == nil {
+			continue
+		}
+
+		if err := decode(p.Value, v.FieldByIndex(f.index), tag); err != nil {
+			return fmt.Errorf("%s: %v", f.name, err)
+		}
+	}
+
+	return nil
+}
+
+// generic returns the value of a tree decoded into an interface{}.
+// Maps with string keys become map[string]interface{}.
+func generic(src interface{}) interface{} {
+	switch s := src.(type) {
+	case []interface{}:
+		a := make([]interface{}, len(s))
+		for i, e := range s {
+			a[i] = generic(e)
+		}
+		return a
+	case Map:
+		str := true
+		for _, p := range s {
+			if _, ok := p.Key.(string); !ok {
+				str = false
+				break
+			}
+		}
+		if str {
+			m := make(map[string]interface{}, len(s))
+			for _, p := range s {
+				m[p.Key.(string)] = generic(p.Value)
+			}
+			return m
+		}
+		m := make(map[interface{}]interface{}, len(s))
+		for _, p := range s {
+			key := generic(p.Key)
+			// keys of a go map have to be comparable
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				key = fmt.Sprint(key)
+			}
+			m[key] = generic(p.Value)
+		}
+		return m
+	}
+	return src
+}
+
+func typeError(src interface{}, v reflect.Value) error {
+	return fmt.Errorf("cannot decode %T into %s", src, v.Type())
+}
@@ -0,0 +1,244 @@
+package msgpack
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/micro/go-micro/codec/internal/value"
+)
+
+// maxDepth is how deeply arrays and maps can be nested
+const maxDepth = 1000
+
+var (
+	errShort = errors.New("unexpected end of data")
+	errDepth = errors.New("exceeded max depth")
+)
+
+type decoder struct {
+	buf []byte
+	off int
+	// nesting of the current value
+	depth int
+}
+
+func unmarshal(b []byte, v interface{}) error {
+	d := &decoder{buf: b}
+	src, err := d.value()
+	if err != nil {
+		return fmt.Errorf("msgpack: %v", err)
+	}
+	if d.off != len(d.buf) {
+		return errors.New("msgpack: invalid data after top-level value")
+	}
+	if err := value.Decode(src, v, "msgpack"); err != nil {
+		return fmt.Errorf("msgpack: %v", err)
+	}
+	return nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.off < n {
+		return nil, errShort
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+func (d *decoder) uint(n int) (uint64, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+	return bigEndian(b), nil
+}
+
+func bigEndian(b []byte) uint64 {
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u
+}
+
+// value decodes the next value into a tree
+func (d *decoder) value() (interface{}, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, errDepth
+	}
+	defer func() { d.depth-- }()
+
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.string(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.mapping(int(c & 0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		// unsigned values are only kept for those over an int64
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := 1 << (c - 0xd0)
+		u, err := d.uint(n)
+		if err != nil {
+			return nil, err
+		}
+		// sign extend
+		shift := uint(64 - 8*n)
+		return int64(u<<shift) >> shift, nil
+	case 0xca:
+		u, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(u))), nil
+	case 0xcb:
+		u, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(u), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.string(int(n))
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapping(int(n))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(int(n))
+	}
+
+	return nil, fmt.Errorf("invalid type 0x%x", c)
+}
+
+func (d *decoder) string(n int) (interface{}, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) array(n int) (interface{}, error) {
+	// every element is at least a byte
+	if n > len(d.buf)-d.off {
+		return nil, errShort
+	}
+	a := make([]interface{}, n)
+	for i := range a {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func (d *decoder) mapping(n int) (interface{}, error) {
+	// every key and value is at least a byte
+	if n > (len(d.buf)-d.off)/2 {
+		return nil, errShort
+	}
+	m := make(value.Map, n)
+	for i := range m {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		m[i] = value.Pair{Key: k, Value: v}
+	}
+	return m, nil
+}
+
+// ext decodes an extension of n bytes. Only timestamps are supported.
+func (d *decoder) ext(n int) (interface{}, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	t := int8(b[0])
+
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if t != timeExt {
+		return nil, fmt.Errorf("unsupported extension %d", t)
+	}
+
+	switch n {
+	case 4:
+		return time.Unix(int64(bigEndian(data)), 0), nil
+	case 8:
+		u := bigEndian(data)
+		return time.Unix(int64(u&(1<<34-1)), int64(u>>34)), nil
+	case 12:
+		return time.Unix(int64(bigEndian(data[4:])), int64(bigEndian(data[:4]))), nil
+	}
+
+	return nil, fmt.Errorf("invalid timestamp of %d bytes", n)
+}
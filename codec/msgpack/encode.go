@@ -0,0 +1,161 @@
+package msgpack
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/micro/go-micro/codec/internal/value"
+)
+
+// extension type of timestamps
+const timeExt = -1
+
+type encoder struct {
+	buf []byte
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	e := new(encoder)
+	if err := value.Encode(e, v, "msgpack"); err != nil {
+		return nil, fmt.Errorf("msgpack: %v", err)
+	}
+	return e.buf, nil
+}
+
+func (e *encoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *encoder) uint16(n uint16) {
+	e.buf = append(e.buf, byte(n>>8), byte(n))
+}
+
+func (e *encoder) uint32(n uint32) {
+	e.buf = append(e.buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func (e *encoder) uint64(n uint64) {
+	e.uint32(uint32(n >> 32))
+	e.uint32(uint32(n))
+}
+
+// length writes the type of a string, binary, array or map of n
+// elements. fix is the type of small ones up to max elements.
+func (e *encoder) length(n int, fix byte, max int, t8, t16, t32 byte) {
+	switch {
+	case n <= max:
+		e.byte(fix | byte(n))
+	case n <= math.MaxUint8 && t8 != 0:
+		e.byte(t8)
+		e.byte(byte(n))
+	case n <= math.MaxUint16:
+		e.byte(t16)
+		e.uint16(uint16(n))
+	default:
+		e.byte(t32)
+		e.uint32(uint32(n))
+	}
+}
+
+func (e *encoder) Nil() {
+	e.byte(0xc0)
+}
+
+func (e *encoder) Bool(b bool) {
+	if b {
+		e.byte(0xc3)
+	} else {
+		e.byte(0xc2)
+	}
+}
+
+func (e *encoder) Int(n int64) {
+	switch {
+	case n >= 0:
+		e.Uint(uint64(n))
+	case n >= -32:
+		e.byte(byte(n))
+	case n >= math.MinInt8:
+		e.byte(0xd0)
+		e.byte(byte(n))
+	case n >= math.MinInt16:
+		e.byte(0xd1)
+		e.uint16(uint16(n))
+	case n >= math.MinInt32:
+		e.byte(0xd2)
+		e.uint32(uint32(n))
+	default:
+		e.byte(0xd3)
+		e.uint64(uint64(n))
+	}
+}
+
+func (e *encoder) Uint(n uint64) {
+	switch {
+	case n <= math.MaxInt8:
+		e.byte(byte(n))
+	case n <= math.MaxUint8:
+		e.byte(0xcc)
+		e.byte(byte(n))
+	case n <= math.MaxUint16:
+		e.byte(0xcd)
+		e.uint16(uint16(n))
+	case n <= math.MaxUint32:
+		e.byte(0xce)
+		e.uint32(uint32(n))
+	default:
+		e.byte(0xcf)
+		e.uint64(n)
+	}
+}
+
+func (e *encoder) Float32(f float32) {
+	e.byte(0xca)
+	e.uint32(math.Float32bits(f))
+}
+
+func (e *encoder) Float64(f float64) {
+	e.byte(0xcb)
+	e.uint64(math.Float64bits(f))
+}
+
+func (e *encoder) String(s string) {
+	e.length(len(s), 0xa0, 31, 0xd9, 0xda, 0xdb)
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) Bytes(b []byte) {
+	e.length(len(b), 0, -1, 0xc4, 0xc5, 0xc6)
+	e.buf = append(e.buf, b...)
+}
+
+// Time writes the timestamp extension in its smallest form
+func (e *encoder) Time(t time.Time) {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		e.byte(0xd6)
+		e.byte(byte(timeExt & 0xff))
+		e.uint32(uint32(sec))
+	case sec>>34 == 0:
+		e.byte(0xd7)
+		e.byte(byte(timeExt & 0xff))
+		e.uint64(uint64(nsec)<<34 | uint64(sec))
+	default:
+		e.byte(0xc7)
+		e.byte(12)
+		e.byte(byte(timeExt & 0xff))
+		e.uint32(uint32(nsec))
+		e.uint64(uint64(sec))
+	}
+}
+
+func (e *encoder) Array(n int) {
+	e.length(n, 0x90, 15, 0, 0xdc, 0xdd)
+}
+
+func (e *encoder) Map(n int) {
+	e.length(n, 0x80, 15, 0, 0xde, 0xdf)
+}
@@ -0,0 +1,169 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testEmbed struct {
+	Embedded string
+}
+
+type testStruct struct {
+	testEmbed
+	Name    string            `json:"name"`
+	Count   int               `msgpack:"n"`
+	Neg     int8              `json:"neg"`
+	Big     uint64            `json:"big"`
+	Ratio   float64           `json:"ratio"`
+	Small   float32           `json:"small"`
+	Ok      bool              `json:"ok"`
+	Data    []byte            `json:"data"`
+	Tags    []string          `json:"tags"`
+	Meta    map[string]string `json:"meta"`
+	Ptr     *int              `json:"ptr"`
+	Time    time.Time         `json:"time"`
+	Empty   string            `json:"empty,omitempty"`
+	Skipped string            `json:"-"`
+}
+
+func TestEncode(t *testing.T) {
+	testData := []struct {
+		value interface{}
+		hex   string
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{false, "c2"},
+		{1, "01"},
+		{-1, "ff"},
+		{-33, "d0df"},
+		{128, "cc80"},
+		{256, "cd0100"},
+		{-129, "d1ff7f"},
+		{int64(math.MaxInt64), "cf7fffffffffffffff"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{1.5, "cb3ff8000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{"foo", "a3666f6f"},
+		{[]byte{1, 2}, "c4020102"},
+		{[]int{1, 2}, "920102"},
+		{map[string]int{"a": 1}, "81a16101"},
+		{time.Unix(1, 0), "d6ff00000001"},
+	}
+
+	for _, d := range testData {
+		b, err := marshal(d.value)
+		if err != nil {
+			t.Fatalf("Unexpected error encoding %v: %v", d.value, err)
+		}
+		if h := hex.EncodeToString(b); h != d.hex {
+			t.Fatalf("Expected %v to encode to %s got %s", d.value, d.hex, h)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	n := 10
+
+	in := testStruct{
+		testEmbed: testEmbed{Embedded: "embedded"},
+		Name:      "foo",
+		Count:     100000,
+		Neg:       -100,
+		Big:       math.MaxUint64,
+		Ratio:     0.25,
+		Small:     1.5,
+		Ok:        true,
+		Data:      bytes.Repeat([]byte{1}, 300),
+		Tags:      []string{"a", "b"},
+		Meta:      map[string]string{"foo": "bar"},
+		Ptr:       &n,
+		Time:      time.Unix(1<<35, 123).UTC(),
+		Skipped:   "skipped",
+	}
+
+	var m Marshaler
+
+	b, err := m.Marshal(in)
+	if err != nil {
+		t.Fatalf("Unexpected error marshaling %v", err)
+	}
+
+	var out testStruct
+	if err := m.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unexpected error unmarshaling %v", err)
+	}
+
+	// times are decoded in the local time zone
+	if !out.Time.Equal(in.Time) {
+		t.Fatalf("Expected time %v got %v", in.Time, out.Time)
+	}
+	out.Time = in.Time
+
+	in.Skipped = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("Expected %+v got %+v", in, out)
+	}
+
+	// decode into generic values
+	var v map[string]interface{}
+	if err := m.Unmarshal(b, &v); err != nil {
+		t.Fatalf("Unexpected error unmarshaling %v", err)
+	}
+	if v["name"] != "foo" || v["n"] != int64(100000) || v["Embedded"] != "embedded" {
+		t.Fatalf("Unexpected generic value %+v", v)
+	}
+	if _, ok := v["empty"]; ok {
+		t.Fatal("Expected empty field to be omitted")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testData := []string{
+		"",
+		"a3666f",
+		"92c0",
+		"c1",
+		"0101",
+		"cd01",
+	}
+
+	for _, d := range testData {
+		b, _ := hex.DecodeString(d)
+		var v interface{}
+		if err := unmarshal(b, &v); err == nil {
+			t.Fatalf("Expected error decoding %s", d)
+		}
+	}
+
+	// values which don't fit the type
+	var i int8
+	if err := unmarshal([]byte{0xcd, 0x01, 0x00}, &i); err == nil {
+		t.Fatal("Expected overflow error")
+	}
+	var s string
+	if err := unmarshal([]byte{0x01}, &s); err == nil {
+		t.Fatal("Expected type error")
+	}
+}
+
+func TestDecodeDepth(t *testing.T) {
+	nested := func(n int) []byte {
+		return append(bytes.Repeat([]byte{0x91}, n), 0xc0)
+	}
+
+	var v interface{}
+	if err := unmarshal(nested(maxDepth-1), &v); err != nil {
+		t.Fatalf("Unexpected error decoding %v", err)
+	}
+
+	// arrays nested too deeply fail rather than exhausting the stack
+	if err := unmarshal(nested(maxDepth), &v); err == nil {
+		t.Fatal("Expected error decoding nested arrays")
+	}
+}
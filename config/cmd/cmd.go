@@ -6,6 +6,7 @@ import (
 	"io"
 	"math/rand"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,10 +14,13 @@ import (
 	"github.com/micro/go-micro/client"
 	cgrpc "github.com/micro/go-micro/client/grpc"
 	cmucp "github.com/micro/go-micro/client/mucp"
+	"github.com/micro/go-micro/debug/fault"
 	"github.com/micro/go-micro/server"
 	sgrpc "github.com/micro/go-micro/server/grpc"
+	"github.com/micro/go-micro/server/limiter"
 	smucp "github.com/micro/go-micro/server/mucp"
 	"github.com/micro/go-micro/util/log"
+	mls "github.com/micro/go-micro/util/tls"
 
 	// brokers
 	"github.com/micro/go-micro/broker"
@@ -43,7 +47,10 @@ import (
 	tgrpc "github.com/micro/go-micro/transport/grpc"
 	thttp "github.com/micro/go-micro/transport/http"
 	tmem "github.com/micro/go-micro/transport/memory"
+	"github.com/micro/go-micro/transport/mux"
 	"github.com/micro/go-micro/transport/quic"
+	"github.com/micro/go-micro/transport/tcp"
+	"github.com/micro/go-micro/transport/unix"
 
 	// runtimes
 	"github.com/micro/go-micro/runtime"
@@ -97,6 +104,11 @@ var (
 			EnvVar: "MICRO_CLIENT_POOL_TTL",
 			Usage:  "Sets the client connection pool ttl. e.g 500ms, 5s, 1m. Default: 1m",
 		},
+		cli.StringFlag{
+			Name:   "client_compression",
+			EnvVar: "MICRO_CLIENT_COMPRESSION",
+			Usage:  "Sets the encoding used to compress requests. e.g gzip, deflate",
+		},
 		cli.IntFlag{
 			Name:   "register_ttl",
 			EnvVar: "MICRO_REGISTER_TTL",
@@ -139,12 +151,73 @@ var (
 			EnvVar: "MICRO_SERVER_ADVERTISE",
 			Usage:  "Used instead of the server_address when registering with discovery. 127.0.0.1:8080",
 		},
+		cli.StringFlag{
+			Name:   "server_drain_period",
+			EnvVar: "MICRO_SERVER_DRAIN_PERIOD",
+			Usage:  "Sets the time requests are served after deregistering on shutdown. e.g 500ms, 5s, 1m. Default: 0s",
+		},
+		cli.StringFlag{
+			Name:   "server_shutdown_timeout",
+			EnvVar: "MICRO_SERVER_SHUTDOWN_TIMEOUT",
+			Usage:  "Sets the time to wait for requests in flight on shutdown. e.g 500ms, 5s, 1m. Default: 10s",
+		},
+		cli.StringFlag{
+			Name:   "server_compression",
+			EnvVar: "MICRO_SERVER_COMPRESSION",
+			Usage:  "Sets the encoding used to compress responses to clients accepting it. e.g gzip, deflate",
+		},
 		cli.StringSliceFlag{
 			Name:   "server_metadata",
 			EnvVar: "MICRO_SERVER_METADATA",
 			Value:  &cli.StringSlice{},
 			Usage:  "A list of key-value pairs defining metadata. version=1.0.0",
 		},
+		cli.IntFlag{
+			Name:   "server_weight",
+			EnvVar: "MICRO_SERVER_WEIGHT",
+			Usage:  "Relative share of traffic the server receives. Default: 100",
+		},
+		cli.Float64Flag{
+			Name:   "server_rate_limit",
+			EnvVar: "MICRO_SERVER_RATE_LIMIT",
+			Usage:  "Requests per second allowed to each endpoint. Default: unlimited",
+		},
+		cli.IntFlag{
+			Name:   "server_rate_burst",
+			EnvVar: "MICRO_SERVER_RATE_BURST",
+			Usage:  "Requests allowed at once by the rate limits. Default: 1",
+		},
+		cli.StringSliceFlag{
+			Name:   "server_endpoint_rate_limit",
+			EnvVar: "MICRO_SERVER_ENDPOINT_RATE_LIMIT",
+			Value:  &cli.StringSlice{},
+			Usage:  "A list of endpoint rate limits with an optional burst. Greeter.Hello=10:20",
+		},
+		cli.Float64Flag{
+			Name:   "server_caller_rate_limit",
+			EnvVar: "MICRO_SERVER_CALLER_RATE_LIMIT",
+			Usage:  "Requests per second allowed from each calling service. Default: unlimited",
+		},
+		cli.IntFlag{
+			Name:   "server_max_concurrency",
+			EnvVar: "MICRO_SERVER_MAX_CONCURRENCY",
+			Usage:  "Maximum number of requests handled at once. Default: unlimited",
+		},
+		cli.BoolFlag{
+			Name:   "fault_injection",
+			EnvVar: "MICRO_FAULT_INJECTION",
+			Usage:  "Inject the faults set through the debug handler into requests",
+		},
+		cli.StringFlag{
+			Name:   "region",
+			EnvVar: "MICRO_REGION",
+			Usage:  "Region the service runs in. Nodes in the same region are preferred",
+		},
+		cli.StringFlag{
+			Name:   "zone",
+			EnvVar: "MICRO_ZONE",
+			Usage:  "Zone the service runs in. Nodes in the same zone are preferred",
+		},
 		cli.StringFlag{
 			Name:   "broker",
 			EnvVar: "MICRO_BROKER",
@@ -155,6 +228,11 @@ var (
 			EnvVar: "MICRO_BROKER_ADDRESS",
 			Usage:  "Comma-separated list of broker addresses",
 		},
+		cli.StringFlag{
+			Name:   "broker_compression",
+			EnvVar: "MICRO_BROKER_COMPRESSION",
+			Usage:  "Sets the encoding used to compress messages. e.g gzip, deflate",
+		},
 		cli.StringFlag{
 			Name:   "profile",
 			Usage:  "Debug profiler for cpu and memory stats",
@@ -191,6 +269,21 @@ var (
 			EnvVar: "MICRO_TRANSPORT_ADDRESS",
 			Usage:  "Comma-separated list of transport addresses",
 		},
+		cli.BoolFlag{
+			Name:   "transport_multiplex",
+			EnvVar: "MICRO_TRANSPORT_MULTIPLEX",
+			Usage:  "Multiplex requests over a connection. Requires a tcp, unix, grpc or quic transport",
+		},
+		cli.StringFlag{
+			Name:   "transport_ca_dir",
+			EnvVar: "MICRO_TRANSPORT_CA_DIR",
+			Usage:  "Directory of the local CA issuing certificates for mutual TLS between services. Created if it doesn't exist. Any service able to read it can issue certificates for every service, so it must only be readable by the user running the services (mode 0700)",
+		},
+		cli.StringFlag{
+			Name:   "transport_cert_ttl",
+			EnvVar: "MICRO_TRANSPORT_CERT_TTL",
+			Usage:  "Lifetime of the service certificates issued by the local CA e.g 1h. Certificates are reissued before they expire",
+		},
 	}
 
 	DefaultBrokers = map[string]func(...broker.Option) broker.Broker{
@@ -234,6 +327,8 @@ var (
 		"http":   thttp.NewTransport,
 		"grpc":   tgrpc.NewTransport,
 		"quic":   quic.NewTransport,
+		"tcp":    tcp.NewTransport,
+		"unix":   unix.NewTransport,
 	}
 
 	DefaultRuntimes = map[string]func(...runtime.Option) runtime.Runtime{
@@ -390,6 +485,14 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		clientOpts = append(clientOpts, client.Selector(*c.opts.Selector))
 	}
 
+	// Prefer nodes in the same region and zone
+	region, zone := ctx.String("region"), ctx.String("zone")
+	if len(region) > 0 || len(zone) > 0 {
+		if err := (*c.opts.Selector).Init(selector.Locality(region, zone)); err != nil {
+			log.Fatalf("Error configuring selector: %v", err)
+		}
+	}
+
 	// Set the transport
 	if name := ctx.String("transport"); len(name) > 0 && (*c.opts.Transport).String() != name {
 		t, ok := c.opts.Transports[name]
@@ -402,6 +505,29 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		clientOpts = append(clientOpts, client.Transport(*c.opts.Transport))
 	}
 
+	// Multiplex requests over a connection
+	if ctx.Bool("transport_multiplex") {
+		if !mux.Supported(*c.opts.Transport) {
+			return fmt.Errorf("Transport %s can't be multiplexed", (*c.opts.Transport).String())
+		}
+		*c.opts.Transport = mux.NewTransport(*c.opts.Transport)
+		serverOpts = append(serverOpts, server.Transport(*c.opts.Transport))
+		clientOpts = append(clientOpts, client.Transport(*c.opts.Transport))
+	}
+
+	// Inject faults into calls, handlers and connections
+	if ctx.Bool("fault_injection") {
+		*c.opts.Transport = fault.NewTransport(*c.opts.Transport, fault.DefaultFaults)
+		serverOpts = append(serverOpts,
+			server.Transport(*c.opts.Transport),
+			server.WrapHandler(fault.NewHandlerWrapper(fault.DefaultFaults)),
+		)
+		clientOpts = append(clientOpts,
+			client.Transport(*c.opts.Transport),
+			client.WrapCall(fault.NewCallWrapper(fault.DefaultFaults)),
+		)
+	}
+
 	// Parse the server options
 	metadata := make(map[string]string)
 	for _, d := range ctx.StringSlice("server_metadata") {
@@ -414,16 +540,77 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		metadata[key] = val
 	}
 
+	if len(region) > 0 {
+		metadata[registry.MetadataRegion] = region
+	}
+
+	if len(zone) > 0 {
+		metadata[registry.MetadataZone] = zone
+	}
+
+	if w := ctx.Int("server_weight"); w > 0 {
+		metadata[registry.MetadataWeight] = strconv.Itoa(w)
+	}
+
 	if len(metadata) > 0 {
 		serverOpts = append(serverOpts, server.Metadata(metadata))
 	}
 
+	// Parse the server limits
+	var limits []limiter.Option
+	burst := ctx.Int("server_rate_burst")
+
+	if r := ctx.Float64("server_rate_limit"); r > 0 {
+		limits = append(limits, limiter.Rate(r, burst))
+	}
+
+	for _, d := range ctx.StringSlice("server_endpoint_rate_limit") {
+		parts := strings.SplitN(d, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("failed to parse server_endpoint_rate_limit: %v", d)
+		}
+		vals := strings.SplitN(parts[1], ":", 2)
+		r, err := strconv.ParseFloat(vals[0], 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse server_endpoint_rate_limit: %v", d)
+		}
+		b := burst
+		if len(vals) > 1 {
+			if b, err = strconv.Atoi(vals[1]); err != nil {
+				return fmt.Errorf("failed to parse server_endpoint_rate_limit: %v", d)
+			}
+		}
+		limits = append(limits, limiter.EndpointRate(parts[0], r, b))
+	}
+
+	if r := ctx.Float64("server_caller_rate_limit"); r > 0 {
+		limits = append(limits, limiter.CallerRate(r, burst))
+	}
+
+	if n := ctx.Int("server_max_concurrency"); n > 0 {
+		limits = append(limits, limiter.MaxConcurrent(n))
+	}
+
+	if len(limits) > 0 {
+		l := limiter.NewLimiter(limits...)
+		serverOpts = append(serverOpts,
+			server.WrapHandler(limiter.NewHandlerWrapper(l)),
+			server.WrapSubscriber(limiter.NewSubscriberWrapper(l)),
+		)
+	}
+
 	if len(ctx.String("broker_address")) > 0 {
 		if err := (*c.opts.Broker).Init(broker.Addrs(strings.Split(ctx.String("broker_address"), ",")...)); err != nil {
 			log.Fatalf("Error configuring broker: %v", err)
 		}
 	}
 
+	if len(ctx.String("broker_compression")) > 0 {
+		if err := (*c.opts.Broker).Init(broker.Compression(ctx.String("broker_compression"))); err != nil {
+			log.Fatalf("Error configuring broker: %v", err)
+		}
+	}
+
 	if len(ctx.String("registry_address")) > 0 {
 		if err := (*c.opts.Registry).Init(registry.Addrs(strings.Split(ctx.String("registry_address"), ",")...)); err != nil {
 			log.Fatalf("Error configuring registry: %v", err)
@@ -456,6 +643,26 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		serverOpts = append(serverOpts, server.Advertise(ctx.String("server_advertise")))
 	}
 
+	if t := ctx.String("server_drain_period"); len(t) > 0 {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return fmt.Errorf("failed to parse server_drain_period: %v", t)
+		}
+		serverOpts = append(serverOpts, server.DrainPeriod(d))
+	}
+
+	if t := ctx.String("server_shutdown_timeout"); len(t) > 0 {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return fmt.Errorf("failed to parse server_shutdown_timeout: %v", t)
+		}
+		serverOpts = append(serverOpts, server.ShutdownTimeout(d))
+	}
+
+	if len(ctx.String("server_compression")) > 0 {
+		serverOpts = append(serverOpts, server.Compression(ctx.String("server_compression")))
+	}
+
 	if ttl := time.Duration(ctx.GlobalInt("register_ttl")); ttl >= 0 {
 		serverOpts = append(serverOpts, server.RegisterTTL(ttl*time.Second))
 	}
@@ -489,6 +696,10 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		clientOpts = append(clientOpts, client.PoolTTL(d))
 	}
 
+	if len(ctx.String("client_compression")) > 0 {
+		clientOpts = append(clientOpts, client.Compression(ctx.String("client_compression")))
+	}
+
 	// We have some command line opts for the server.
 	// Lets set it up
 	if len(serverOpts) > 0 {
@@ -504,6 +715,40 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		}
 	}
 
+	// Secure the transport with certificates naming the service
+	if dir := ctx.String("transport_ca_dir"); len(dir) > 0 {
+		ttl := mls.DefaultTTL
+		if t := ctx.String("transport_cert_ttl"); len(t) > 0 {
+			d, err := time.ParseDuration(t)
+			if err != nil {
+				return fmt.Errorf("failed to parse transport_cert_ttl: %v", t)
+			}
+			ttl = d
+		}
+
+		ca, err := mls.LocalCA(dir)
+		if err != nil {
+			log.Fatalf("Error loading local CA: %v", err)
+		}
+
+		config := ca.Config((*c.opts.Server).Options().Name, ttl)
+		if err := (*c.opts.Transport).Init(transport.TLSConfig(config)); err != nil {
+			log.Fatalf("Error configuring transport: %v", err)
+		}
+
+		// the grpc server and client don't use the transport
+		if (*c.opts.Server).String() == "grpc" {
+			if err := (*c.opts.Server).Init(sgrpc.AuthTLS(config)); err != nil {
+				log.Fatalf("Error configuring server: %v", err)
+			}
+		}
+		if (*c.opts.Client).String() == "grpc" {
+			if err := (*c.opts.Client).Init(cgrpc.AuthTLS(config)); err != nil {
+				log.Fatalf("Error configuring client: %v", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,300 @@
+// Package fault injects latency, errors and dropped connections into
+// requests to test how services cope with failures. Faults are injected
+// by client, server and transport wrappers and toggled at runtime. Each
+// rule is injected by the wrappers of its layer only.
+package fault
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/config"
+	"github.com/micro/go-micro/config/reader"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/util/backoff"
+	"github.com/micro/go-micro/util/log"
+)
+
+var (
+	// DefaultFaults is the set of faults used by the debug handler
+	DefaultFaults = NewFaults()
+
+	// DefaultPath is the path of the faults in the config
+	DefaultPath = []string{"micro", "faults"}
+
+	// longest time to wait before watching the config again
+	watchMaxBackoff = 30 * time.Second
+)
+
+const (
+	// LayerServer injects faults into requests handled by the server
+	LayerServer = "server"
+	// LayerClient injects faults into calls made by the client
+	LayerClient = "client"
+	// LayerTransport injects faults into the messages of connections
+	LayerTransport = "transport"
+)
+
+// Rule injects a fault into the requests it matches
+type Rule struct {
+	// Layer the fault is injected at, LayerServer if empty
+	Layer string `json:"layer,omitempty"`
+	// Service to match, all services if empty
+	Service string `json:"service,omitempty"`
+	// Endpoint to match, all endpoints if empty
+	Endpoint string `json:"endpoint,omitempty"`
+	// Percentage of matching requests the fault is injected
+	// into from 0 to 100. Zero injects it into every request.
+	Percentage float64 `json:"percentage,omitempty"`
+	// Delay before the request is handled in nanoseconds
+	Delay time.Duration `json:"delay,omitempty"`
+	// Code and Detail of the error returned
+	Code   int32  `json:"code,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	// Drop closes the connection of the request at LayerTransport.
+	// The server and client wrappers can't reach the connection so
+	// at LayerServer and LayerClient the request fails with a 500.
+	Drop bool `json:"drop,omitempty"`
+	// After fails a stream after the number of messages.
+	// Only injected at LayerTransport.
+	After int `json:"after,omitempty"`
+}
+
+// Faults is a set of rules which can be enabled and disabled at runtime
+type Faults struct {
+	sync.RWMutex
+	enabled bool
+	rules   []Rule
+
+	w    config.Watcher
+	exit chan bool
+}
+
+// faultsConfig is the format of the faults in the config
+type faultsConfig struct {
+	Enabled bool   `json:"enabled"`
+	Rules   []Rule `json:"rules"`
+}
+
+// NewFaults returns a disabled set of faults without any rules
+func NewFaults() *Faults {
+	return &Faults{}
+}
+
+func (r Rule) matches(layer, service, endpoint string) bool {
+	if l := r.Layer; l != layer && (len(l) > 0 || layer != LayerServer) {
+		return false
+	}
+	if len(r.Service) > 0 && r.Service != service {
+		return false
+	}
+	if len(r.Endpoint) > 0 && r.Endpoint != endpoint {
+		return false
+	}
+	return true
+}
+
+// inject delays the request and returns the error of the rule
+func (r Rule) inject(ctx context.Context, id string) error {
+	if r.Delay > 0 {
+		select {
+		case <-time.After(r.Delay):
+		case <-ctx.Done():
+			return errors.Timeout(id, "fault: %v", ctx.Err())
+		}
+	}
+
+	if r.Drop {
+		return errors.InternalServerError(id, "fault: connection dropped")
+	}
+
+	if r.Code > 0 {
+		return errors.New(id, r.Detail, r.Code)
+	}
+
+	return nil
+}
+
+// Enable turns fault injection on or off
+func (f *Faults) Enable(enabled bool) {
+	f.Lock()
+	f.enabled = enabled
+	f.Unlock()
+}
+
+// Enabled returns true if faults are being injected
+func (f *Faults) Enabled() bool {
+	f.RLock()
+	defer f.RUnlock()
+	return f.enabled
+}
+
+// Set replaces the rules
+func (f *Faults) Set(rules ...Rule) {
+	f.Lock()
+	f.rules = rules
+	f.Unlock()
+}
+
+// Rules returns the current rules
+func (f *Faults) Rules() []Rule {
+	f.RLock()
+	defer f.RUnlock()
+	rules := make([]Rule, len(f.rules))
+	copy(rules, f.rules)
+	return rules
+}
+
+// Match returns the first rule of the layer matching the service and
+// endpoint if faults are enabled and the request falls in its percentage
+func (f *Faults) Match(layer, service, endpoint string) (Rule, bool) {
+	f.RLock()
+	defer f.RUnlock()
+
+	if !f.enabled {
+		return Rule{}, false
+	}
+
+	for _, r := range f.rules {
+		if !r.matches(layer, service, endpoint) {
+			continue
+		}
+		if r.Percentage > 0 && rand.Float64()*100 >= r.Percentage {
+			return Rule{}, false
+		}
+		return r, true
+	}
+
+	return Rule{}, false
+}
+
+func (f *Faults) load(v reader.Value) error {
+	var c faultsConfig
+	if err := v.Scan(&c); err != nil {
+		return err
+	}
+
+	f.Lock()
+	f.enabled = c.Enabled
+	f.rules = c.Rules
+	f.Unlock()
+
+	return nil
+}
+
+// Watch loads the faults from the config at the path, or DefaultPath if
+// none is given, and keeps them updated as the config changes until Stop
+// is called. The config holds an object with the fields enabled and rules.
+func (f *Faults) Watch(c config.Config, path ...string) error {
+	if len(path) == 0 {
+		path = DefaultPath
+	}
+
+	// stop watching any previous config
+	f.Stop()
+
+	if err := f.load(c.Get(path...)); err != nil {
+		return err
+	}
+
+	w, err := c.Watch(path...)
+	if err != nil {
+		return err
+	}
+
+	exit := make(chan bool)
+
+	f.Lock()
+	f.w = w
+	f.exit = exit
+	f.Unlock()
+
+	go f.watch(c, path, w, exit)
+
+	return nil
+}
+
+// watch reloads the faults when they change. The watcher
+// is created again with backoff if it fails.
+func (f *Faults) watch(c config.Config, path []string, w config.Watcher, exit chan bool) {
+	var attempts int
+
+	for {
+		v, err := w.Next()
+		if err == nil {
+			attempts = 0
+			if err := f.load(v); err != nil {
+				log.Logf("Error loading faults: %v", err)
+			}
+			continue
+		}
+
+		w.Stop()
+
+		for {
+			select {
+			case <-exit:
+				return
+			default:
+			}
+
+			log.Logf("Error watching faults: %v", err)
+
+			attempts++
+			select {
+			case <-exit:
+				return
+			case <-time.After(watchBackoff(attempts)):
+			}
+
+			if w, err = c.Watch(path...); err == nil {
+				break
+			}
+		}
+
+		f.Lock()
+		select {
+		case <-exit:
+			// stopped while watching again
+			f.Unlock()
+			w.Stop()
+			return
+		default:
+		}
+		f.w = w
+		f.Unlock()
+
+		// pick up changes made while there was no watcher
+		if err := f.load(c.Get(path...)); err != nil {
+			log.Logf("Error loading faults: %v", err)
+		}
+	}
+}
+
+// watchBackoff returns the time to wait before watching again
+func watchBackoff(attempts int) time.Duration {
+	if attempts > 4 {
+		return watchMaxBackoff
+	}
+	if d := backoff.Do(attempts); d < watchMaxBackoff {
+		return d
+	}
+	return watchMaxBackoff
+}
+
+// Stop stops watching the config for changes
+func (f *Faults) Stop() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.w == nil {
+		return nil
+	}
+
+	close(f.exit)
+	err := f.w.Stop()
+	f.w = nil
+	return err
+}
@@ -0,0 +1,215 @@
+package fault
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/config"
+	"github.com/micro/go-micro/config/source"
+	"github.com/micro/go-micro/config/source/memory"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/registry"
+	"github.com/micro/go-micro/transport"
+	tmemory "github.com/micro/go-micro/transport/memory"
+)
+
+func TestMatch(t *testing.T) {
+	f := NewFaults()
+	f.Set(
+		Rule{Service: "foo", Endpoint: "Foo.Bar", Code: 500},
+		Rule{Service: "foo", Percentage: 50, Code: 503},
+	)
+
+	// disabled faults never match
+	if _, ok := f.Match(LayerServer, "foo", "Foo.Bar"); ok {
+		t.Fatal("Expected no match while disabled")
+	}
+
+	f.Enable(true)
+
+	if r, ok := f.Match(LayerServer, "foo", "Foo.Bar"); !ok || r.Code != 500 {
+		t.Fatalf("Expected endpoint rule got %+v", r)
+	}
+	if _, ok := f.Match(LayerServer, "bar", "Foo.Bar"); ok {
+		t.Fatal("Expected no match for other services")
+	}
+
+	// rules are only injected at their layer
+	if _, ok := f.Match(LayerClient, "foo", "Foo.Bar"); ok {
+		t.Fatal("Expected no match for other layers")
+	}
+
+	var n int
+	for i := 0; i < 1000; i++ {
+		if _, ok := f.Match(LayerServer, "foo", "Foo.Baz"); ok {
+			n++
+		}
+	}
+	if n < 400 || n > 600 {
+		t.Fatalf("Expected around 500 matches got %d", n)
+	}
+}
+
+func TestCallWrapper(t *testing.T) {
+	f := NewFaults()
+	f.Enable(true)
+	f.Set(Rule{Layer: LayerClient, Service: "foo", Delay: 10 * time.Millisecond, Code: 503, Detail: "unavailable"})
+
+	var called bool
+	fn := NewCallWrapper(f)(func(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
+		called = true
+		return nil
+	})
+
+	c := client.NewClient()
+	req := c.NewRequest("foo", "Foo.Bar", nil)
+
+	start := time.Now()
+	err := fn(context.TODO(), nil, req, nil, client.CallOptions{})
+	if e := errors.Parse(err.Error()); e.Code != 503 {
+		t.Fatalf("Expected 503 got %v", err)
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Fatal("Expected call to be delayed")
+	}
+	if called {
+		t.Fatal("Expected call not to be made")
+	}
+
+	// other services are untouched
+	if err := fn(context.TODO(), nil, c.NewRequest("bar", "Bar.Foo", nil), nil, client.CallOptions{}); err != nil || !called {
+		t.Fatalf("Expected call to be made got %v", err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	f := NewFaults()
+	f.Enable(true)
+	f.Set(Rule{Layer: LayerTransport, Service: "foo", After: 2})
+
+	tr := NewTransport(tmemory.NewTransport(), f)
+
+	l, err := tr.Listen("127.0.0.1:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock transport.Socket) {
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	})
+
+	c, err := tr.Dial("127.0.0.1:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	msg := &transport.Message{
+		Header: map[string]string{"Micro-Service": "foo"},
+		Body:   []byte(`ping`),
+	}
+
+	// the stream fails after two messages
+	for i := 0; i < 2; i++ {
+		if err := c.Send(msg); err != nil {
+			t.Fatalf("Unexpected error sending %d: %v", i, err)
+		}
+		var m transport.Message
+		if err := c.Recv(&m); err != nil {
+			t.Fatalf("Unexpected error receiving %d: %v", i, err)
+		}
+	}
+
+	if err := c.Send(msg); err != ErrDropped {
+		t.Fatalf("Expected dropped connection got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	src := memory.NewSource(memory.WithJSON([]byte(`{
+		"micro": {"faults": {"enabled": true, "rules": [{"service": "foo", "code": 500}]}}
+	}`)))
+
+	c := config.NewConfig()
+	if err := c.Load(src); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFaults()
+	if err := f.Watch(c); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Stop()
+
+	if r, ok := f.Match(LayerServer, "foo", "Foo.Bar"); !ok || r.Code != 500 {
+		t.Fatalf("Expected rule to be loaded got %+v", r)
+	}
+
+	update := src.(interface{ Update(*source.ChangeSet) })
+
+	for i := 0; i < 100; i++ {
+		// keep updating until the config is watching the source
+		update.Update(&source.ChangeSet{
+			Data:   []byte(`{"micro": {"faults": {"enabled": false}}}`),
+			Format: "json",
+		})
+		time.Sleep(10 * time.Millisecond)
+
+		if !f.Enabled() {
+			return
+		}
+	}
+
+	t.Fatal("Expected faults to be disabled")
+}
+
+func TestWatchError(t *testing.T) {
+	src := memory.NewSource(memory.WithJSON([]byte(`{
+		"micro": {"faults": {"enabled": true}}
+	}`)))
+
+	c := config.NewConfig()
+	if err := c.Load(src); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFaults()
+	if err := f.Watch(c); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Stop()
+
+	// the watcher fails
+	f.Lock()
+	w := f.w
+	f.Unlock()
+	w.Stop()
+
+	update := src.(interface{ Update(*source.ChangeSet) })
+
+	for i := 0; i < 100; i++ {
+		// keep updating until the config is watched again
+		update.Update(&source.ChangeSet{
+			Data:   []byte(`{"micro": {"faults": {"enabled": false}}}`),
+			Format: "json",
+		})
+		time.Sleep(10 * time.Millisecond)
+
+		if !f.Enabled() {
+			return
+		}
+	}
+
+	t.Fatal("Expected faults to be reloaded after the watcher failed")
+}
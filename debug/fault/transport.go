@@ -0,0 +1,116 @@
+package fault
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+)
+
+var (
+	// ErrDropped is returned when a fault drops a connection
+	ErrDropped = errors.New("fault: connection dropped")
+)
+
+type faultTransport struct {
+	transport.Transport
+	faults *Faults
+}
+
+type faultListener struct {
+	transport.Listener
+	faults *Faults
+}
+
+// faultSocket injects faults into the messages sent by clients
+// or received by servers. Streams fail once a rule's After
+// number of messages has been exceeded on the socket.
+type faultSocket struct {
+	transport.Socket
+	faults *Faults
+	server bool
+
+	sync.Mutex
+	count int
+}
+
+func (s *faultSocket) inject(m *transport.Message) error {
+	r, ok := s.faults.Match(LayerTransport, m.Header["Micro-Service"], m.Header["Micro-Endpoint"])
+	if !ok {
+		return nil
+	}
+
+	s.Lock()
+	s.count++
+	n := s.count
+	s.Unlock()
+
+	if r.Delay > 0 {
+		time.Sleep(r.Delay)
+	}
+
+	if r.Drop || (r.After > 0 && n > r.After) {
+		s.Socket.Close()
+		return ErrDropped
+	}
+
+	return nil
+}
+
+func (s *faultSocket) Send(m *transport.Message) error {
+	if !s.server {
+		if err := s.inject(m); err != nil {
+			return err
+		}
+	}
+	return s.Socket.Send(m)
+}
+
+func (s *faultSocket) Recv(m *transport.Message) error {
+	if err := s.Socket.Recv(m); err != nil {
+		return err
+	}
+	if s.server {
+		return s.inject(m)
+	}
+	return nil
+}
+
+func (s *faultSocket) ConnectionState() *tls.ConnectionState {
+	return transport.ConnectionState(s.Socket)
+}
+
+func (t *faultTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	c, err := t.Transport.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &faultSocket{Socket: c, faults: t.faults}, nil
+}
+
+func (t *faultTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
+	l, err := t.Transport.Listen(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &faultListener{Listener: l, faults: t.faults}, nil
+}
+
+func (l *faultListener) Accept(fn func(transport.Socket)) error {
+	return l.Listener.Accept(func(sock transport.Socket) {
+		fn(&faultSocket{Socket: sock, faults: l.faults, server: true})
+	})
+}
+
+// NewTransport returns a transport injecting the faults into the messages
+// of connections. Messages are matched by their Micro-Service and
+// Micro-Endpoint headers. Delays, dropped connections and streams
+// failing after a number of messages are supported.
+func NewTransport(t transport.Transport, f *Faults) transport.Transport {
+	return &faultTransport{
+		Transport: t,
+		faults:    f,
+	}
+}
@@ -0,0 +1,37 @@
+package fault
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/registry"
+	"github.com/micro/go-micro/server"
+)
+
+// NewCallWrapper returns a call wrapper injecting the faults into calls
+func NewCallWrapper(f *Faults) client.CallWrapper {
+	return func(cf client.CallFunc) client.CallFunc {
+		return func(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
+			if r, ok := f.Match(LayerClient, req.Service(), req.Endpoint()); ok {
+				if err := r.inject(ctx, "go.micro.client"); err != nil {
+					return err
+				}
+			}
+			return cf(ctx, node, req, rsp, opts)
+		}
+	}
+}
+
+// NewHandlerWrapper returns a handler wrapper injecting the faults into requests
+func NewHandlerWrapper(f *Faults) server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			if r, ok := f.Match(LayerServer, req.Service(), req.Endpoint()); ok {
+				if err := r.inject(ctx, req.Service()); err != nil {
+					return err
+				}
+			}
+			return h(ctx, req, rsp)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/store"
+)
+
+// CheckKey is the key read from a store by the store check
+var CheckKey = "micro/health"
+
+// connected is implemented by brokers reporting their connection state
+type connected interface {
+	Connected() bool
+}
+
+// Broker returns a check which fails if the broker is not connected.
+// Brokers without a Connected method are connected when they have
+// an address.
+func Broker(b broker.Broker) CheckFunc {
+	return func(ctx context.Context) error {
+		if c, ok := b.(connected); ok {
+			if !c.Connected() {
+				return errors.New("broker " + b.String() + " not connected")
+			}
+			return nil
+		}
+		if len(b.Address()) == 0 {
+			return errors.New("broker " + b.String() + " not connected")
+		}
+		return nil
+	}
+}
+
+// Store returns a check which fails if a key can't be read from the store
+func Store(s store.Store) CheckFunc {
+	return func(ctx context.Context) error {
+		if _, err := s.Read(CheckKey); err != nil && err != store.ErrNotFound {
+			return err
+		}
+		return nil
+	}
+}
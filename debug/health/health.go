@@ -0,0 +1,241 @@
+// Package health provides named readiness and liveness checks. Handlers,
+// brokers, stores and other components register checks which are run
+// by the debug handler, the grpc health service and web endpoints.
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Liveness checks fail when the service needs restarting
+	Liveness Type = "liveness"
+	// Readiness checks fail when the service can't serve requests
+	Readiness Type = "readiness"
+)
+
+const (
+	// StatusOk is the status when all the checks pass
+	StatusOk = "ok"
+	// StatusFailing is the status when any check fails
+	StatusFailing = "failing"
+)
+
+var (
+	// DefaultHealth is the set of checks used by the debug handler
+	DefaultHealth = NewHealth()
+
+	// DefaultTimeout is the time a check can run before it fails
+	DefaultTimeout = time.Second * 5
+
+	// ErrNotFound is returned when a check is not registered
+	ErrNotFound = errors.New("check not found")
+)
+
+// Type of a check
+type Type string
+
+// CheckFunc returns an error if the check fails
+type CheckFunc func(context.Context) error
+
+// Check is a named health check
+type Check struct {
+	Name string
+	Type Type
+	Func CheckFunc
+}
+
+// Result is the result of running a check
+type Result struct {
+	Name     string
+	Type     Type
+	Error    error
+	Duration time.Duration
+}
+
+// Results of running a set of checks
+type Results []*Result
+
+// Ok returns true if all the checks passed
+func (r Results) Ok() bool {
+	for _, res := range r {
+		if res.Error != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Status returns StatusOk if all the checks passed or StatusFailing
+func (r Results) Status() string {
+	if r.Ok() {
+		return StatusOk
+	}
+	return StatusFailing
+}
+
+// Health is a set of checks
+type Health struct {
+	opts Options
+
+	sync.RWMutex
+	checks map[string]*Check
+}
+
+// NewHealth returns an empty set of checks
+func NewHealth(opts ...Option) *Health {
+	options := Options{
+		Timeout: DefaultTimeout,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Health{
+		opts:   options,
+		checks: make(map[string]*Check),
+	}
+}
+
+// Register adds a check, replacing any check with the same name
+func (h *Health) Register(name string, t Type, fn CheckFunc) {
+	h.Lock()
+	h.checks[name] = &Check{
+		Name: name,
+		Type: t,
+		Func: fn,
+	}
+	h.Unlock()
+}
+
+// Deregister removes a check
+func (h *Health) Deregister(name string) {
+	h.Lock()
+	delete(h.checks, name)
+	h.Unlock()
+}
+
+// Checks returns the registered checks sorted by name
+func (h *Health) Checks() []*Check {
+	h.RLock()
+	checks := make([]*Check, 0, len(h.checks))
+	for _, c := range h.checks {
+		checks = append(checks, c)
+	}
+	h.RUnlock()
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	return checks
+}
+
+// Check runs the checks of the given types concurrently, or all the
+// checks if no type is given, and returns the results sorted by name
+func (h *Health) Check(ctx context.Context, types ...Type) Results {
+	var checks []*Check
+
+	for _, c := range h.Checks() {
+		if len(types) == 0 || hasType(types, c.Type) {
+			checks = append(checks, c)
+		}
+	}
+
+	results := make(Results, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *Check) {
+			results[i] = h.run(ctx, c)
+			wg.Done()
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// Run runs the named check
+func (h *Health) Run(ctx context.Context, name string) (*Result, error) {
+	h.RLock()
+	c, ok := h.checks[name]
+	h.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return h.run(ctx, c), nil
+}
+
+func (h *Health) run(ctx context.Context, c *Check) *Result {
+	if h.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.opts.Timeout)
+		defer cancel()
+	}
+
+	errc := make(chan error, 1)
+	started := time.Now()
+
+	go func() {
+		errc <- c.Func(ctx)
+	}()
+
+	var err error
+
+	// don't wait on checks which ignore the context
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return &Result{
+		Name:     c.Name,
+		Type:     c.Type,
+		Error:    err,
+		Duration: time.Since(started),
+	}
+}
+
+func hasType(types []Type, t Type) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Register adds a check to the default health
+func Register(name string, t Type, fn CheckFunc) {
+	DefaultHealth.Register(name, t, fn)
+}
+
+// Deregister removes a check from the default health
+func Deregister(name string) {
+	DefaultHealth.Deregister(name)
+}
+
+type healthKey struct{}
+
+// NewContext returns a context carrying the checks
+func NewContext(ctx context.Context, h *Health) context.Context {
+	return context.WithValue(ctx, healthKey{}, h)
+}
+
+// FromContext returns the checks carried by the context
+func FromContext(ctx context.Context) (*Health, bool) {
+	h, ok := ctx.Value(healthKey{}).(*Health)
+	return h, ok
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/broker/memory"
+)
+
+func TestHealth(t *testing.T) {
+	h := NewHealth()
+
+	h.Register("db", Readiness, func(ctx context.Context) error {
+		return errors.New("down")
+	})
+	h.Register("loop", Liveness, func(ctx context.Context) error {
+		return nil
+	})
+
+	results := h.Check(context.TODO())
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results got %d", len(results))
+	}
+	if results.Ok() || results.Status() != StatusFailing {
+		t.Fatalf("Expected failing status got %s", results.Status())
+	}
+	// results are sorted by name
+	if results[0].Name != "db" || results[0].Error == nil {
+		t.Fatalf("Expected db check to fail got %+v", results[0])
+	}
+
+	// checks are filtered by type
+	results = h.Check(context.TODO(), Liveness)
+	if len(results) != 1 || !results.Ok() {
+		t.Fatalf("Expected liveness checks to pass got %+v", results)
+	}
+
+	// checks are run by name
+	res, err := h.Run(context.TODO(), "db")
+	if err != nil || res.Error == nil {
+		t.Fatalf("Expected db check to fail got %v %v", res, err)
+	}
+	if _, err := h.Run(context.TODO(), "foo"); err != ErrNotFound {
+		t.Fatalf("Expected %v got %v", ErrNotFound, err)
+	}
+
+	h.Deregister("db")
+	if results := h.Check(context.TODO(), Readiness); !results.Ok() {
+		t.Fatalf("Expected no readiness checks got %+v", results)
+	}
+}
+
+func TestHealthTimeout(t *testing.T) {
+	h := NewHealth(Timeout(10 * time.Millisecond))
+
+	h.Register("slow", Readiness, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	res, err := h.Run(context.TODO(), "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error != context.DeadlineExceeded {
+		t.Fatalf("Expected %v got %v", context.DeadlineExceeded, res.Error)
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := memory.NewBroker()
+	check := Broker(b)
+
+	if err := check(context.TODO()); err == nil {
+		t.Fatal("Expected check to fail before connecting")
+	}
+
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(context.TODO()); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	b.Disconnect()
+	if err := check(context.TODO()); err == nil {
+		t.Fatal("Expected check to fail after disconnecting")
+	}
+}
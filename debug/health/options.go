@@ -0,0 +1,19 @@
+package health
+
+import (
+	"time"
+)
+
+type Options struct {
+	// Timeout of each check
+	Timeout time.Duration
+}
+
+type Option func(o *Options)
+
+// Timeout sets the time a check can run before it fails
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/micro/go-micro/debug/fault"
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/debug/log"
 	proto "github.com/micro/go-micro/debug/service/proto"
 	"github.com/micro/go-micro/server"
@@ -19,18 +21,50 @@ var (
 type Debug struct {
 	started int64
 	proto.DebugHandler
-	log log.Log
+	log    log.Log
+	faults *fault.Faults
+	health *health.Health
 }
 
 func newDebug() *Debug {
 	return &Debug{
 		started: time.Now().Unix(),
 		log:     log.DefaultLog,
+		faults:  fault.DefaultFaults,
+		health:  health.DefaultHealth,
 	}
 }
 
+// NewHandler returns a debug handler running the given health checks
+func NewHandler(h *health.Health) *Debug {
+	d := newDebug()
+	d.health = h
+	return d
+}
+
 func (d *Debug) Health(ctx context.Context, req *proto.HealthRequest, rsp *proto.HealthResponse) error {
-	rsp.Status = "ok"
+	var types []health.Type
+	if len(req.Type) > 0 {
+		types = append(types, health.Type(req.Type))
+	}
+
+	results := d.health.Check(ctx, types...)
+
+	for _, r := range results {
+		check := &proto.HealthCheck{
+			Name:     r.Name,
+			Type:     string(r.Type),
+			Status:   health.StatusOk,
+			Duration: r.Duration.Nanoseconds(),
+		}
+		if r.Error != nil {
+			check.Status = health.StatusFailing
+			check.Error = r.Error.Error()
+		}
+		rsp.Checks = append(rsp.Checks, check)
+	}
+
+	rsp.Status = results.Status()
 	return nil
 }
 
@@ -96,6 +130,44 @@ func (d *Debug) Log(ctx context.Context, stream server.Stream) error {
 	return nil
 }
 
+func (d *Debug) Faults(ctx context.Context, req *proto.FaultsRequest, rsp *proto.FaultsResponse) error {
+	if req.Update {
+		rules := make([]fault.Rule, 0, len(req.Rules))
+		for _, r := range req.Rules {
+			rules = append(rules, fault.Rule{
+				Layer:      r.Layer,
+				Service:    r.Service,
+				Endpoint:   r.Endpoint,
+				Percentage: r.Percentage,
+				Delay:      time.Duration(r.Delay),
+				Code:       r.Code,
+				Detail:     r.Detail,
+				Drop:       r.Drop,
+				After:      int(r.After),
+			})
+		}
+		d.faults.Set(rules...)
+		d.faults.Enable(req.Enabled)
+	}
+
+	rsp.Enabled = d.faults.Enabled()
+	for _, r := range d.faults.Rules() {
+		rsp.Rules = append(rsp.Rules, &proto.FaultRule{
+			Layer:      r.Layer,
+			Service:    r.Service,
+			Endpoint:   r.Endpoint,
+			Percentage: r.Percentage,
+			Delay:      int64(r.Delay),
+			Code:       r.Code,
+			Detail:     r.Detail,
+			Drop:       r.Drop,
+			After:      int64(r.After),
+		})
+	}
+
+	return nil
+}
+
 func (d *Debug) sendRecord(record log.Record, stream server.Stream) error {
 	metadata := make(map[string]string)
 	for k, v := range record.Metadata {
@@ -22,7 +22,9 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type HealthRequest struct {
 	// optional service name
-	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// optional type of checks to run; liveness or readiness
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -60,12 +62,21 @@ func (m *HealthRequest) GetService() string {
 	return ""
 }
 
+func (m *HealthRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
 type HealthResponse struct {
 	// default: ok
-	Status               string   `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// results of the checks
+	Checks               []*HealthCheck `protobuf:"bytes,2,rep,name=checks,proto3" json:"checks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
 func (m *HealthResponse) Reset()         { *m = HealthResponse{} }
@@ -100,6 +111,89 @@ func (m *HealthResponse) GetStatus() string {
 	return ""
 }
 
+func (m *HealthResponse) GetChecks() []*HealthCheck {
+	if m != nil {
+		return m.Checks
+	}
+	return nil
+}
+
+// HealthCheck is the result of a named check
+type HealthCheck struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// liveness or readiness
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// ok or failing
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	// error of a failing check
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// duration of the check in nanoseconds
+	Duration             int64    `protobuf:"varint,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HealthCheck) Reset()         { *m = HealthCheck{} }
+func (m *HealthCheck) String() string { return proto.CompactTextString(m) }
+func (*HealthCheck) ProtoMessage()    {}
+func (*HealthCheck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dea322649cde1ef2, []int{2}
+}
+
+func (m *HealthCheck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HealthCheck.Unmarshal(m, b)
+}
+func (m *HealthCheck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HealthCheck.Marshal(b, m, deterministic)
+}
+func (m *HealthCheck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HealthCheck.Merge(m, src)
+}
+func (m *HealthCheck) XXX_Size() int {
+	return xxx_messageInfo_HealthCheck.Size(m)
+}
+func (m *HealthCheck) XXX_DiscardUnknown() {
+	xxx_messageInfo_HealthCheck.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HealthCheck proto.InternalMessageInfo
+
+func (m *HealthCheck) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *HealthCheck) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *HealthCheck) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *HealthCheck) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *HealthCheck) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
 type StatsRequest struct {
 	// optional service name
 	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
@@ -112,7 +206,7 @@ func (m *StatsRequest) Reset()         { *m = StatsRequest{} }
 func (m *StatsRequest) String() string { return proto.CompactTextString(m) }
 func (*StatsRequest) ProtoMessage()    {}
 func (*StatsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dea322649cde1ef2, []int{2}
+	return fileDescriptor_dea322649cde1ef2, []int{3}
 }
 
 func (m *StatsRequest) XXX_Unmarshal(b []byte) error {
@@ -162,7 +256,7 @@ func (m *StatsResponse) Reset()         { *m = StatsResponse{} }
 func (m *StatsResponse) String() string { return proto.CompactTextString(m) }
 func (*StatsResponse) ProtoMessage()    {}
 func (*StatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dea322649cde1ef2, []int{3}
+	return fileDescriptor_dea322649cde1ef2, []int{4}
 }
 
 func (m *StatsResponse) XXX_Unmarshal(b []byte) error {
@@ -246,7 +340,7 @@ func (m *LogRequest) Reset()         { *m = LogRequest{} }
 func (m *LogRequest) String() string { return proto.CompactTextString(m) }
 func (*LogRequest) ProtoMessage()    {}
 func (*LogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dea322649cde1ef2, []int{4}
+	return fileDescriptor_dea322649cde1ef2, []int{5}
 }
 
 func (m *LogRequest) XXX_Unmarshal(b []byte) error {
@@ -312,7 +406,7 @@ func (m *Record) Reset()         { *m = Record{} }
 func (m *Record) String() string { return proto.CompactTextString(m) }
 func (*Record) ProtoMessage()    {}
 func (*Record) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dea322649cde1ef2, []int{5}
+	return fileDescriptor_dea322649cde1ef2, []int{6}
 }
 
 func (m *Record) XXX_Unmarshal(b []byte) error {
@@ -354,14 +448,238 @@ func (m *Record) GetMetadata() map[string]string {
 	return nil
 }
 
+// FaultRule injects a fault into matching requests
+type FaultRule struct {
+	// service to match, all if empty
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// endpoint to match, all if empty
+	Endpoint string `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	// percentage of matching requests, all if zero
+	Percentage float64 `protobuf:"fixed64,3,opt,name=percentage,proto3" json:"percentage,omitempty"`
+	// delay in nanoseconds
+	Delay int64 `protobuf:"varint,4,opt,name=delay,proto3" json:"delay,omitempty"`
+	// code of the error returned
+	Code int32 `protobuf:"varint,5,opt,name=code,proto3" json:"code,omitempty"`
+	// detail of the error returned
+	Detail string `protobuf:"bytes,6,opt,name=detail,proto3" json:"detail,omitempty"`
+	// close the connection
+	Drop bool `protobuf:"varint,7,opt,name=drop,proto3" json:"drop,omitempty"`
+	// fail streams after the number of messages
+	After int64 `protobuf:"varint,8,opt,name=after,proto3" json:"after,omitempty"`
+	// layer the fault is injected at, server if empty
+	Layer                string   `protobuf:"bytes,9,opt,name=layer,proto3" json:"layer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FaultRule) Reset()         { *m = FaultRule{} }
+func (m *FaultRule) String() string { return proto.CompactTextString(m) }
+func (*FaultRule) ProtoMessage()    {}
+func (*FaultRule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dea322649cde1ef2, []int{7}
+}
+
+func (m *FaultRule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FaultRule.Unmarshal(m, b)
+}
+func (m *FaultRule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FaultRule.Marshal(b, m, deterministic)
+}
+func (m *FaultRule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FaultRule.Merge(m, src)
+}
+func (m *FaultRule) XXX_Size() int {
+	return xxx_messageInfo_FaultRule.Size(m)
+}
+func (m *FaultRule) XXX_DiscardUnknown() {
+	xxx_messageInfo_FaultRule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FaultRule proto.InternalMessageInfo
+
+func (m *FaultRule) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *FaultRule) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *FaultRule) GetPercentage() float64 {
+	if m != nil {
+		return m.Percentage
+	}
+	return 0
+}
+
+func (m *FaultRule) GetDelay() int64 {
+	if m != nil {
+		return m.Delay
+	}
+	return 0
+}
+
+func (m *FaultRule) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *FaultRule) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
+func (m *FaultRule) GetDrop() bool {
+	if m != nil {
+		return m.Drop
+	}
+	return false
+}
+
+func (m *FaultRule) GetAfter() int64 {
+	if m != nil {
+		return m.After
+	}
+	return 0
+}
+
+func (m *FaultRule) GetLayer() string {
+	if m != nil {
+		return m.Layer
+	}
+	return ""
+}
+
+// FaultsRequest gets or updates the injected faults
+type FaultsRequest struct {
+	// update the faults, otherwise they're returned
+	Update bool `protobuf:"varint,1,opt,name=update,proto3" json:"update,omitempty"`
+	// enable fault injection
+	Enabled bool `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// rules replacing the current rules
+	Rules                []*FaultRule `protobuf:"bytes,3,rep,name=rules,proto3" json:"rules,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *FaultsRequest) Reset()         { *m = FaultsRequest{} }
+func (m *FaultsRequest) String() string { return proto.CompactTextString(m) }
+func (*FaultsRequest) ProtoMessage()    {}
+func (*FaultsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dea322649cde1ef2, []int{8}
+}
+
+func (m *FaultsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FaultsRequest.Unmarshal(m, b)
+}
+func (m *FaultsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FaultsRequest.Marshal(b, m, deterministic)
+}
+func (m *FaultsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FaultsRequest.Merge(m, src)
+}
+func (m *FaultsRequest) XXX_Size() int {
+	return xxx_messageInfo_FaultsRequest.Size(m)
+}
+func (m *FaultsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FaultsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FaultsRequest proto.InternalMessageInfo
+
+func (m *FaultsRequest) GetUpdate() bool {
+	if m != nil {
+		return m.Update
+	}
+	return false
+}
+
+func (m *FaultsRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+func (m *FaultsRequest) GetRules() []*FaultRule {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
+}
+
+// FaultsResponse returns the injected faults
+type FaultsResponse struct {
+	Enabled              bool         `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Rules                []*FaultRule `protobuf:"bytes,2,rep,name=rules,proto3" json:"rules,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *FaultsResponse) Reset()         { *m = FaultsResponse{} }
+func (m *FaultsResponse) String() string { return proto.CompactTextString(m) }
+func (*FaultsResponse) ProtoMessage()    {}
+func (*FaultsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dea322649cde1ef2, []int{9}
+}
+
+func (m *FaultsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FaultsResponse.Unmarshal(m, b)
+}
+func (m *FaultsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FaultsResponse.Marshal(b, m, deterministic)
+}
+func (m *FaultsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FaultsResponse.Merge(m, src)
+}
+func (m *FaultsResponse) XXX_Size() int {
+	return xxx_messageInfo_FaultsResponse.Size(m)
+}
+func (m *FaultsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FaultsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FaultsResponse proto.InternalMessageInfo
+
+func (m *FaultsResponse) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+func (m *FaultsResponse) GetRules() []*FaultRule {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*HealthRequest)(nil), "HealthRequest")
 	proto.RegisterType((*HealthResponse)(nil), "HealthResponse")
+	proto.RegisterType((*HealthCheck)(nil), "HealthCheck")
 	proto.RegisterType((*StatsRequest)(nil), "StatsRequest")
 	proto.RegisterType((*StatsResponse)(nil), "StatsResponse")
 	proto.RegisterType((*LogRequest)(nil), "LogRequest")
 	proto.RegisterType((*Record)(nil), "Record")
 	proto.RegisterMapType((map[string]string)(nil), "Record.MetadataEntry")
+	proto.RegisterType((*FaultRule)(nil), "FaultRule")
+	proto.RegisterType((*FaultsRequest)(nil), "FaultsRequest")
+	proto.RegisterType((*FaultsResponse)(nil), "FaultsResponse")
 }
 
 func init() {
@@ -369,30 +687,46 @@ func init() {
 }
 
 var fileDescriptor_dea322649cde1ef2 = []byte{
-	// 399 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x52, 0xc1, 0x6e, 0xd4, 0x30,
-	0x10, 0xdd, 0xac, 0x9b, 0xb4, 0x3b, 0x65, 0x17, 0x64, 0x15, 0x64, 0xad, 0x90, 0xa8, 0x7c, 0x0a,
-	0x42, 0x78, 0xa1, 0x5c, 0x10, 0x5c, 0x41, 0xe2, 0x50, 0x2e, 0xe6, 0x0b, 0xdc, 0x64, 0x94, 0x2e,
-	0x34, 0x71, 0xb0, 0x27, 0x95, 0xf6, 0xc4, 0xb7, 0x70, 0xe7, 0x23, 0x51, 0x6c, 0x2f, 0x6d, 0x44,
-	0xa5, 0xbd, 0xcd, 0x7b, 0xf3, 0xf4, 0x3c, 0x33, 0x7e, 0xa0, 0xda, 0x6d, 0xe5, 0xec, 0xa6, 0xb1,
-	0xaf, 0x63, 0x51, 0xe3, 0xd5, 0xd0, 0x6c, 0x3c, 0xba, 0xdb, 0x6d, 0x85, 0x9b, 0xde, 0x59, 0x4a,
-	0x9c, 0x0a, 0xb5, 0x7c, 0x09, 0xcb, 0x2f, 0x68, 0x6e, 0xe8, 0x5a, 0xe3, 0xcf, 0x01, 0x3d, 0x71,
-	0x01, 0xc7, 0x49, 0x2d, 0xb2, 0xf3, 0xac, 0x5c, 0xe8, 0x3d, 0x94, 0x25, 0xac, 0xf6, 0x52, 0xdf,
-	0xdb, 0xce, 0x23, 0x7f, 0x06, 0x85, 0x27, 0x43, 0x83, 0x4f, 0xd2, 0x84, 0x64, 0x09, 0x8f, 0xbe,
-	0x91, 0x21, 0x7f, 0xd8, 0xf3, 0x77, 0x06, 0xcb, 0x24, 0x4d, 0x9e, 0xcf, 0x61, 0x41, 0xdb, 0x16,
-	0x3d, 0x99, 0xb6, 0x0f, 0xea, 0x23, 0x7d, 0x47, 0x04, 0x27, 0x32, 0x8e, 0xb0, 0x16, 0xf3, 0xd0,
-	0xdb, 0xc3, 0x71, 0x96, 0xa1, 0x1f, 0x85, 0x82, 0x85, 0x46, 0x42, 0x23, 0xdf, 0x62, 0x6b, 0xdd,
-	0x4e, 0x1c, 0x45, 0x3e, 0xa2, 0xd1, 0x89, 0xae, 0x1d, 0x9a, 0xda, 0x8b, 0x3c, 0x3a, 0x25, 0xc8,
-	0x57, 0x30, 0x6f, 0x2a, 0x51, 0x04, 0x72, 0xde, 0x54, 0xf2, 0x3b, 0xc0, 0xa5, 0x6d, 0x0e, 0xee,
-	0x12, 0xaf, 0xe1, 0xd0, 0xb4, 0x61, 0xb4, 0x13, 0x9d, 0x10, 0x3f, 0x83, 0xbc, 0xb2, 0x43, 0x47,
-	0x61, 0x30, 0xa6, 0x23, 0x18, 0x59, 0xbf, 0xed, 0x2a, 0x0c, 0x63, 0x31, 0x1d, 0x81, 0xfc, 0x93,
-	0x41, 0xa1, 0xb1, 0xb2, 0xae, 0xfe, 0xff, 0x10, 0xec, 0xfe, 0x21, 0xce, 0x20, 0xbf, 0x35, 0x37,
-	0x03, 0x86, 0xb7, 0x16, 0x3a, 0x02, 0xfe, 0x16, 0x4e, 0x5a, 0x24, 0x53, 0x1b, 0x32, 0x82, 0x9d,
-	0xb3, 0xf2, 0xf4, 0xe2, 0xa9, 0x8a, 0x76, 0xea, 0x6b, 0xe2, 0x3f, 0x77, 0xe4, 0x76, 0xfa, 0x9f,
-	0x6c, 0xfd, 0x11, 0x96, 0x93, 0x16, 0x7f, 0x02, 0xec, 0x07, 0xee, 0xd2, 0x72, 0x63, 0xf9, 0xf0,
-	0x5b, 0x1f, 0xe6, 0xef, 0xb3, 0x8b, 0x5f, 0x90, 0x7f, 0x1a, 0xc3, 0xc4, 0x5f, 0x41, 0x11, 0xb3,
-	0xc1, 0x57, 0x6a, 0x92, 0xa7, 0xf5, 0x63, 0x35, 0x0d, 0x8d, 0x9c, 0xf1, 0x12, 0xf2, 0xf0, 0xe7,
-	0x7c, 0xa9, 0xee, 0xc7, 0x64, 0xbd, 0x52, 0x93, 0x28, 0xc8, 0x19, 0x7f, 0x01, 0xec, 0xd2, 0x36,
-	0xfc, 0x54, 0xdd, 0x7d, 0xc0, 0xfa, 0x38, 0x6d, 0x24, 0x67, 0x6f, 0xb2, 0xab, 0x22, 0xa4, 0xf8,
-	0xdd, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xea, 0xdf, 0xa5, 0x1d, 0xf7, 0x02, 0x00, 0x00,
+	// 649 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xcd, 0x6e, 0xdb, 0x38,
+	0x10, 0xb6, 0x2c, 0xdb, 0xb1, 0x27, 0xb1, 0xb3, 0x20, 0xb2, 0x0b, 0x41, 0x58, 0xec, 0x1a, 0x44,
+	0x0f, 0x06, 0x8a, 0x2a, 0x6d, 0x7a, 0x29, 0x5a, 0xf4, 0xd4, 0x1f, 0xf4, 0x90, 0xf6, 0xc0, 0x3e,
+	0x01, 0x23, 0x4d, 0x15, 0x35, 0x92, 0xa8, 0x52, 0x54, 0x00, 0x9f, 0xfa, 0x2c, 0xbd, 0xe7, 0xad,
+	0xfa, 0x22, 0x05, 0x87, 0x94, 0x63, 0xa1, 0x49, 0x73, 0x9b, 0xef, 0xe3, 0x70, 0x7e, 0xbe, 0x19,
+	0x12, 0x92, 0xaa, 0x48, 0xb5, 0x3a, 0xcd, 0xd5, 0x13, 0x67, 0x64, 0x78, 0xd1, 0xe5, 0xa7, 0x2d,
+	0xea, 0xeb, 0x22, 0xc5, 0xd3, 0x46, 0x2b, 0xe3, 0xb9, 0x84, 0x6c, 0xfe, 0x1a, 0x96, 0x1f, 0x50,
+	0x96, 0xe6, 0x52, 0xe0, 0xb7, 0x0e, 0x5b, 0xc3, 0x22, 0x38, 0xf0, 0xde, 0x51, 0xb0, 0x0e, 0x36,
+	0x0b, 0xd1, 0x43, 0xc6, 0x60, 0x62, 0xb6, 0x0d, 0x46, 0x63, 0xa2, 0xc9, 0xe6, 0x9f, 0x60, 0xd5,
+	0x5f, 0x6f, 0x1b, 0x55, 0xb7, 0xc8, 0xfe, 0x81, 0x59, 0x6b, 0xa4, 0xe9, 0x5a, 0x7f, 0xdd, 0x23,
+	0xf6, 0x08, 0x66, 0xe9, 0x25, 0xa6, 0x57, 0x6d, 0x34, 0x5e, 0x87, 0x9b, 0xc3, 0xb3, 0xa3, 0xc4,
+	0x5d, 0x7c, 0x63, 0x49, 0xe1, 0xcf, 0xf8, 0x77, 0x38, 0xdc, 0xa3, 0x6d, 0xca, 0x5a, 0x56, 0x7d,
+	0x25, 0x64, 0xdf, 0x55, 0xc6, 0x5e, 0xd2, 0x70, 0x90, 0xf4, 0x04, 0xa6, 0xa8, 0xb5, 0xd2, 0xd1,
+	0x84, 0x68, 0x07, 0x58, 0x0c, 0xf3, 0xac, 0xd3, 0xd2, 0x14, 0xaa, 0x8e, 0xa6, 0xeb, 0x60, 0x13,
+	0x8a, 0x1d, 0xe6, 0x1b, 0x38, 0xfa, 0x6c, 0xa4, 0x69, 0x1f, 0x94, 0x83, 0xff, 0x08, 0x60, 0xe9,
+	0x5d, 0x7d, 0xeb, 0xff, 0xc2, 0xc2, 0x14, 0x15, 0xb6, 0x46, 0x56, 0x0d, 0x79, 0x4f, 0xc4, 0x2d,
+	0x41, 0x91, 0x8c, 0xd4, 0x06, 0x33, 0x2a, 0x7d, 0x22, 0x7a, 0x68, 0xab, 0xef, 0x1a, 0xeb, 0x48,
+	0xd5, 0x4f, 0x84, 0x47, 0x96, 0xaf, 0xb0, 0x52, 0x7a, 0x4b, 0xe5, 0x4f, 0x84, 0x47, 0x36, 0x92,
+	0xb9, 0xd4, 0x28, 0xb3, 0x96, 0xca, 0x9f, 0x88, 0x1e, 0xb2, 0x15, 0x8c, 0xf3, 0x34, 0x9a, 0x11,
+	0x39, 0xce, 0x53, 0xfe, 0x15, 0xe0, 0x5c, 0xe5, 0x0f, 0x8f, 0x96, 0xf4, 0xd3, 0x28, 0x2b, 0x2a,
+	0x6d, 0x2e, 0x3c, 0xb2, 0xfa, 0xa5, 0xaa, 0xab, 0x0d, 0x15, 0x16, 0x0a, 0x07, 0x2c, 0xdb, 0x16,
+	0x75, 0x8a, 0x54, 0x56, 0x28, 0x1c, 0xe0, 0x37, 0x01, 0xcc, 0x04, 0xa6, 0x4a, 0x67, 0xbf, 0x0b,
+	0x11, 0xee, 0x0b, 0x71, 0x02, 0xd3, 0x6b, 0x59, 0x76, 0xfd, 0x04, 0x1d, 0x60, 0xcf, 0x60, 0x5e,
+	0xa1, 0x91, 0x99, 0x34, 0x32, 0x0a, 0x69, 0x43, 0xfe, 0x4e, 0x5c, 0xb8, 0xe4, 0xa3, 0xe7, 0xdf,
+	0xd5, 0x46, 0x6f, 0xc5, 0xce, 0x2d, 0x7e, 0x05, 0xcb, 0xc1, 0x11, 0xfb, 0x0b, 0xc2, 0x2b, 0xdc,
+	0xfa, 0xe6, 0xac, 0x79, 0x77, 0xae, 0x97, 0xe3, 0x17, 0x01, 0xff, 0x19, 0xc0, 0xe2, 0xbd, 0xec,
+	0x4a, 0x23, 0xba, 0x12, 0xff, 0x20, 0x4d, 0x0c, 0x73, 0xac, 0xb3, 0x46, 0x15, 0xb5, 0xf1, 0x41,
+	0x76, 0x98, 0xfd, 0x07, 0xd0, 0xa0, 0x4e, 0xb1, 0x36, 0x32, 0x77, 0xc3, 0x0b, 0xc4, 0x1e, 0x63,
+	0xb3, 0x67, 0x58, 0xca, 0x6d, 0x2f, 0x14, 0x01, 0xbb, 0xc0, 0xa9, 0xca, 0x90, 0x66, 0x37, 0x15,
+	0x64, 0xdb, 0x01, 0x64, 0x68, 0x64, 0x51, 0xd2, 0xf0, 0x16, 0xc2, 0x23, 0xeb, 0x9b, 0x69, 0xd5,
+	0x44, 0x07, 0x34, 0x16, 0xb2, 0x6d, 0x54, 0xf9, 0xc5, 0xa0, 0x8e, 0xe6, 0x2e, 0x2a, 0x01, 0xcb,
+	0x96, 0x72, 0x8b, 0x3a, 0x5a, 0xb8, 0x4e, 0x09, 0xf0, 0x14, 0x96, 0xd4, 0xe4, 0x6e, 0x9f, 0x69,
+	0xd7, 0x32, 0x69, 0x5c, 0x9f, 0x73, 0xe1, 0x91, 0x15, 0x00, 0x6b, 0x79, 0x51, 0xfa, 0xed, 0x9c,
+	0x8b, 0x1e, 0xb2, 0x35, 0x4c, 0x75, 0x57, 0x62, 0xeb, 0xa7, 0x02, 0xc9, 0x4e, 0x35, 0xe1, 0x0e,
+	0xf8, 0x39, 0xac, 0xfa, 0x24, 0xfe, 0x25, 0xec, 0x45, 0x0b, 0xee, 0x89, 0x36, 0xbe, 0x27, 0xda,
+	0xd9, 0x4d, 0x00, 0xd3, 0xb7, 0xf6, 0x87, 0x62, 0x8f, 0x61, 0xe6, 0x3e, 0x03, 0xb6, 0x4a, 0x06,
+	0x9f, 0x54, 0x7c, 0x9c, 0x0c, 0x7f, 0x1d, 0x3e, 0x62, 0x1b, 0x98, 0xd2, 0x6b, 0x64, 0xcb, 0x64,
+	0xff, 0x01, 0xc7, 0xab, 0x64, 0xf0, 0x48, 0xf9, 0x88, 0xfd, 0x0f, 0xe1, 0xb9, 0xca, 0xd9, 0x61,
+	0x72, 0xfb, 0x34, 0xe2, 0x03, 0xbf, 0x6b, 0x7c, 0xf4, 0x34, 0xb0, 0x79, 0x5d, 0x3f, 0x6c, 0x95,
+	0x0c, 0xd4, 0x8b, 0x8f, 0x93, 0x61, 0xa3, 0x7c, 0x74, 0x31, 0xa3, 0x7f, 0xf4, 0xf9, 0xaf, 0x01,
+	0x00, 0x35, 0x7b, 0x28, 0xf5, 0x79, 0x05, 0x00, 0x00,
 }
@@ -37,6 +37,7 @@ type DebugService interface {
 	Health(ctx context.Context, in *HealthRequest, opts ...client.CallOption) (*HealthResponse, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...client.CallOption) (*StatsResponse, error)
 	Log(ctx context.Context, in *LogRequest, opts ...client.CallOption) (Debug_LogService, error)
+	Faults(ctx context.Context, in *FaultsRequest, opts ...client.CallOption) (*FaultsResponse, error)
 }
 
 type debugService struct {
@@ -121,12 +122,23 @@ func (x *debugServiceLog) Recv() (*Record, error) {
 	return m, nil
 }
 
+func (c *debugService) Faults(ctx context.Context, in *FaultsRequest, opts ...client.CallOption) (*FaultsResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Faults", in)
+	out := new(FaultsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Debug service
 
 type DebugHandler interface {
 	Health(context.Context, *HealthRequest, *HealthResponse) error
 	Stats(context.Context, *StatsRequest, *StatsResponse) error
 	Log(context.Context, *LogRequest, Debug_LogStream) error
+	Faults(context.Context, *FaultsRequest, *FaultsResponse) error
 }
 
 func RegisterDebugHandler(s server.Server, hdlr DebugHandler, opts ...server.HandlerOption) error {
@@ -134,6 +146,7 @@ func RegisterDebugHandler(s server.Server, hdlr DebugHandler, opts ...server.Han
 		Health(ctx context.Context, in *HealthRequest, out *HealthResponse) error
 		Stats(ctx context.Context, in *StatsRequest, out *StatsResponse) error
 		Log(ctx context.Context, stream server.Stream) error
+		Faults(ctx context.Context, in *FaultsRequest, out *FaultsResponse) error
 	}
 	type Debug struct {
 		debug
@@ -188,3 +201,7 @@ func (x *debugLogStream) RecvMsg(m interface{}) error {
 func (x *debugLogStream) Send(m *Record) error {
 	return x.stream.Send(m)
 }
+
+func (h *debugHandler) Faults(ctx context.Context, in *FaultsRequest, out *FaultsResponse) error {
+	return h.DebugHandler.Faults(ctx, in, out)
+}
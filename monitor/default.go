@@ -40,7 +40,7 @@ func (m *monitor) Check(service string) error {
 	return nil
 }
 
-// check provides binary running/failed status.
+// check provides running, not ready or failed status.
 // In the event Debug.Health cannot be called on a service we reap the node.
 func (m *monitor) check(service string) (*Status, error) {
 	services, err := m.registry.GetService(service)
@@ -53,6 +53,9 @@ func (m *monitor) check(service string) (*Status, error) {
 
 	var status *Status
 	var gerr error
+	// error of a live node which is not ready
+	var rerr error
+	var checks []Check
 
 	// iterate through multiple versions of a service
 	for _, service := range services {
@@ -83,9 +86,23 @@ func (m *monitor) check(service string) (*Status, error) {
 				continue
 			}
 
+			for _, c := range rsp.Checks {
+				checks = append(checks, Check{
+					Node:   node.Id,
+					Name:   c.Name,
+					Type:   c.Type,
+					Status: c.Status,
+					Error:  c.Error,
+				})
+			}
+
 			// expecting ok response status
 			if rsp.Status != "ok" {
-				gerr = errors.New(rsp.Status)
+				if live(rsp.Checks) {
+					rerr = errors.New(rsp.Status)
+				} else {
+					gerr = errors.New(rsp.Status)
+				}
 				continue
 			}
 
@@ -99,15 +116,27 @@ func (m *monitor) check(service string) (*Status, error) {
 
 	// if we got the success case return it
 	if status != nil {
+		status.Checks = checks
 		return status, nil
 	}
 
+	// a live node is not failed
+	if rerr != nil {
+		return &Status{
+			Code:   StatusNotReady,
+			Info:   "not ready",
+			Error:  rerr.Error(),
+			Checks: checks,
+		}, nil
+	}
+
 	// if gerr is not nil return it
 	if gerr != nil {
 		return &Status{
-			Code:  StatusFailed,
-			Info:  "not running",
-			Error: gerr.Error(),
+			Code:   StatusFailed,
+			Info:   "not running",
+			Error:  gerr.Error(),
+			Checks: checks,
 		}, nil
 	}
 
@@ -118,6 +147,20 @@ func (m *monitor) check(service string) (*Status, error) {
 	}, nil
 }
 
+// live returns true if the node reported checks and none of
+// its liveness checks failed
+func live(checks []*pb.HealthCheck) bool {
+	if len(checks) == 0 {
+		return false
+	}
+	for _, c := range checks {
+		if c.Type == "liveness" && c.Status != "ok" {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *monitor) reap() {
 	services, err := m.registry.ListServices()
 	if err != nil {
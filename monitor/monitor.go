@@ -9,6 +9,8 @@ const (
 	StatusUnknown StatusCode = iota
 	StatusRunning
 	StatusFailed
+	// StatusNotReady is a live service failing its readiness checks
+	StatusNotReady
 )
 
 type StatusCode int
@@ -33,6 +35,19 @@ type Status struct {
 	Code  StatusCode
 	Info  string
 	Error string
+	// Checks reported by the nodes of the service
+	Checks []Check
+}
+
+// Check is the result of a health check run by a node
+type Check struct {
+	Node string
+	Name string
+	// liveness or readiness
+	Type string
+	// ok or failing
+	Status string
+	Error  string
 }
 
 var (
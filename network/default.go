@@ -785,13 +785,14 @@ func (n *network) processCtrlChan(listener tunnel.Listener) {
 						}
 					}
 					route := router.Route{
-						Service: event.Route.Service,
-						Address: event.Route.Address,
-						Gateway: event.Route.Gateway,
-						Network: event.Route.Network,
-						Router:  event.Route.Router,
-						Link:    event.Route.Link,
-						Metric:  event.Route.Metric,
+						Service:  event.Route.Service,
+						Address:  event.Route.Address,
+						Gateway:  event.Route.Gateway,
+						Network:  event.Route.Network,
+						Router:   event.Route.Router,
+						Link:     event.Route.Link,
+						Metric:   event.Route.Metric,
+						Metadata: event.Route.Metadata,
 					}
 					// calculate route metric and add to the advertised metric
 					// we need to make sure we do not overflow math.MaxInt64
@@ -883,13 +884,14 @@ func (n *network) advertise(advertChan <-chan *router.Advert) {
 				metric := n.getRouteMetric(event.Route.Router, event.Route.Gateway, event.Route.Link)
 				// NOTE: we override Gateway, Link and Address here
 				route := &pbRtr.Route{
-					Service: event.Route.Service,
-					Address: address,
-					Gateway: n.node.Address(),
-					Network: event.Route.Network,
-					Router:  event.Route.Router,
-					Link:    DefaultLink,
-					Metric:  metric,
+					Service:  event.Route.Service,
+					Address:  address,
+					Gateway:  n.node.Address(),
+					Network:  event.Route.Network,
+					Router:   event.Route.Router,
+					Link:     DefaultLink,
+					Metric:   metric,
+					Metadata: event.Route.Metadata,
 				}
 				e := &pbRtr.Event{
 					Type:      pbRtr.EventType(event.Type),
@@ -170,13 +170,14 @@ func (n *Network) Routes(ctx context.Context, req *pbNet.RoutesRequest, resp *pb
 	respRoutes := make([]*pbRtr.Route, 0, len(routes))
 	for _, route := range routes {
 		respRoute := &pbRtr.Route{
-			Service: route.Service,
-			Address: route.Address,
-			Gateway: route.Gateway,
-			Network: route.Network,
-			Router:  route.Router,
-			Link:    route.Link,
-			Metric:  int64(route.Metric),
+			Service:  route.Service,
+			Address:  route.Address,
+			Gateway:  route.Gateway,
+			Network:  route.Network,
+			Router:   route.Router,
+			Link:     route.Link,
+			Metric:   int64(route.Metric),
+			Metadata: route.Metadata,
 		}
 		respRoutes = append(respRoutes, respRoute)
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/micro/go-micro/client"
 	"github.com/micro/go-micro/client/selector"
 	"github.com/micro/go-micro/config/cmd"
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/server"
 	"github.com/micro/go-micro/transport"
@@ -22,6 +23,9 @@ type Options struct {
 	Registry  registry.Registry
 	Transport transport.Transport
 
+	// Health checks of the service
+	Health *health.Health
+
 	// Before and After funcs
 	BeforeStart []func() error
 	BeforeStop  []func() error
@@ -43,6 +47,7 @@ func newOptions(opts ...Option) Options {
 		Server:    server.DefaultServer,
 		Registry:  registry.DefaultRegistry,
 		Transport: transport.DefaultTransport,
+		Health:    health.NewHealth(),
 		Context:   context.Background(),
 		Signal:    true,
 	}
@@ -93,6 +98,14 @@ func HandleSignal(b bool) Option {
 	}
 }
 
+// Health sets the health checks of the service. The checks are
+// served by the debug handler and the grpc health service.
+func Health(h *health.Health) Option {
+	return func(o *Options) {
+		o.Health = h
+	}
+}
+
 func Server(s server.Server) Option {
 	return func(o *Options) {
 		o.Server = s
@@ -1,5 +1,15 @@
 package registry
 
+// Node metadata used to place requests
+const (
+	// MetadataWeight is the relative share of traffic the node receives
+	MetadataWeight = "weight"
+	// MetadataRegion is the region the node runs in
+	MetadataRegion = "region"
+	// MetadataZone is the zone the node runs in within its region
+	MetadataZone = "zone"
+)
+
 type Service struct {
 	Name      string            `json:"name"`
 	Version   string            `json:"version"`
@@ -134,13 +134,14 @@ func (r *router) manageServiceRoutes(service *registry.Service, action string) e
 	// take route action on each service node
 	for _, node := range service.Nodes {
 		route := Route{
-			Service: service.Name,
-			Address: node.Address,
-			Gateway: "",
-			Network: r.options.Network,
-			Router:  r.options.Id,
-			Link:    DefaultLink,
-			Metric:  DefaultLocalMetric,
+			Service:  service.Name,
+			Address:  node.Address,
+			Gateway:  "",
+			Network:  r.options.Network,
+			Router:   r.options.Id,
+			Link:     DefaultLink,
+			Metric:   DefaultLocalMetric,
+			Metadata: node.Metadata,
 		}
 
 		if err := r.manageRoute(route, action); err != nil {
@@ -750,10 +750,12 @@ type Route struct {
 	// the network link
 	Link string `protobuf:"bytes,6,opt,name=link,proto3" json:"link,omitempty"`
 	// the metric / score of this route
-	Metric               int64    `protobuf:"varint,7,opt,name=metric,proto3" json:"metric,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Metric int64 `protobuf:"varint,7,opt,name=metric,proto3" json:"metric,omitempty"`
+	// metadata of the node such as its weight and zone
+	Metadata             map[string]string `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *Route) Reset()         { *m = Route{} }
@@ -830,6 +832,13 @@ func (m *Route) GetMetric() int64 {
 	return 0
 }
 
+func (m *Route) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 type Status struct {
 	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
 	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
@@ -936,6 +945,7 @@ func init() {
 	proto.RegisterType((*Event)(nil), "go.micro.router.Event")
 	proto.RegisterType((*Query)(nil), "go.micro.router.Query")
 	proto.RegisterType((*Route)(nil), "go.micro.router.Route")
+	proto.RegisterMapType((map[string]string)(nil), "go.micro.router.Route.MetadataEntry")
 	proto.RegisterType((*Status)(nil), "go.micro.router.Status")
 	proto.RegisterType((*StatusResponse)(nil), "go.micro.router.StatusResponse")
 }
@@ -945,51 +955,55 @@ func init() {
 }
 
 var fileDescriptor_2dd64c6ec344e37e = []byte{
-	// 736 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0x4f, 0x4f, 0xdb, 0x4a,
-	0x10, 0xb7, 0x93, 0xd8, 0x79, 0x99, 0x17, 0x42, 0xde, 0xe8, 0x09, 0x4c, 0xde, 0x03, 0x22, 0x9f,
-	0x10, 0xa2, 0x4e, 0x15, 0xae, 0xfd, 0x43, 0xa0, 0x54, 0x95, 0xca, 0xa1, 0x35, 0xa0, 0x9e, 0x8d,
-	0xb3, 0x0a, 0x16, 0x49, 0xd6, 0xec, 0xae, 0x41, 0x39, 0xf7, 0xd3, 0xf4, 0xd2, 0x4b, 0x3f, 0x52,
-	0xbf, 0x48, 0xe5, 0xdd, 0x75, 0x08, 0x71, 0x16, 0x09, 0x4e, 0xd9, 0xf9, 0xf7, 0x9b, 0x99, 0xdd,
-	0xdf, 0x8c, 0x03, 0x87, 0xa3, 0x44, 0x5c, 0x67, 0x57, 0x41, 0x4c, 0x27, 0xbd, 0x49, 0x12, 0x33,
-	0xda, 0x1b, 0xd1, 0x57, 0xea, 0xc0, 0x68, 0x26, 0x08, 0xeb, 0xa5, 0x8c, 0x8a, 0x42, 0x08, 0xa4,
-	0x80, 0xeb, 0x23, 0x1a, 0x48, 0x9f, 0x40, 0xa9, 0xfd, 0x06, 0xd4, 0x43, 0x72, 0x9b, 0x11, 0x2e,
-	0x7c, 0x80, 0xbf, 0x42, 0xc2, 0x53, 0x3a, 0xe5, 0xc4, 0x7f, 0x07, 0xcd, 0xb3, 0x84, 0x8b, 0x42,
-	0xc6, 0x00, 0x5c, 0x19, 0xc0, 0x3d, 0xbb, 0x5b, 0xdd, 0xfb, 0xbb, 0xbf, 0x11, 0x2c, 0x01, 0x05,
-	0x61, 0xfe, 0x13, 0x6a, 0x2f, 0xff, 0x2d, 0xac, 0x9d, 0x51, 0x7a, 0x93, 0xa5, 0x1a, 0x1c, 0x0f,
-	0xc0, 0xb9, 0xcd, 0x08, 0x9b, 0x79, 0x76, 0xd7, 0x5e, 0x19, 0xff, 0x35, 0xb7, 0x86, 0xca, 0xc9,
-	0x3f, 0x82, 0x56, 0x11, 0xfe, 0xc2, 0x02, 0xde, 0x40, 0x53, 0x21, 0xbe, 0x28, 0xff, 0x7b, 0x58,
-	0xd3, 0xd1, 0x2f, 0x4c, 0xdf, 0x82, 0xe6, 0xb7, 0x48, 0xc4, 0xd7, 0xc5, 0xdd, 0xfe, 0xb0, 0xc1,
-	0x1d, 0x0c, 0xef, 0x08, 0x13, 0xd8, 0x82, 0x4a, 0x32, 0x94, 0x65, 0x34, 0xc2, 0x4a, 0x32, 0xc4,
-	0x1e, 0xd4, 0xc4, 0x2c, 0x25, 0x5e, 0xa5, 0x6b, 0xef, 0xb5, 0xfa, 0xff, 0x95, 0x80, 0x55, 0xd8,
-	0xc5, 0x2c, 0x25, 0xa1, 0x74, 0xc4, 0xff, 0xa1, 0x21, 0x92, 0x09, 0xe1, 0x22, 0x9a, 0xa4, 0x5e,
-	0xb5, 0x6b, 0xef, 0x55, 0xc3, 0x07, 0x05, 0xb6, 0xa1, 0x2a, 0xc4, 0xd8, 0xab, 0x49, 0x7d, 0x7e,
-	0xcc, 0x6b, 0x27, 0x77, 0x64, 0x2a, 0xb8, 0xe7, 0x18, 0x6a, 0x3f, 0xcd, 0xcd, 0xa1, 0xf6, 0xf2,
-	0xb7, 0xa0, 0x7e, 0x4e, 0xc7, 0x49, 0x9c, 0x94, 0x6a, 0xf5, 0xff, 0x81, 0xf5, 0x2f, 0x8c, 0xc6,
-	0x84, 0xf3, 0x39, 0x53, 0xda, 0xd0, 0x3a, 0x61, 0x24, 0x12, 0x64, 0x51, 0xf3, 0x81, 0x8c, 0xc9,
-	0x63, 0xcd, 0x65, 0x3a, 0x5c, 0xf4, 0xf9, 0x6e, 0x83, 0x23, 0xb3, 0x62, 0xa0, 0xdb, 0xb7, 0x65,
-	0xfb, 0x9d, 0xd5, 0xb5, 0x99, 0xba, 0xaf, 0x2c, 0x77, 0x7f, 0x00, 0x8e, 0x8c, 0x93, 0xf7, 0x62,
-	0x7e, 0x26, 0xe5, 0xe4, 0x5f, 0x82, 0x23, 0x9f, 0x19, 0x3d, 0xa8, 0x73, 0xc2, 0xee, 0x92, 0x98,
-	0xe8, 0x66, 0x0b, 0x31, 0xb7, 0x8c, 0x22, 0x41, 0xee, 0xa3, 0x99, 0x4c, 0xd6, 0x08, 0x0b, 0x31,
-	0xb7, 0x4c, 0x89, 0xb8, 0xa7, 0xec, 0x46, 0x26, 0x6b, 0x84, 0x85, 0xe8, 0xff, 0xb2, 0xc1, 0x91,
-	0x79, 0x9e, 0xc6, 0x8d, 0x86, 0x43, 0x46, 0x38, 0x2f, 0x70, 0xb5, 0xb8, 0x98, 0xb1, 0x6a, 0xcc,
-	0x58, 0x7b, 0x94, 0x11, 0x37, 0x34, 0x3d, 0x99, 0xe7, 0x48, 0x83, 0x96, 0x10, 0xa1, 0x36, 0x4e,
-	0xa6, 0x37, 0x9e, 0x2b, 0xb5, 0xf2, 0x9c, 0xfb, 0x4e, 0x88, 0x60, 0x49, 0xec, 0xd5, 0xe5, 0xed,
-	0x69, 0xc9, 0xef, 0x83, 0x7b, 0x2e, 0x22, 0x91, 0xf1, 0x3c, 0x2a, 0xa6, 0xc3, 0xa2, 0x64, 0x79,
-	0xc6, 0x7f, 0xc1, 0x21, 0x8c, 0x51, 0xa6, 0xab, 0x55, 0x82, 0x3f, 0x80, 0x96, 0x8a, 0x99, 0x0f,
-	0x4a, 0x0f, 0x5c, 0x2e, 0x35, 0x7a, 0xd0, 0x36, 0x4b, 0x2f, 0xa0, 0x03, 0xb4, 0xdb, 0x7e, 0x1f,
-	0xe0, 0x81, 0xe1, 0x88, 0xd0, 0x52, 0xd2, 0x60, 0x3a, 0xa5, 0xd9, 0x34, 0x26, 0x6d, 0x0b, 0xdb,
-	0xd0, 0x54, 0x3a, 0xc5, 0xa1, 0xb6, 0xbd, 0xdf, 0x83, 0xc6, 0x9c, 0x16, 0x08, 0xe0, 0x2a, 0x02,
-	0xb6, 0xad, 0xfc, 0xac, 0xa8, 0xd7, 0xb6, 0xf3, 0xb3, 0x0e, 0xa8, 0xf4, 0x7f, 0x56, 0xc1, 0x0d,
-	0xd5, 0x95, 0x7c, 0x06, 0x57, 0xad, 0x16, 0xdc, 0x29, 0x95, 0xf6, 0x68, 0x65, 0x75, 0x76, 0x8d,
-	0x76, 0x4d, 0x62, 0x0b, 0x8f, 0xc1, 0x91, 0x63, 0x8e, 0xdb, 0x25, 0xdf, 0xc5, 0xf1, 0xef, 0x18,
-	0x46, 0xce, 0xb7, 0x5e, 0xdb, 0x78, 0x0c, 0x0d, 0xd5, 0x5e, 0xc2, 0x09, 0x7a, 0x65, 0xc2, 0x6a,
-	0x88, 0x4d, 0xc3, 0x62, 0x90, 0x18, 0x47, 0x0f, 0x23, 0x6b, 0x46, 0xd8, 0x5a, 0x61, 0x99, 0x77,
-	0xf2, 0x11, 0xea, 0x7a, 0xb2, 0xd1, 0x94, 0xa9, 0xd3, 0x2d, 0x19, 0x96, 0x97, 0x81, 0x85, 0xa7,
-	0x73, 0x16, 0x99, 0x0b, 0xd9, 0x35, 0x71, 0x62, 0x0e, 0xd3, 0xff, 0x5d, 0x01, 0xe7, 0x22, 0xba,
-	0x1a, 0x13, 0x3c, 0x29, 0x9e, 0x17, 0x0d, 0xc3, 0xbc, 0x02, 0x6e, 0x69, 0x21, 0x59, 0x39, 0x88,
-	0xe2, 0xc5, 0x33, 0x40, 0x96, 0x76, 0x98, 0x04, 0x51, 0x84, 0x7a, 0x06, 0xc8, 0xd2, 0xda, 0xb3,
-	0x70, 0x00, 0xb5, 0xfc, 0xc3, 0xfa, 0xc4, 0xed, 0x94, 0xa9, 0xb4, 0xf8, 0x25, 0xf6, 0x2d, 0xfc,
-	0x54, 0x6c, 0xad, 0x6d, 0xc3, 0x47, 0x4c, 0x03, 0xed, 0x98, 0xcc, 0x05, 0xd2, 0x95, 0x2b, 0xff,
-	0x14, 0x1c, 0xfe, 0x09, 0x00, 0x00, 0xff, 0xff, 0x47, 0x98, 0xd8, 0x20, 0x4b, 0x08, 0x00, 0x00,
+	// 790 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xcd, 0x4e, 0xdb, 0x58,
+	0x14, 0xb6, 0x9d, 0xd8, 0x21, 0x67, 0x42, 0xc8, 0x5c, 0x8d, 0xc0, 0x64, 0x06, 0x88, 0xac, 0x59,
+	0x20, 0xc4, 0x38, 0xa3, 0xb0, 0x19, 0xcd, 0x5f, 0x09, 0x94, 0xaa, 0x52, 0xa9, 0xd4, 0x1a, 0x50,
+	0xd7, 0xc6, 0x3e, 0x0a, 0x56, 0x12, 0x3b, 0x5c, 0x5f, 0x07, 0x65, 0xdd, 0xa7, 0xe9, 0xa6, 0x8b,
+	0xbe, 0x52, 0x5f, 0xa4, 0xba, 0x3f, 0x0e, 0x49, 0x1c, 0x23, 0xc1, 0x2a, 0xe7, 0xf7, 0x3b, 0xe7,
+	0xdc, 0x7b, 0xee, 0x17, 0xc3, 0xc9, 0x20, 0x62, 0x77, 0xd9, 0xad, 0x1b, 0x24, 0xe3, 0xee, 0x38,
+	0x0a, 0x68, 0xd2, 0x1d, 0x24, 0x7f, 0x48, 0x81, 0x26, 0x19, 0x43, 0xda, 0x9d, 0xd0, 0x84, 0xe5,
+	0x8a, 0x2b, 0x14, 0xb2, 0x35, 0x48, 0x5c, 0x11, 0xe3, 0x4a, 0xb3, 0x53, 0x87, 0x9a, 0x87, 0xf7,
+	0x19, 0xa6, 0xcc, 0x01, 0xd8, 0xf0, 0x30, 0x9d, 0x24, 0x71, 0x8a, 0xce, 0xff, 0xd0, 0xb8, 0x8c,
+	0x52, 0x96, 0xeb, 0xc4, 0x05, 0x4b, 0x24, 0xa4, 0xb6, 0xde, 0xa9, 0x1c, 0xfe, 0xd4, 0xdb, 0x76,
+	0x57, 0x80, 0x5c, 0x8f, 0xff, 0x78, 0x2a, 0xca, 0xf9, 0x0f, 0x36, 0x2f, 0x93, 0x64, 0x98, 0x4d,
+	0x14, 0x38, 0x39, 0x06, 0xf3, 0x3e, 0x43, 0x3a, 0xb3, 0xf5, 0x8e, 0xbe, 0x36, 0xff, 0x23, 0xf7,
+	0x7a, 0x32, 0xc8, 0x39, 0x85, 0x66, 0x9e, 0xfe, 0xc2, 0x06, 0xfe, 0x85, 0x86, 0x44, 0x7c, 0x51,
+	0xfd, 0x57, 0xb0, 0xa9, 0xb2, 0x5f, 0x58, 0xbe, 0x09, 0x8d, 0x4f, 0x3e, 0x0b, 0xee, 0xf2, 0xb3,
+	0xfd, 0xa2, 0x83, 0xd5, 0x0f, 0xa7, 0x48, 0x19, 0x69, 0x82, 0x11, 0x85, 0xa2, 0x8d, 0xba, 0x67,
+	0x44, 0x21, 0xe9, 0x42, 0x95, 0xcd, 0x26, 0x68, 0x1b, 0x1d, 0xfd, 0xb0, 0xd9, 0xfb, 0xb5, 0x00,
+	0x2c, 0xd3, 0xae, 0x67, 0x13, 0xf4, 0x44, 0x20, 0xf9, 0x0d, 0xea, 0x2c, 0x1a, 0x63, 0xca, 0xfc,
+	0xf1, 0xc4, 0xae, 0x74, 0xf4, 0xc3, 0x8a, 0xf7, 0x68, 0x20, 0x2d, 0xa8, 0x30, 0x36, 0xb2, 0xab,
+	0xc2, 0xce, 0x45, 0xde, 0x3b, 0x4e, 0x31, 0x66, 0xa9, 0x6d, 0x96, 0xf4, 0x7e, 0xc1, 0xdd, 0x9e,
+	0x8a, 0x72, 0x76, 0xa1, 0x76, 0x95, 0x8c, 0xa2, 0x20, 0x2a, 0xf4, 0xea, 0xfc, 0x0c, 0x5b, 0x1f,
+	0x68, 0x12, 0x60, 0x9a, 0xce, 0x37, 0xa5, 0x05, 0xcd, 0x73, 0x8a, 0x3e, 0xc3, 0x45, 0xcb, 0x6b,
+	0x1c, 0xe1, 0xb2, 0xe5, 0x66, 0x12, 0x2e, 0xc6, 0x7c, 0xd6, 0xc1, 0x14, 0x55, 0x89, 0xab, 0xc6,
+	0xd7, 0xc5, 0xf8, 0xed, 0xf5, 0xbd, 0x95, 0x4d, 0x6f, 0xac, 0x4e, 0x7f, 0x0c, 0xa6, 0xc8, 0x13,
+	0xe7, 0x52, 0x7e, 0x4d, 0x32, 0xc8, 0xb9, 0x01, 0x53, 0x5c, 0x33, 0xb1, 0xa1, 0x96, 0x22, 0x9d,
+	0x46, 0x01, 0xaa, 0x61, 0x73, 0x95, 0x7b, 0x06, 0x3e, 0xc3, 0x07, 0x7f, 0x26, 0x8a, 0xd5, 0xbd,
+	0x5c, 0xe5, 0x9e, 0x18, 0xd9, 0x43, 0x42, 0x87, 0xa2, 0x58, 0xdd, 0xcb, 0x55, 0xe7, 0x9b, 0x01,
+	0xa6, 0xa8, 0xf3, 0x34, 0xae, 0x1f, 0x86, 0x14, 0xd3, 0x34, 0xc7, 0x55, 0xea, 0x62, 0xc5, 0x4a,
+	0x69, 0xc5, 0xea, 0x52, 0x45, 0xb2, 0xad, 0xd6, 0x93, 0xda, 0xa6, 0x70, 0x28, 0x8d, 0x10, 0xa8,
+	0x8e, 0xa2, 0x78, 0x68, 0x5b, 0xc2, 0x2a, 0x64, 0x1e, 0x3b, 0x46, 0x46, 0xa3, 0xc0, 0xae, 0x89,
+	0xd3, 0x53, 0x1a, 0x39, 0x85, 0x8d, 0x31, 0x32, 0x3f, 0xf4, 0x99, 0x6f, 0x6f, 0x88, 0x45, 0xf9,
+	0x7d, 0xfd, 0xe9, 0xb9, 0xef, 0x55, 0xd8, 0x45, 0xcc, 0xe8, 0xcc, 0x9b, 0x67, 0xb5, 0xff, 0x81,
+	0xcd, 0x25, 0x17, 0xdf, 0xc5, 0x21, 0xce, 0xd4, 0xe8, 0x5c, 0x24, 0xbf, 0x80, 0x39, 0xf5, 0x47,
+	0x19, 0xaa, 0xa1, 0xa5, 0xf2, 0xb7, 0xf1, 0x97, 0xee, 0xf4, 0xc0, 0xba, 0x62, 0x3e, 0xcb, 0x52,
+	0xde, 0x74, 0x90, 0x84, 0xf9, 0x89, 0x09, 0x99, 0xe7, 0x21, 0xa5, 0x09, 0xcd, 0xf3, 0x84, 0xe2,
+	0xf4, 0xa1, 0x29, 0x73, 0xe6, 0xef, 0xb4, 0x0b, 0x56, 0x2a, 0x2c, 0xea, 0x9d, 0xef, 0x14, 0x46,
+	0x50, 0x09, 0x2a, 0xec, 0xa8, 0x07, 0xf0, 0xf8, 0xc0, 0x08, 0x81, 0xa6, 0xd4, 0xfa, 0x71, 0x9c,
+	0x64, 0x71, 0x80, 0x2d, 0x8d, 0xb4, 0xa0, 0x21, 0x6d, 0x72, 0x85, 0x5b, 0xfa, 0x51, 0x17, 0xea,
+	0xf3, 0xad, 0x24, 0x00, 0x96, 0xdc, 0xff, 0x96, 0xc6, 0x65, 0xb9, 0xf9, 0x2d, 0x9d, 0xcb, 0x2a,
+	0xc1, 0xe8, 0x7d, 0xad, 0x80, 0xe5, 0xc9, 0x1b, 0x79, 0x07, 0x96, 0x64, 0x36, 0xb2, 0x5f, 0x68,
+	0x6d, 0x89, 0x31, 0xdb, 0x07, 0xa5, 0x7e, 0xf5, 0x86, 0x34, 0x72, 0x06, 0xa6, 0x60, 0x19, 0xb2,
+	0x57, 0x88, 0x5d, 0x64, 0x9f, 0x76, 0xc9, 0x8b, 0x77, 0xb4, 0x3f, 0x75, 0x72, 0x06, 0x75, 0x39,
+	0x5e, 0x94, 0x22, 0xb1, 0x8b, 0x37, 0xae, 0x20, 0x76, 0x4a, 0x78, 0x49, 0x60, 0x9c, 0x3e, 0x32,
+	0x46, 0x39, 0xc2, 0xee, 0x1a, 0xcf, 0x7c, 0x92, 0x37, 0x50, 0x53, 0xc4, 0x42, 0xca, 0x2a, 0xb5,
+	0x3b, 0x05, 0xc7, 0x2a, 0x17, 0x69, 0xe4, 0x62, 0xbe, 0x45, 0xe5, 0x8d, 0x1c, 0x94, 0xed, 0xc4,
+	0x1c, 0xa6, 0xf7, 0xdd, 0x00, 0xf3, 0xda, 0xbf, 0x1d, 0x21, 0x39, 0xcf, 0xaf, 0x97, 0x94, 0x70,
+	0xc9, 0x1a, 0xb8, 0x15, 0x3e, 0xd4, 0xc8, 0x79, 0xbe, 0x17, 0xcf, 0x00, 0x59, 0xa1, 0x50, 0x01,
+	0x22, 0x17, 0xea, 0x19, 0x20, 0x2b, 0xac, 0xab, 0x91, 0x3e, 0x54, 0xf9, 0xff, 0xfa, 0x13, 0xa7,
+	0x53, 0x5c, 0xa5, 0xc5, 0x0f, 0x01, 0x47, 0x23, 0x6f, 0x73, 0xd2, 0xdc, 0x2b, 0xf9, 0x0f, 0x55,
+	0x40, 0xfb, 0x65, 0xee, 0x1c, 0xe9, 0xd6, 0x12, 0xdf, 0x24, 0x27, 0x3f, 0x06, 0x00, 0x1a, 0x8d,
+	0xb8, 0x4f, 0xca, 0x08, 0x00, 0x00,
 }
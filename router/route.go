@@ -27,6 +27,8 @@ type Route struct {
 	Link string
 	// Metric is the route cost metric
 	Metric int64
+	// Metadata of the node such as its weight and zone
+	Metadata map[string]string
 }
 
 // Hash returns route hash sum.
@@ -6,6 +6,8 @@ import (
 )
 
 type serverKey struct{}
+type peerKey struct{}
+type idempotencyKey struct{}
 
 func wait(ctx context.Context) *sync.WaitGroup {
 	if ctx == nil {
@@ -26,3 +28,31 @@ func FromContext(ctx context.Context) (Server, bool) {
 func NewContext(ctx context.Context, s Server) context.Context {
 	return context.WithValue(ctx, serverKey{}, s)
 }
+
+// PeerService returns the name of the service which made a request as
+// authenticated by the certificate of its connection. Certificates are
+// issued to services by the CA of the util/tls package.
+func PeerService(ctx context.Context) (string, bool) {
+	s, ok := ctx.Value(peerKey{}).(string)
+	return s, ok
+}
+
+// NewPeerContext returns a context carrying the name
+// of the authenticated service which made a request
+func NewPeerContext(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, peerKey{}, service)
+}
+
+// IdempotencyKey returns the idempotency key sent with a request.
+// Servers remove the key from the metadata so it isn't passed on
+// to nested calls.
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	k, ok := ctx.Value(idempotencyKey{}).(string)
+	return k, ok
+}
+
+// NewIdempotencyContext returns a context carrying
+// the idempotency key sent with a request
+func NewIdempotencyContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// Counter tracks the requests and messages in flight. Unlike a
+// sync.WaitGroup it can be added to while being waited on.
+type Counter struct {
+	sync.Mutex
+	n int
+	// closed when n drops to zero
+	zero chan struct{}
+}
+
+// Add adds i, which may be negative, to the counter
+func (c *Counter) Add(i int) {
+	c.Lock()
+	c.n += i
+	if c.n == 0 && c.zero != nil {
+		close(c.zero)
+		c.zero = nil
+	}
+	c.Unlock()
+}
+
+// Wait blocks until the counter and the wait group, if not nil, are done
+// or the deadline passes. A zero deadline waits for as long as it takes.
+// It returns false if the deadline passed.
+func (c *Counter) Wait(wg *sync.WaitGroup, deadline time.Time) bool {
+	done := make(chan bool)
+
+	go func() {
+		for {
+			c.Lock()
+			if c.n == 0 {
+				c.Unlock()
+				break
+			}
+			if c.zero == nil {
+				c.zero = make(chan struct{})
+			}
+			zero := c.zero
+			c.Unlock()
+			<-zero
+		}
+
+		if wg != nil {
+			wg.Wait()
+		}
+
+		close(done)
+	}()
+
+	if deadline.IsZero() {
+		<-done
+		return true
+	}
+
+	t := time.NewTimer(time.Until(deadline))
+	defer t.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-t.C:
+		return false
+	}
+}
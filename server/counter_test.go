@@ -0,0 +1,39 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCounter(t *testing.T) {
+	c := new(Counter)
+	c.Add(1)
+
+	// the deadline passes while a request is in flight
+	start := time.Now()
+	if c.Wait(nil, start.Add(10*time.Millisecond)) {
+		t.Fatal("Expected wait to time out")
+	}
+
+	// a passed deadline doesn't wait again
+	if c.Wait(nil, start.Add(10*time.Millisecond)) {
+		t.Fatal("Expected wait to time out")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Expected wait to return by the deadline took %v", d)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.Add(-1)
+		wg.Done()
+	}()
+
+	if !c.Wait(&wg, time.Time{}) {
+		t.Fatal("Expected wait to complete")
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/micro/go-micro/broker"
 	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/errors"
 	meta "github.com/micro/go-micro/metadata"
 	"github.com/micro/go-micro/registry"
@@ -24,11 +25,13 @@ import (
 	mgrpc "github.com/micro/go-micro/util/grpc"
 	"github.com/micro/go-micro/util/log"
 	mnet "github.com/micro/go-micro/util/net"
+	mls "github.com/micro/go-micro/util/tls"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
@@ -58,6 +61,12 @@ type grpcServer struct {
 	started bool
 	// used for first registration
 	registered bool
+	// requests and messages in flight
+	inflight *server.Counter
+	// set while shutting down to reject new requests
+	draining bool
+	// closed when draining starts
+	drain chan bool
 }
 
 func init() {
@@ -78,6 +87,8 @@ func newGRPCServer(opts ...server.Option) server.Server {
 		handlers:    make(map[string]server.Handler),
 		subscribers: make(map[*subscriber][]broker.Subscriber),
 		exit:        make(chan chan error),
+		inflight:    new(server.Counter),
+		drain:       make(chan bool),
 		wg:          wait(options.Context),
 	}
 
@@ -127,6 +138,12 @@ func (g *grpcServer) configure(opts ...server.Option) {
 	}
 
 	g.srv = grpc.NewServer(gopts...)
+
+	// serve the standard health protocol
+	grpc_health_v1.RegisterHealthServer(g.srv, &healthServer{
+		g: g,
+		h: g.getHealth(),
+	})
 }
 
 func (g *grpcServer) getMaxMsgSize() int {
@@ -140,6 +157,17 @@ func (g *grpcServer) getMaxMsgSize() int {
 	return s
 }
 
+func (g *grpcServer) getHealth() *health.Health {
+	if g.opts.Context == nil {
+		return health.DefaultHealth
+	}
+	h, ok := health.FromContext(g.opts.Context)
+	if !ok {
+		return health.DefaultHealth
+	}
+	return h
+}
+
 func (g *grpcServer) getCredentials() credentials.TransportCredentials {
 	if g.opts.Context != nil {
 		if v := g.opts.Context.Value(tlsAuth{}); v != nil {
@@ -176,6 +204,19 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
 		defer g.wg.Done()
 	}
 
+	// track the request until it's done
+	g.inflight.Add(1)
+	defer g.inflight.Add(-1)
+
+	g.RLock()
+	draining := g.draining
+	g.RUnlock()
+
+	// reject new requests while shutting down
+	if draining {
+		return status.New(codes.Unavailable, server.ErrShuttingDown.Error()).Err()
+	}
+
 	fullMethod, ok := grpc.MethodFromServerStream(stream)
 	if !ok {
 		return status.Errorf(codes.Internal, "method does not exist in context")
@@ -207,19 +248,36 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
 		ct = ctype
 	}
 
+	// idempotency key of the request
+	key := md[strings.ToLower(server.IdempotencyKeyHeader)]
+
 	delete(md, "x-content-type")
 	delete(md, "timeout")
+	delete(md, strings.ToLower(server.IdempotencyKeyHeader))
 
 	// create new context
 	ctx := meta.NewContext(stream.Context(), md)
 
+	// set the idempotency key of the request
+	if len(key) > 0 {
+		ctx = server.NewIdempotencyContext(ctx, key)
+	}
+
 	// get peer from context
 	if p, ok := peer.FromContext(stream.Context()); ok {
 		md["Remote"] = p.Addr.String()
 		ctx = peer.NewContext(ctx, p)
+
+		// set the service authenticated by the connection
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			if name := mls.PeerService(&info.State); len(name) > 0 {
+				ctx = server.NewPeerContext(ctx, name)
+			}
+		}
 	}
 
-	// set the timeout if we have it
+	// set the timeout if we have it, the client sends
+	// the time remaining so nested calls inherit it
 	if len(to) > 0 {
 		if n, err := strconv.ParseUint(to, 10, 64); err == nil {
 			var cancel context.CancelFunc
@@ -228,6 +286,11 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
 		}
 	}
 
+	// reject the request if its deadline has already passed
+	if ctx.Err() != nil {
+		return errors.Timeout("go.micro.server", "deadline exceeded")
+	}
+
 	// process via router
 	if g.opts.Router != nil {
 		cc, err := g.newGRPCCodec(ct)
@@ -642,6 +705,12 @@ func (g *grpcServer) Register() error {
 			opts = append(opts, broker.DisableAutoAck())
 		}
 
+		opts = append(opts,
+			broker.MaxAttempts(sb.Options().MaxAttempts),
+			broker.Backoff(sb.Options().Backoff),
+			broker.DeadLetter(sb.Options().DeadLetter),
+		)
+
 		sub, err := config.Broker.Subscribe(sb.Topic(), handler, opts...)
 		if err != nil {
 			return err
@@ -793,13 +862,42 @@ func (g *grpcServer) Start() error {
 			log.Log("Server deregister error: ", err)
 		}
 
-		// wait for waitgroup
-		if g.wg != nil {
-			g.wg.Wait()
+		// keep serving while clients learn the server is going away
+		if config.DrainPeriod > 0 {
+			time.Sleep(config.DrainPeriod)
+		}
+
+		// stop accepting new requests
+		g.Lock()
+		g.draining = true
+		close(g.drain)
+		g.Unlock()
+
+		// stop the grpc server, forcing it after the shutdown timeout
+		stopped := make(chan bool)
+		go func() {
+			g.srv.GracefulStop()
+			close(stopped)
+		}()
+
+		var timeout <-chan time.Time
+		var deadline time.Time
+		if config.ShutdownTimeout > 0 {
+			deadline = time.Now().Add(config.ShutdownTimeout)
+			timeout = time.After(config.ShutdownTimeout)
+		}
+
+		select {
+		case <-stopped:
+		case <-timeout:
+			log.Log("Server shutdown timeout, closing connections")
+			g.srv.Stop()
 		}
 
-		// stop the grpc server
-		g.srv.GracefulStop()
+		// wait for subscribers and the waitgroup in the time left
+		if !g.inflight.Wait(g.wg, deadline) {
+			log.Log("Server shutdown timeout, requests still in flight")
+		}
 
 		// close transport
 		ch <- nil
@@ -832,6 +930,8 @@ func (g *grpcServer) Stop() error {
 	case err = <-ch:
 		g.Lock()
 		g.started = false
+		g.draining = false
+		g.drain = make(chan bool)
 		g.Unlock()
 	}
 
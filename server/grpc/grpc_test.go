@@ -2,11 +2,18 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/micro/go-micro/codec/compress"
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/registry/memory"
 	"github.com/micro/go-micro/server"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/micro/go-micro/server/grpc/proto"
 )
@@ -64,3 +71,141 @@ func TestGRPCServer(t *testing.T) {
 		}
 	}
 }
+
+func TestGRPCServerCompression(t *testing.T) {
+	r := memory.NewRegistry()
+	s := NewServer(
+		server.Name("foo"),
+		server.Registry(r),
+	)
+
+	pb.RegisterTestHandler(s, &testServer{})
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	defer func() {
+		if err := s.Stop(); err != nil {
+			t.Fatalf("failed to stop: %v", err)
+		}
+	}()
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+
+	// deflate is registered with grpc by the server
+	for _, name := range compress.Compressors() {
+		rsp := pb.Response{}
+
+		if err := cc.Invoke(context.Background(), "/test.Test/Call", &pb.Request{Name: "John"}, &rsp, grpc.UseCompressor(name)); err != nil {
+			t.Fatalf("error calling server with %s: %v", name, err)
+		}
+
+		if rsp.Msg != "Hello John" {
+			t.Fatalf("Got unexpected response %v", rsp.Msg)
+		}
+	}
+}
+
+func TestGRPCServerShutdown(t *testing.T) {
+	started := make(chan bool)
+
+	r := memory.NewRegistry()
+	s := NewServer(
+		server.Name("foo"),
+		server.Registry(r),
+		server.ShutdownTimeout(time.Second),
+		server.WrapHandler(func(fn server.HandlerFunc) server.HandlerFunc {
+			return func(ctx context.Context, req server.Request, rsp interface{}) error {
+				close(started)
+				time.Sleep(100 * time.Millisecond)
+				return fn(ctx, req, rsp)
+			}
+		}),
+	)
+
+	pb.RegisterTestHandler(s, &testServer{})
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		rsp := pb.Response{}
+		errs <- cc.Invoke(context.Background(), "/test.Test/Call", &pb.Request{Name: "John"}, &rsp)
+	}()
+
+	// stop while the request is in flight
+	<-started
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+
+	// the request in flight completes
+	if err := <-errs; err != nil {
+		t.Fatalf("error calling server: %v", err)
+	}
+}
+
+func TestGRPCServerHealth(t *testing.T) {
+	h := health.NewHealth()
+	h.Register("db", health.Readiness, func(ctx context.Context) error {
+		return errors.New("down")
+	})
+	h.Register("loop", health.Liveness, func(ctx context.Context) error {
+		return nil
+	})
+
+	s := NewServer(
+		server.Name("foo"),
+		server.Registry(memory.NewRegistry()),
+		Health(h),
+	)
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer cc.Close()
+
+	hc := grpc_health_v1.NewHealthClient(cc)
+
+	testCases := map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+		"":          grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		"foo":       grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		"readiness": grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		"liveness":  grpc_health_v1.HealthCheckResponse_SERVING,
+		"loop":      grpc_health_v1.HealthCheckResponse_SERVING,
+	}
+
+	for service, st := range testCases {
+		rsp, err := hc.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("error checking %q: %v", service, err)
+		}
+		if rsp.Status != st {
+			t.Fatalf("Expected %q to be %v got %v", service, st, rsp.Status)
+		}
+	}
+
+	_, err = hc.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "bar"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected not found got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/debug/health"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// HealthWatchInterval is how often the checks are run for a watch
+	HealthWatchInterval = time.Second * 5
+)
+
+// healthServer implements the standard grpc health protocol. The empty
+// service or the server name runs all the checks, "liveness" and
+// "readiness" run the checks of that type and any other name runs the
+// check registered with that name.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	g *grpcServer
+	h *health.Health
+}
+
+func (s *healthServer) status(ctx context.Context, service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	s.g.RLock()
+	name := s.g.opts.Name
+	draining := s.g.draining
+	s.g.RUnlock()
+
+	var results health.Results
+
+	switch service {
+	case "", name:
+		results = s.h.Check(ctx)
+	case string(health.Liveness):
+		results = s.h.Check(ctx, health.Liveness)
+	case string(health.Readiness):
+		results = s.h.Check(ctx, health.Readiness)
+	default:
+		res, err := s.h.Run(ctx, service)
+		if err == health.ErrNotFound {
+			return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, status.Errorf(codes.NotFound, "unknown service %s", service)
+		}
+		results = health.Results{res}
+	}
+
+	// the server is not ready once it's shutting down
+	if draining && service != string(health.Liveness) {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING, nil
+	}
+
+	if !results.Ok() {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING, nil
+	}
+
+	return grpc_health_v1.HealthCheckResponse_SERVING, nil
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	st, err := s.status(ctx, req.Service)
+	if err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: st}, nil
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	s.g.RLock()
+	drain := s.g.drain
+	s.g.RUnlock()
+
+	t := time.NewTicker(HealthWatchInterval)
+	defer t.Stop()
+
+	last := grpc_health_v1.HealthCheckResponse_ServingStatus(-1)
+
+	for {
+		st, err := s.status(stream.Context(), req.Service)
+		if err != nil {
+			st = grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+
+		// only send changes of the status
+		if st != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-t.C:
+		case <-drain:
+			// end the watch to let graceful stop finish
+			if last == grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+				return nil
+			}
+			return stream.Send(&grpc_health_v1.HealthCheckResponse{
+				Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+			})
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/micro/go-micro/broker"
 	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/codec/compress"
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/server"
 	"github.com/micro/go-micro/transport"
@@ -53,6 +55,17 @@ func Options(opts ...grpc.ServerOption) server.Option {
 	}
 }
 
+// Health sets the checks served by the grpc health service. Defaults
+// to the checks of the service or health.DefaultHealth
+func Health(h *health.Health) server.Option {
+	return func(o *server.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = health.NewContext(o.Context, h)
+	}
+}
+
 //
 // MaxMsgSize set the maximum message in bytes the server can receive and
 // send.  Default maximum message size is 4 MB.
@@ -68,8 +81,12 @@ func MaxMsgSize(s int) server.Option {
 
 func newOptions(opt ...server.Option) server.Options {
 	opts := server.Options{
-		Codecs:   make(map[string]codec.NewCodec),
-		Metadata: map[string]string{},
+		Codecs:          make(map[string]codec.NewCodec),
+		Metadata:        map[string]string{},
+		DrainPeriod:     server.DefaultDrainPeriod,
+		ShutdownTimeout: server.DefaultShutdownTimeout,
+
+		CompressionThreshold: compress.DefaultThreshold,
 	}
 
 	for _, o := range opt {
@@ -170,6 +170,10 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 	return func(p broker.Event) error {
 		var err error
 
+		// track the message until it's handled
+		g.inflight.Add(1)
+		defer g.inflight.Add(-1)
+
 		defer func() {
 			if r := recover(); r != nil {
 				log.Log("panic recovered: ", r)
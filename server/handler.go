@@ -1,6 +1,10 @@
 package server
 
-import "context"
+import (
+	"context"
+
+	"github.com/micro/go-micro/broker"
+)
 
 type HandlerOption func(*HandlerOptions)
 
@@ -17,7 +21,14 @@ type SubscriberOptions struct {
 	AutoAck  bool
 	Queue    string
 	Internal bool
-	Context  context.Context
+	// MaxAttempts is the number of times the handler is
+	// called for a message. Zero means no retries.
+	MaxAttempts int
+	// Backoff returns the time to wait before retrying
+	Backoff broker.BackoffFunc
+	// DeadLetter is the topic failed messages are published to
+	DeadLetter string
+	Context    context.Context
 }
 
 // EndpointMetadata is a Handler option that allows metadata to be added to
@@ -72,6 +83,29 @@ func SubscriberQueue(n string) SubscriberOption {
 	}
 }
 
+// SubscriberMaxAttempts sets the number of times the handler is called
+// for a message before it is dead lettered or dropped
+func SubscriberMaxAttempts(n int) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.MaxAttempts = n
+	}
+}
+
+// SubscriberBackoff sets the time to wait between delivery attempts
+func SubscriberBackoff(fn broker.BackoffFunc) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.Backoff = fn
+	}
+}
+
+// SubscriberDeadLetter sets the topic messages are published
+// to once every delivery attempt has failed
+func SubscriberDeadLetter(topic string) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.DeadLetter = topic
+	}
+}
+
 // SubscriberContext set context options to allow broker SubscriberOption passed
 func SubscriberContext(ctx context.Context) SubscriberOption {
 	return func(o *SubscriberOptions) {
@@ -0,0 +1,286 @@
+// Package idempotency deduplicates requests carrying an idempotency key
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/micro/go-micro/codec"
+	jsonc "github.com/micro/go-micro/codec/json"
+	protoc "github.com/micro/go-micro/codec/proto"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+	"github.com/micro/go-micro/store"
+	"github.com/micro/go-micro/store/memory"
+	"github.com/micro/go-micro/util/log"
+)
+
+var (
+	// KeyHeader is the metadata header holding the idempotency key
+	KeyHeader = server.IdempotencyKeyHeader
+
+	// CallerHeader is the metadata header naming the service which
+	// made a request when it isn't authenticated by its certificate
+	CallerHeader = "Micro-From-Service"
+	// DefaultTTL is how long a response is replayed for
+	DefaultTTL = time.Hour * 24
+	// DefaultTimeout is how long a request is claimed for
+	DefaultTimeout = time.Minute
+
+	// how often duplicates check whether the request has been handled
+	pollInterval = time.Millisecond * 50
+)
+
+// result is the recorded outcome of a request
+type result struct {
+	// Pending is set while the request is being handled
+	Pending bool `json:"pending,omitempty"`
+	// hash of the request body
+	Hash  string `json:"hash,omitempty"`
+	Error string `json:"error,omitempty"`
+	Body  []byte `json:"body,omitempty"`
+}
+
+type dedup struct {
+	opts Options
+}
+
+func newDedup(opts ...Option) *dedup {
+	options := Options{
+		TTL:     DefaultTTL,
+		Timeout: DefaultTimeout,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Store == nil {
+		options.Store = memory.NewStore()
+	}
+
+	return &dedup{
+		opts: options,
+	}
+}
+
+func marshaler(v interface{}) codec.Marshaler {
+	if _, ok := v.(proto.Message); ok {
+		return protoc.Marshaler{}
+	}
+	return jsonc.Marshaler{}
+}
+
+// claim records the request with the key as pending in the store so
+// duplicates handled by any instance of the service wait for it rather
+// than being handled too. It returns nil once the key is claimed or the
+// result recorded for it, which may be pending for a different request.
+func (d *dedup) claim(ctx context.Context, key, hash string) (*result, error) {
+	b, err := json.Marshal(result{Pending: true, Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err := d.create(&store.Record{
+			Key:   key,
+			Value: b,
+			// the claim of a service which died is given up
+			Expiry: d.opts.Timeout,
+		})
+		if err == nil {
+			return nil, nil
+		} else if err != store.ErrConflict {
+			return nil, err
+		}
+
+		res, err := d.read(key)
+		if err == store.ErrNotFound {
+			// the claim was released or expired
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if !res.Pending || res.Hash != hash {
+			return res, nil
+		}
+
+		// wait for the request to be handled
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// create writes the record unless the key exists. Stores which don't
+// support batches are read and written separately.
+func (d *dedup) create(rec *store.Record) error {
+	err := d.opts.Store.Batch(store.WriteOp(rec, store.IfAbsent()))
+	if err != store.ErrNotSupported {
+		return err
+	}
+
+	if _, err := d.opts.Store.Read(rec.Key); err == nil {
+		return store.ErrConflict
+	} else if err != store.ErrNotFound {
+		return err
+	}
+
+	return d.opts.Store.Write(rec)
+}
+
+// read returns the result recorded for the key
+func (d *dedup) read(key string) (*result, error) {
+	recs, err := d.opts.Store.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(result)
+	if err := json.Unmarshal(recs[0].Value, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// hash returns the hash of the request body
+func hash(req interface{}) (string, error) {
+	if req == nil {
+		return "", nil
+	}
+	b, err := marshaler(req).Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// replay reads the recorded result into the response. Requests
+// with a different body than the recorded one are rejected.
+func (d *dedup) replay(service, id, hash string, res *result, rsp interface{}) error {
+	if res.Hash != hash {
+		return errors.New(service, "idempotency key "+id+" was used for a different request", 422)
+	}
+
+	if len(res.Error) > 0 {
+		return errors.Parse(res.Error)
+	}
+
+	return marshaler(rsp).Unmarshal(res.Body, rsp)
+}
+
+// record stores the result of the key
+func (d *dedup) record(key, hash string, rsp interface{}, herr error) error {
+	res := result{Hash: hash}
+
+	if herr != nil {
+		res.Error = herr.Error()
+	} else {
+		b, err := marshaler(rsp).Marshal(rsp)
+		if err != nil {
+			return err
+		}
+		res.Body = b
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	return d.opts.Store.Write(&store.Record{
+		Key:    key,
+		Value:  b,
+		Expiry: d.opts.TTL,
+	})
+}
+
+// NewHandlerWrapper returns a handler wrapper which records the response of
+// requests with an idempotency key and replays it for duplicate requests
+// rather than executing the handler again. A request is claimed in the
+// store before it's handled so duplicates sent to any instance sharing
+// the store wait for its response. Keys are scoped to the calling service.
+// Errors with a 5xx, 408 or 429 code aren't recorded so failed requests
+// can be retried. Reusing a key for a request with a different body
+// returns a 422 error.
+func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
+	d := newDedup(opts...)
+
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			// servers move the key from the metadata to the context
+			id, ok := server.IdempotencyKey(ctx)
+			if !ok {
+				id, ok = metadata.Get(ctx, KeyHeader)
+			}
+			if !ok {
+				// grpc lower cases the headers
+				id, ok = metadata.Get(ctx, strings.ToLower(KeyHeader))
+			}
+			if !ok || len(id) == 0 || req.Stream() {
+				return h(ctx, req, rsp)
+			}
+
+			// keys are chosen by the client so they're scoped to the caller
+			caller, ok := server.PeerService(ctx)
+			if !ok {
+				caller, _ = metadata.Get(ctx, CallerHeader)
+			}
+			key := req.Service() + "/" + req.Endpoint() + "/" + caller + "/" + id
+
+			sum, err := hash(req.Body())
+			if err != nil {
+				return errors.InternalServerError(req.Service(), "error hashing request for %s: %v", id, err)
+			}
+
+			res, err := d.claim(ctx, key, sum)
+			if err == context.Canceled || err == context.DeadlineExceeded {
+				return errors.Timeout(req.Service(), "timed out waiting for request %s", id)
+			} else if err != nil {
+				return errors.InternalServerError(req.Service(), "error claiming request %s: %v", id, err)
+			}
+
+			if res != nil {
+				return d.replay(req.Service(), id, sum, res, rsp)
+			}
+
+			herr := h(ctx, req, rsp)
+
+			if retryable(herr) {
+				// release the claim so the request can be retried
+				if err := d.opts.Store.Delete(key); err != nil {
+					log.Logf("Error releasing request %s: %v", id, err)
+				}
+			} else if err := d.record(key, sum, rsp, herr); err != nil {
+				log.Logf("Error recording response for %s: %v", id, err)
+				d.opts.Store.Delete(key)
+			}
+
+			return herr
+		}
+	}
+}
+
+// retryable returns true if the error is a failure the request can be retried after
+func retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	e := errors.Parse(err.Error())
+	switch {
+	case e.Code == 0, e.Code >= 500, e.Code == 408, e.Code == 429:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+	"github.com/micro/go-micro/store/memory"
+)
+
+type testRequest struct {
+	server.Request
+	body map[string]string
+}
+
+func (testRequest) Service() string  { return "foo" }
+func (testRequest) Endpoint() string { return "Foo.Bar" }
+func (testRequest) Stream() bool     { return false }
+
+func (r testRequest) Body() interface{} { return r.body }
+
+type testResponse struct {
+	Count int
+}
+
+func TestHandlerWrapper(t *testing.T) {
+	var mtx sync.Mutex
+	var count int
+
+	handler := NewHandlerWrapper()(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		count++
+		rsp.(*testResponse).Count = count
+		return nil
+	})
+
+	call := func(key string) int {
+		ctx := context.TODO()
+		if len(key) > 0 {
+			ctx = metadata.NewContext(ctx, metadata.Metadata{KeyHeader: key})
+		}
+		rsp := new(testResponse)
+		if err := handler(ctx, testRequest{}, rsp); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		return rsp.Count
+	}
+
+	// duplicates are replayed
+	if n := call("a"); n != 1 {
+		t.Fatalf("Expected 1 got %d", n)
+	}
+	if n := call("a"); n != 1 {
+		t.Fatalf("Expected replayed response 1 got %d", n)
+	}
+
+	// other keys and requests without a key are handled
+	if n := call("b"); n != 2 {
+		t.Fatalf("Expected 2 got %d", n)
+	}
+	if n := call(""); n != 3 {
+		t.Fatalf("Expected 3 got %d", n)
+	}
+
+	// concurrent duplicates are handled once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			call("c")
+		}()
+	}
+	wg.Wait()
+
+	if count != 4 {
+		t.Fatalf("Expected handler to be called 4 times got %d", count)
+	}
+}
+
+func TestHandlerWrapperErrors(t *testing.T) {
+	var count int
+
+	handler := NewHandlerWrapper()(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		count++
+		if count == 1 {
+			return errors.InternalServerError("foo", "failed")
+		}
+		return errors.BadRequest("foo", "bad request")
+	})
+
+	ctx := metadata.NewContext(context.TODO(), metadata.Metadata{KeyHeader: "a"})
+
+	// server errors are retried
+	for i := 0; i < 3; i++ {
+		handler(ctx, testRequest{}, new(testResponse))
+	}
+	if count != 2 {
+		t.Fatalf("Expected handler to be called 2 times got %d", count)
+	}
+
+	// client errors are replayed
+	err := handler(ctx, testRequest{}, new(testResponse))
+	if e := errors.Parse(err.Error()); e.Code != 400 {
+		t.Fatalf("Expected 400 got %v", err)
+	}
+}
+
+func TestHandlerWrapperBody(t *testing.T) {
+	var count int
+
+	handler := NewHandlerWrapper()(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		count++
+		return nil
+	})
+
+	// servers pass the key in the context
+	ctx := server.NewIdempotencyContext(context.TODO(), "a")
+
+	req := testRequest{body: map[string]string{"name": "foo"}}
+	for i := 0; i < 2; i++ {
+		if err := handler(ctx, req, new(testResponse)); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+	if count != 1 {
+		t.Fatalf("Expected handler to be called once got %d", count)
+	}
+
+	// the key can't be reused for another request
+	err := handler(ctx, testRequest{body: map[string]string{"name": "bar"}}, new(testResponse))
+	if err == nil {
+		t.Fatal("Expected error reusing the key")
+	}
+	if e := errors.Parse(err.Error()); e.Code != 422 {
+		t.Fatalf("Expected 422 got %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Expected handler to be called once got %d", count)
+	}
+}
+
+func TestHandlerWrapperShared(t *testing.T) {
+	var mtx sync.Mutex
+	var count int
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+
+	handler := func(ctx context.Context, req server.Request, rsp interface{}) error {
+		mtx.Lock()
+		count++
+		n := count
+		mtx.Unlock()
+		if n == 1 {
+			close(started)
+			<-unblock
+		}
+		rsp.(*testResponse).Count = n
+		return nil
+	}
+
+	// two instances of the service sharing a store
+	st := memory.NewStore()
+	a := NewHandlerWrapper(Store(st))(handler)
+	b := NewHandlerWrapper(Store(st))(handler)
+
+	ctx := metadata.NewContext(context.TODO(), metadata.Metadata{
+		KeyHeader:    "a",
+		CallerHeader: "bar",
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- a(ctx, testRequest{}, new(testResponse))
+	}()
+	<-started
+
+	// the duplicate waits for the request claimed by the other instance
+	done := make(chan *testResponse, 1)
+	go func() {
+		rsp := new(testResponse)
+		if err := b(ctx, testRequest{}, rsp); err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+		done <- rsp
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Expected the duplicate to wait for the pending request")
+	case <-time.After(pollInterval * 2):
+	}
+
+	close(unblock)
+	if err := <-errs; err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if rsp := <-done; rsp.Count != 1 {
+		t.Fatalf("Expected replayed response 1 got %d", rsp.Count)
+	}
+
+	// keys are scoped to the caller
+	ctx = metadata.NewContext(context.TODO(), metadata.Metadata{
+		KeyHeader:    "a",
+		CallerHeader: "baz",
+	})
+	rsp := new(testResponse)
+	if err := b(ctx, testRequest{}, rsp); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if rsp.Count != 2 {
+		t.Fatalf("Expected 2 got %d", rsp.Count)
+	}
+
+	// authenticated callers are scoped by their certificate
+	ctx = server.NewPeerContext(server.NewIdempotencyContext(context.TODO(), "a"), "bar")
+	rsp = new(testResponse)
+	if err := a(ctx, testRequest{}, rsp); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if rsp.Count != 1 {
+		t.Fatalf("Expected replayed response 1 got %d", rsp.Count)
+	}
+}
@@ -0,0 +1,43 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/store"
+)
+
+type Options struct {
+	// Store the responses are recorded in
+	Store store.Store
+	// TTL is how long a response is replayed for
+	TTL time.Duration
+	// Timeout is how long a request is claimed for
+	Timeout time.Duration
+}
+
+type Option func(o *Options)
+
+// Store sets the store responses are recorded in. Defaults to a memory
+// store which only catches duplicates handled by the same instance. Use
+// a shared store for duplicates sent to other instances to be caught.
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// TTL sets how long a response is replayed for. Defaults to DefaultTTL
+func TTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = d
+	}
+}
+
+// Timeout sets how long a request is claimed for while it's handled.
+// Duplicates are handled again if the instance handling the request
+// doesn't record its response in time. Defaults to DefaultTimeout
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
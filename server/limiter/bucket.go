@@ -0,0 +1,74 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// bucket is a token bucket refilled at rate tokens per second up to burst
+type bucket struct {
+	rate  float64
+	burst float64
+
+	sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(l Limit) *bucket {
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &bucket{
+		rate:   l.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// take removes a token from the bucket if one is available
+func (b *bucket) take() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// refund returns a token taken for a request which was rejected
+func (b *bucket) refund() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// full reports whether the bucket has refilled by now so
+// it's no different from a new bucket
+func (b *bucket) full(now time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.rate <= 0 {
+		return false
+	}
+
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
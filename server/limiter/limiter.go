@@ -0,0 +1,186 @@
+// Package limiter provides rate and concurrency limits for server handlers and subscribers
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+)
+
+var (
+	// CallerHeader identifies the service making a request
+	CallerHeader = "Micro-From-Service"
+
+	// how often buckets of idle endpoints and callers are removed
+	sweepInterval = time.Minute
+)
+
+// Limiter enforces the limits for the handlers and
+// subscribers of a server sharing it
+type Limiter struct {
+	opts Options
+	// semaphore for in flight requests
+	inflight chan struct{}
+
+	sync.Mutex
+	endpoints map[string]*bucket
+	callers   map[string]*bucket
+	// last time idle callers were removed
+	swept time.Time
+}
+
+// NewLimiter returns a limiter enforcing the limits
+func NewLimiter(opts ...Option) *Limiter {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	l := &Limiter{
+		opts:      options,
+		endpoints: make(map[string]*bucket),
+		callers:   make(map[string]*bucket),
+		swept:     time.Now(),
+	}
+
+	if options.MaxConcurrent > 0 {
+		l.inflight = make(chan struct{}, options.MaxConcurrent)
+	}
+
+	return l
+}
+
+// sweep removes the buckets which have been idle long enough to be full
+// again as endpoints and callers are named by the client. The caller
+// must hold the lock.
+func (l *Limiter) sweep() {
+	now := time.Now()
+	if now.Sub(l.swept) <= sweepInterval {
+		return
+	}
+
+	for _, buckets := range []map[string]*bucket{l.endpoints, l.callers} {
+		for k, b := range buckets {
+			if b.full(now) {
+				delete(buckets, k)
+			}
+		}
+	}
+
+	l.swept = now
+}
+
+// endpoint returns the bucket for the endpoint or nil if it's not limited
+func (l *Limiter) endpoint(name string) *bucket {
+	lim, ok := l.opts.Endpoints[name]
+	if !ok {
+		if l.opts.Rate == nil {
+			return nil
+		}
+		lim = *l.opts.Rate
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	l.sweep()
+
+	b, ok := l.endpoints[name]
+	if !ok {
+		b = newBucket(lim)
+		l.endpoints[name] = b
+	}
+	return b
+}
+
+// caller returns the bucket for the caller or nil if it's not limited
+func (l *Limiter) caller(name string) *bucket {
+	if l.opts.Caller == nil || len(name) == 0 {
+		return nil
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	l.sweep()
+
+	b, ok := l.callers[name]
+	if !ok {
+		b = newBucket(*l.opts.Caller)
+		l.callers[name] = b
+	}
+	return b
+}
+
+// acquire checks the limits for a request. The returned
+// func releases the request once it has been handled.
+func (l *Limiter) acquire(ctx context.Context, service, endpoint string) (func(), error) {
+	eb := l.endpoint(endpoint)
+	if eb != nil && !eb.take() {
+		return nil, errors.New(service, "rate limit exceeded for "+endpoint, 429)
+	}
+
+	// tokens taken for a request rejected by a later limit are refunded
+	caller, _ := metadata.Get(ctx, CallerHeader)
+	cb := l.caller(caller)
+	if cb != nil && !cb.take() {
+		if eb != nil {
+			eb.refund()
+		}
+		return nil, errors.New(service, "rate limit exceeded for "+caller, 429)
+	}
+
+	if l.inflight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.inflight <- struct{}{}:
+		return func() { <-l.inflight }, nil
+	default:
+		for _, b := range []*bucket{eb, cb} {
+			if b != nil {
+				b.refund()
+			}
+		}
+		return nil, errors.New(service, "too many concurrent requests", 429)
+	}
+}
+
+// NewHandlerWrapper returns a handler wrapper which rejects requests
+// over the limits with a 429 error
+func NewHandlerWrapper(l *Limiter) server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			release, err := l.acquire(ctx, req.Service(), req.Endpoint())
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			return h(ctx, req, rsp)
+		}
+	}
+}
+
+// NewSubscriberWrapper returns a subscriber wrapper which rejects messages
+// over the limits so the broker redelivers them later. Share the limiter
+// with the handler wrapper so requests and messages count against the
+// same concurrency limit.
+func NewSubscriberWrapper(l *Limiter) server.SubscriberWrapper {
+	return func(fn server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			release, err := l.acquire(ctx, "go.micro.server", msg.Topic())
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			return fn(ctx, msg)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+)
+
+func TestRateLimit(t *testing.T) {
+	l := NewLimiter(Rate(1000, 2), EndpointRate("Foo.Slow", 1, 1))
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.acquire(context.TODO(), "foo", "Foo.Bar"); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+	_, err := l.acquire(context.TODO(), "foo", "Foo.Bar")
+	if e := errors.Parse(err.Error()); e.Code != 429 {
+		t.Fatalf("Expected 429 got %v", err)
+	}
+
+	// the bucket refills over time
+	time.Sleep(5 * time.Millisecond)
+	if _, err := l.acquire(context.TODO(), "foo", "Foo.Bar"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// endpoints have their own limits
+	if _, err := l.acquire(context.TODO(), "foo", "Foo.Slow"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := l.acquire(context.TODO(), "foo", "Foo.Slow"); err == nil {
+		t.Fatal("Expected endpoint to be limited")
+	}
+}
+
+func TestCallerLimit(t *testing.T) {
+	l := NewLimiter(CallerRate(0, 1))
+
+	bar := metadata.NewContext(context.TODO(), metadata.Metadata{CallerHeader: "bar"})
+	baz := metadata.NewContext(context.TODO(), metadata.Metadata{CallerHeader: "baz"})
+
+	if _, err := l.acquire(bar, "foo", "Foo.Bar"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err := l.acquire(bar, "foo", "Foo.Bar"); err == nil {
+		t.Fatal("Expected caller to be limited")
+	}
+	if _, err := l.acquire(baz, "foo", "Foo.Bar"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	l := NewLimiter(MaxConcurrent(2))
+
+	r1, err := l.acquire(context.TODO(), "foo", "Foo.Bar")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err := l.acquire(context.TODO(), "foo", "Foo.Bar"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err := l.acquire(context.TODO(), "foo", "Foo.Bar"); err == nil {
+		t.Fatal("Expected concurrency to be limited")
+	}
+
+	r1()
+	if _, err := l.acquire(context.TODO(), "foo", "Foo.Bar"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestCallerExpiry(t *testing.T) {
+	l := NewLimiter(CallerRate(1000, 1))
+
+	for _, caller := range []string{"bar", "baz"} {
+		ctx := metadata.NewContext(context.TODO(), metadata.Metadata{CallerHeader: caller})
+		if _, err := l.acquire(ctx, "foo", "Foo.Bar"); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+
+	// idle callers are removed once their bucket is full
+	time.Sleep(5 * time.Millisecond)
+	l.swept = time.Now().Add(-sweepInterval * 2)
+
+	ctx := metadata.NewContext(context.TODO(), metadata.Metadata{CallerHeader: "qux"})
+	if _, err := l.acquire(ctx, "foo", "Foo.Bar"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if n := len(l.callers); n != 1 {
+		t.Fatalf("Expected 1 caller got %d", n)
+	}
+}
+
+func TestEndpointExpiry(t *testing.T) {
+	// endpoints aren't tracked without a rate
+	l := NewLimiter(MaxConcurrent(10))
+	release, err := l.acquire(context.TODO(), "foo", "Foo.Bar")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	release()
+	if n := len(l.endpoints); n != 0 {
+		t.Fatalf("Expected no endpoints got %d", n)
+	}
+
+	l = NewLimiter(Rate(1000, 1))
+
+	for _, endpoint := range []string{"Foo.Bar", "Foo.Baz"} {
+		if _, err := l.acquire(context.TODO(), "foo", endpoint); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+
+	// idle endpoints are removed once their bucket is full
+	time.Sleep(5 * time.Millisecond)
+	l.swept = time.Now().Add(-sweepInterval * 2)
+
+	if _, err := l.acquire(context.TODO(), "foo", "Foo.Qux"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if n := len(l.endpoints); n != 1 {
+		t.Fatalf("Expected 1 endpoint got %d", n)
+	}
+}
+
+func TestRefund(t *testing.T) {
+	l := NewLimiter(Rate(0, 1), CallerRate(0, 1))
+
+	bar := metadata.NewContext(context.TODO(), metadata.Metadata{CallerHeader: "bar"})
+	if _, err := l.acquire(bar, "foo", "Foo.Bar"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// the caller is limited before the endpoint
+	baz := metadata.NewContext(context.TODO(), metadata.Metadata{CallerHeader: "baz"})
+	if _, err := l.acquire(baz, "foo", "Foo.Baz"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err := l.acquire(baz, "foo", "Foo.Qux"); err == nil {
+		t.Fatal("Expected caller to be limited")
+	}
+
+	// the endpoint token of the rejected request was refunded
+	if _, err := l.acquire(bar, "foo", "Foo.Qux"); err == nil {
+		t.Fatal("Expected caller to be limited")
+	}
+	qux := metadata.NewContext(context.TODO(), metadata.Metadata{CallerHeader: "qux"})
+	if _, err := l.acquire(qux, "foo", "Foo.Qux"); err != nil {
+		t.Fatalf("Expected the endpoint token to be refunded got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package limiter
+
+// Limit is the rate of a token bucket. Rate is the number of requests
+// allowed per second and Burst the number allowed at once.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Options struct {
+	// Rate is the limit for each endpoint
+	Rate *Limit
+	// Endpoints overrides the limit for individual endpoints
+	Endpoints map[string]Limit
+	// Caller is the limit for each calling service
+	// identified by the Micro-From-Service header
+	Caller *Limit
+	// MaxConcurrent is the maximum number of
+	// requests handled at once. Zero is unlimited.
+	MaxConcurrent int
+}
+
+type Option func(o *Options)
+
+// Rate limits the requests to each endpoint
+func Rate(rate float64, burst int) Option {
+	return func(o *Options) {
+		o.Rate = &Limit{Rate: rate, Burst: burst}
+	}
+}
+
+// EndpointRate limits the requests to a single endpoint e.g Greeter.Hello.
+// For subscribers the endpoint is the topic.
+func EndpointRate(endpoint string, rate float64, burst int) Option {
+	return func(o *Options) {
+		if o.Endpoints == nil {
+			o.Endpoints = make(map[string]Limit)
+		}
+		o.Endpoints[endpoint] = Limit{Rate: rate, Burst: burst}
+	}
+}
+
+// CallerRate limits the requests from each calling service
+func CallerRate(rate float64, burst int) Option {
+	return func(o *Options) {
+		o.Caller = &Limit{Rate: rate, Burst: burst}
+	}
+}
+
+// MaxConcurrent limits the number of requests handled at once
+func MaxConcurrent(n int) Option {
+	return func(o *Options) {
+		o.MaxConcurrent = n
+	}
+}
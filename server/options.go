@@ -7,6 +7,7 @@ import (
 
 	"github.com/micro/go-micro/broker"
 	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/codec/compress"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/transport"
 )
@@ -31,6 +32,18 @@ type Options struct {
 	RegisterTTL time.Duration
 	// The interval on which to register
 	RegisterInterval time.Duration
+	// DrainPeriod is how long requests are still served after
+	// deregistering so clients learn the server is going away
+	DrainPeriod time.Duration
+	// ShutdownTimeout is how long the server waits for requests
+	// and messages in flight before closing their connections
+	ShutdownTimeout time.Duration
+	// Compression is the encoding of response bodies
+	// if the client accepts it, empty disables it
+	Compression string
+	// CompressionThreshold is the body size in bytes
+	// from which responses are compressed
+	CompressionThreshold int
 
 	// The router for requests
 	Router Router
@@ -46,6 +59,10 @@ func newOptions(opt ...Option) Options {
 		Metadata:         map[string]string{},
 		RegisterInterval: DefaultRegisterInterval,
 		RegisterTTL:      DefaultRegisterTTL,
+		DrainPeriod:      DefaultDrainPeriod,
+		ShutdownTimeout:  DefaultShutdownTimeout,
+
+		CompressionThreshold: compress.DefaultThreshold,
 	}
 
 	for _, o := range opt {
@@ -178,6 +195,37 @@ func RegisterInterval(t time.Duration) Option {
 	}
 }
 
+// DrainPeriod keeps serving requests for d after deregistering
+// so clients stop sending requests before the server stops
+func DrainPeriod(d time.Duration) Option {
+	return func(o *Options) {
+		o.DrainPeriod = d
+	}
+}
+
+// ShutdownTimeout sets how long the server waits for requests in flight
+// before closing their connections. Zero waits until they're done.
+func ShutdownTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.ShutdownTimeout = d
+	}
+}
+
+// Compression sets the encoding used to compress response bodies e.g. gzip.
+// Responses are only compressed for clients accepting the encoding.
+func Compression(name string) Option {
+	return func(o *Options) {
+		o.Compression = name
+	}
+}
+
+// CompressionThreshold sets the body size in bytes from which responses are compressed
+func CompressionThreshold(n int) Option {
+	return func(o *Options) {
+		o.CompressionThreshold = n
+	}
+}
+
 // WithRouter sets the request router
 func WithRouter(r Router) Option {
 	return func(o *Options) {
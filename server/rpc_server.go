@@ -15,6 +15,8 @@ import (
 	"github.com/micro/go-micro/broker"
 	"github.com/micro/go-micro/codec"
 	raw "github.com/micro/go-micro/codec/bytes"
+	"github.com/micro/go-micro/codec/compress"
+	"github.com/micro/go-micro/errors"
 	"github.com/micro/go-micro/metadata"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/transport"
@@ -22,6 +24,7 @@ import (
 	log "github.com/micro/go-micro/util/log"
 	mnet "github.com/micro/go-micro/util/net"
 	"github.com/micro/go-micro/util/socket"
+	mls "github.com/micro/go-micro/util/tls"
 )
 
 type rpcServer struct {
@@ -40,6 +43,12 @@ type rpcServer struct {
 	subscriber broker.Subscriber
 	// graceful exit
 	wg *sync.WaitGroup
+	// requests and messages in flight
+	inflight *Counter
+	// set while shutting down to reject new requests
+	draining bool
+	// open connections closed if requests don't finish in time
+	conns map[transport.Socket]bool
 }
 
 func newRpcServer(opts ...Option) Server {
@@ -55,12 +64,18 @@ func newRpcServer(opts ...Option) Server {
 		subscribers: make(map[Subscriber][]broker.Subscriber),
 		exit:        make(chan chan error),
 		wg:          wait(options.Context),
+		inflight:    new(Counter),
+		conns:       make(map[transport.Socket]bool),
 	}
 }
 
 // HandleEvent handles inbound messages to the service directly
 // TODO: handle requests from an event. We won't send a response.
 func (s *rpcServer) HandleEvent(e broker.Event) error {
+	// track the message until it's handled
+	s.inflight.Add(1)
+	defer s.inflight.Add(-1)
+
 	// formatting horrible cruft
 	msg := e.Message()
 
@@ -136,14 +151,21 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 	// get global waitgroup
 	s.Lock()
 	gg := s.wg
+	// track the connection so it can be closed on shutdown
+	s.conns[sock] = true
 	s.Unlock()
 
 	// waitgroup to wait for processing to finish
 	wg := &waitGroup{
 		gg: gg,
+		c:  s.inflight,
 	}
 
 	defer func() {
+		s.Lock()
+		delete(s.conns, sock)
+		s.Unlock()
+
 		// only wait if there's no error
 		if gerr == nil {
 			// wait till done
@@ -174,6 +196,23 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			return
 		}
 
+		// decompress the body
+		body, err := compress.Decode(msg.Header, msg.Body)
+		if err != nil {
+			// unknown encoding so send back an error
+			if err := sock.Send(&transport.Message{
+				Header: map[string]string{
+					"Content-Type": "text/plain",
+				},
+				Body: []byte(err.Error()),
+			}); err != nil {
+				gerr = err
+				return
+			}
+			continue
+		}
+		msg.Body = body
+
 		// check the message header for
 		// Micro-Service is a request
 		// Micro-Topic is a message
@@ -260,6 +299,8 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		to := msg.Header["Timeout"]
 		// we use this Content-Type header to identify the codec needed
 		ct := msg.Header["Content-Type"]
+		// we use this Accept-Encoding header to compress responses
+		accept := msg.Header[compress.AcceptEncodingHeader]
 
 		// copy the message headers
 		hdr := make(map[string]string)
@@ -271,18 +312,35 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		hdr["Local"] = sock.Local()
 		hdr["Remote"] = sock.Remote()
 
+		// don't pass the idempotency key on to nested calls
+		key := hdr[IdempotencyKeyHeader]
+		delete(hdr, IdempotencyKeyHeader)
+
 		// create new context with the metadata
 		ctx := metadata.NewContext(context.Background(), hdr)
 
-		// set the timeout from the header if we have it
+		// set the idempotency key of the request
+		if len(key) > 0 {
+			ctx = NewIdempotencyContext(ctx, key)
+		}
+
+		// set the service authenticated by the connection
+		if name := mls.PeerService(transport.ConnectionState(sock)); len(name) > 0 {
+			ctx = NewPeerContext(ctx, name)
+		}
+
+		// set the timeout from the header if we have it, the
+		// client sends the time remaining so nested calls inherit it
+		cancel := func() {}
 		if len(to) > 0 {
 			if n, err := strconv.ParseUint(to, 10, 64); err == nil {
-				var cancel context.CancelFunc
 				ctx, cancel = context.WithTimeout(ctx, time.Duration(n))
-				defer cancel()
 			}
 		}
 
+		// don't pass the encodings on to nested calls
+		delete(hdr, compress.AcceptEncodingHeader)
+
 		// if there's no content type default it
 		if len(ct) == 0 {
 			msg.Header["Content-Type"] = DefaultContentType
@@ -307,6 +365,8 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 					gerr = err
 				}
 
+				// the request won't be served
+				cancel()
 				// release the socket we just created
 				pool.Release(psock)
 				// now continue
@@ -358,6 +418,12 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			r = rpcRouter{h: handler}
 		}
 
+		// compress responses if the client accepts the encoding
+		var compression string
+		if protocol != "grpc" && compress.Accepts(accept, s.opts.Compression) {
+			compression = s.opts.Compression
+		}
+
 		// process the outbound messages from the socket
 		go func(id string, psock *socket.Socket) {
 			// wait for processing to exit
@@ -383,6 +449,18 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 					return
 				}
 
+				// compress the body
+				if len(compression) > 0 {
+					if m.Header == nil {
+						m.Header = make(map[string]string)
+					}
+					b, err := compress.Encode(compression, s.opts.CompressionThreshold, m.Header, m.Body)
+					if err != nil {
+						return
+					}
+					m.Body = b
+				}
+
 				// send the message back over the socket
 				if err := sock.Send(m); err != nil {
 					return
@@ -396,14 +474,32 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			wg.Add(1)
 
 			defer func() {
+				// release the context of the request
+				cancel()
 				// release the socket
 				pool.Release(psock)
 				// signal we're done
 				wg.Done()
 			}()
 
-			// serve the actual request using the request router
-			if serveRequestError := r.ServeRequest(ctx, request, response); serveRequestError != nil {
+			var serveRequestError error
+
+			s.RLock()
+			draining := s.draining
+			s.RUnlock()
+
+			// reject new requests while shutting down
+			if draining {
+				serveRequestError = ErrShuttingDown
+			} else if ctx.Err() != nil {
+				// reject the request if its deadline has already passed
+				serveRequestError = errors.Timeout("go.micro.server", "deadline exceeded")
+			} else {
+				// serve the actual request using the request router
+				serveRequestError = r.ServeRequest(ctx, request, response)
+			}
+
+			if serveRequestError != nil {
 				// write an error response
 				writeError := rcodec.Write(&codec.Message{
 					Header: msg.Header,
@@ -635,6 +731,12 @@ func (s *rpcServer) Register() error {
 			opts = append(opts, broker.DisableAutoAck())
 		}
 
+		opts = append(opts,
+			broker.MaxAttempts(sb.Options().MaxAttempts),
+			broker.Backoff(sb.Options().Backoff),
+			broker.DeadLetter(sb.Options().DeadLetter),
+		)
+
 		sub, err := config.Broker.Subscribe(sb.Topic(), s.HandleEvent, opts...)
 		if err != nil {
 			return err
@@ -840,17 +942,37 @@ func (s *rpcServer) Start() error {
 			log.Logf("Server %s-%s deregister error: %s", config.Name, config.Id, err)
 		}
 
+		// keep serving while clients learn the server is going away
+		if config.DrainPeriod > 0 {
+			time.Sleep(config.DrainPeriod)
+		}
+
+		// stop accepting new requests
 		s.Lock()
+		s.draining = true
 		swg := s.wg
 		s.Unlock()
 
+		// close transport listener
+		err := ts.Close()
+
+		var deadline time.Time
+		if config.ShutdownTimeout > 0 {
+			deadline = time.Now().Add(config.ShutdownTimeout)
+		}
+
 		// wait for requests to finish
-		if swg != nil {
-			swg.Wait()
+		if !s.inflight.Wait(swg, deadline) {
+			log.Logf("Server %s-%s shutdown timeout, closing connections", config.Name, config.Id)
+
+			s.Lock()
+			for sock := range s.conns {
+				sock.Close()
+			}
+			s.Unlock()
 		}
 
-		// close transport listener
-		ch <- ts.Close()
+		ch <- err
 
 		// disconnect the broker
 		config.Broker.Disconnect()
@@ -858,6 +980,7 @@ func (s *rpcServer) Start() error {
 		// swap back address
 		s.Lock()
 		s.opts.Address = addr
+		s.draining = false
 		s.Unlock()
 	}()
 
@@ -10,6 +10,8 @@ type waitGroup struct {
 	lg sync.WaitGroup
 	// global waitgroup
 	gg *sync.WaitGroup
+	// requests in flight on the server
+	c *Counter
 }
 
 func (w *waitGroup) Add(i int) {
@@ -17,6 +19,7 @@ func (w *waitGroup) Add(i int) {
 	if w.gg != nil {
 		w.gg.Add(i)
 	}
+	w.c.Add(i)
 }
 
 func (w *waitGroup) Done() {
@@ -24,6 +27,7 @@ func (w *waitGroup) Done() {
 	if w.gg != nil {
 		w.gg.Done()
 	}
+	w.c.Add(-1)
 }
 
 func (w *waitGroup) Wait() {
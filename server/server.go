@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/errors"
 	"github.com/micro/go-micro/registry"
 	log "github.com/micro/go-micro/util/log"
 )
@@ -136,6 +137,15 @@ var (
 	DefaultRegisterCheck           = func(context.Context) error { return nil }
 	DefaultRegisterInterval        = time.Second * 30
 	DefaultRegisterTTL             = time.Minute
+	DefaultDrainPeriod             = time.Duration(0)
+	DefaultShutdownTimeout         = time.Second * 10
+
+	// IdempotencyKeyHeader is the metadata key servers deduplicate calls by
+	IdempotencyKeyHeader = "Micro-Idempotency-Key"
+
+	// ErrShuttingDown is returned for requests received while the server
+	// shuts down. Clients retry them on another node.
+	ErrShuttingDown = errors.New("go.micro.server.shutdown", "server is shutting down", 503)
 )
 
 // DefaultOptions returns config options for the default service
@@ -1,6 +1,7 @@
 package micro
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/micro/go-micro/client"
 	"github.com/micro/go-micro/config/cmd"
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/debug/service/handler"
 	"github.com/micro/go-micro/debug/profile"
 	"github.com/micro/go-micro/debug/profile/pprof"
@@ -143,11 +145,27 @@ func (s *service) Run() error {
 	// register the debug handler
 	s.opts.Server.Handle(
 		s.opts.Server.NewHandler(
-			handler.DefaultHandler,
+			handler.NewHandler(s.opts.Health),
 			server.InternalHandler(true),
 		),
 	)
 
+	// register the readiness checks of the server
+	s.opts.Health.Register("server", health.Readiness, func(ctx context.Context) error {
+		return s.opts.Server.Options().RegisterCheck(ctx)
+	})
+	s.opts.Health.Register("broker", health.Readiness, health.Broker(s.opts.Server.Options().Broker))
+
+	// serve the checks from the server unless it has its own
+	s.opts.Server.Init(func(o *server.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		if _, ok := health.FromContext(o.Context); !ok {
+			o.Context = health.NewContext(o.Context, s.opts.Health)
+		}
+	})
+
 	// start the profiler
 	// TODO: set as an option to the service, don't just use pprof
 	if prof := os.Getenv("MICRO_DEBUG_PROFILE"); len(prof) > 0 {
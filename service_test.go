@@ -5,13 +5,19 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	glog "github.com/go-log/log"
 	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/client/selector"
+	"github.com/micro/go-micro/codec/compress"
 	proto "github.com/micro/go-micro/debug/service/proto"
 	"github.com/micro/go-micro/registry/memory"
+	"github.com/micro/go-micro/server"
+	"github.com/micro/go-micro/transport"
 	"github.com/micro/go-micro/util/log"
 	"github.com/micro/go-micro/util/test"
+	mls "github.com/micro/go-micro/util/tls"
 )
 
 func testShutdown(wg *sync.WaitGroup, cancel func()) {
@@ -23,7 +29,7 @@ func testShutdown(wg *sync.WaitGroup, cancel func()) {
 	wg.Wait()
 }
 
-func testService(ctx context.Context, wg *sync.WaitGroup, name string) Service {
+func testService(ctx context.Context, wg *sync.WaitGroup, name string, opts ...Option) Service {
 	// set no op logger
 	log.SetLogger(glog.DefaultLogger)
 
@@ -33,7 +39,7 @@ func testService(ctx context.Context, wg *sync.WaitGroup, name string) Service {
 	r := memory.NewRegistry(memory.Services(test.Data))
 
 	// create service
-	return NewService(
+	return NewService(append([]Option{
 		Name(name),
 		Context(ctx),
 		Registry(r),
@@ -45,7 +51,7 @@ func testService(ctx context.Context, wg *sync.WaitGroup, name string) Service {
 			wg.Done()
 			return nil
 		}),
-	)
+	}, opts...)...)
 }
 
 func testRequest(ctx context.Context, c client.Client, name string) error {
@@ -100,6 +106,304 @@ func TestService(t *testing.T) {
 	}
 }
 
+// TestServiceDeadline tests the deadline of a call is carried to the handler
+func TestServiceDeadline(t *testing.T) {
+	// waitgroup for server start
+	var wg sync.WaitGroup
+
+	// cancellation context
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// deadline seen by the handler
+	deadlines := make(chan time.Time, 1)
+
+	// start test server
+	service := testService(ctx, &wg, "test.service",
+		WrapHandler(func(fn server.HandlerFunc) server.HandlerFunc {
+			return func(ctx context.Context, req server.Request, rsp interface{}) error {
+				d, _ := ctx.Deadline()
+				select {
+				case deadlines <- d:
+				default:
+				}
+				return fn(ctx, req, rsp)
+			}
+		}),
+	)
+
+	go func() {
+		// wait for service to start
+		wg.Wait()
+
+		// shutdown the service
+		defer testShutdown(&wg, cancel)
+
+		cctx, ccancel := context.WithTimeout(context.Background(), time.Minute)
+		defer ccancel()
+
+		req := service.Client().NewRequest("test.service", "Debug.Health", new(proto.HealthRequest))
+		if err := service.Client().Call(cctx, req, new(proto.HealthResponse)); err != nil {
+			t.Error(err)
+			return
+		}
+
+		// the handler has the deadline of the caller, give or
+		// take the time it took to send the request
+		d, _ := cctx.Deadline()
+		if got := <-deadlines; got.Sub(d) > time.Second || d.Sub(got) > time.Second {
+			t.Errorf("Expected deadline %v got %v", d, got)
+		}
+	}()
+
+	// start service
+	if err := service.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestServiceShutdown tests requests in flight complete on shutdown
+func TestServiceShutdown(t *testing.T) {
+	// waitgroup for server start
+	var wg sync.WaitGroup
+
+	// cancellation context
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// closed when the handler is called
+	started := make(chan bool)
+	var once sync.Once
+
+	// start test server
+	service := testService(ctx, &wg, "test.service",
+		WrapHandler(func(fn server.HandlerFunc) server.HandlerFunc {
+			return func(ctx context.Context, req server.Request, rsp interface{}) error {
+				once.Do(func() { close(started) })
+				time.Sleep(100 * time.Millisecond)
+				return fn(ctx, req, rsp)
+			}
+		}),
+	)
+
+	errs := make(chan error, 1)
+
+	go func() {
+		// wait for service to start
+		wg.Wait()
+
+		go func() {
+			errs <- testRequest(ctx, service.Client(), "test.service")
+		}()
+
+		// shutdown while the request is in flight
+		<-started
+		testShutdown(&wg, cancel)
+	}()
+
+	// start service
+	if err := service.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the request in flight completes
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestServiceCodecs tests calls with the binary codecs
+func TestServiceCodecs(t *testing.T) {
+	// waitgroup for server start
+	var wg sync.WaitGroup
+
+	// cancellation context
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// start test server
+	service := testService(ctx, &wg, "test.service")
+
+	errs := make(chan error, 1)
+
+	go func() {
+		// wait for service to start
+		wg.Wait()
+
+		var err error
+
+		for _, ct := range []string{"application/msgpack", "application/cbor"} {
+			req := service.Client().NewRequest(
+				"test.service",
+				"Debug.Health",
+				new(proto.HealthRequest),
+				client.WithContentType(ct),
+			)
+
+			rsp := new(proto.HealthResponse)
+
+			if err = service.Client().Call(ctx, req, rsp); err != nil {
+				break
+			}
+
+			if rsp.Status != "ok" {
+				err = errors.New(ct + " response: " + rsp.Status)
+				break
+			}
+		}
+
+		errs <- err
+
+		// shutdown the service
+		testShutdown(&wg, cancel)
+	}()
+
+	// start service
+	if err := service.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// compressTransport records the encoding of responses received by clients
+type compressTransport struct {
+	transport.Transport
+	encodings chan string
+}
+
+type compressClient struct {
+	transport.Client
+	encodings chan string
+}
+
+func (t *compressTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	c, err := t.Transport.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &compressClient{c, t.encodings}, nil
+}
+
+func (c *compressClient) Recv(m *transport.Message) error {
+	if err := c.Client.Recv(m); err != nil {
+		return err
+	}
+	select {
+	case c.encodings <- m.Header[compress.ContentEncodingHeader]:
+	default:
+	}
+	return nil
+}
+
+// TestServiceCompression tests compressed requests and responses
+func TestServiceCompression(t *testing.T) {
+	// waitgroup for server start
+	var wg sync.WaitGroup
+
+	// cancellation context
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tr := &compressTransport{
+		Transport: transport.NewTransport(),
+		encodings: make(chan string, 1),
+	}
+
+	// start test server
+	service := testService(ctx, &wg, "test.service", Transport(tr))
+
+	service.Client().Init(
+		client.Compression("gzip"),
+		client.CompressionThreshold(0),
+	)
+	service.Server().Init(
+		server.Compression("gzip"),
+		server.CompressionThreshold(0),
+	)
+
+	errs := make(chan error, 1)
+
+	go func() {
+		// wait for service to start
+		wg.Wait()
+
+		// make a test call
+		errs <- testRequest(ctx, service.Client(), "test.service")
+
+		// shutdown the service
+		testShutdown(&wg, cancel)
+	}()
+
+	// start service
+	if err := service.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if enc := <-tr.encodings; enc != "gzip" {
+		t.Fatalf("Expected gzip response got %q", enc)
+	}
+}
+
+func TestServicePeer(t *testing.T) {
+	// waitgroup for server start
+	var wg sync.WaitGroup
+
+	// cancellation context
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ca, err := mls.NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peers := make(chan string, 1)
+
+	// start test server
+	service := testService(ctx, &wg, "test.service",
+		Transport(transport.NewTransport(transport.TLSConfig(ca.Config("test.service", time.Hour)))),
+		WrapHandler(func(fn server.HandlerFunc) server.HandlerFunc {
+			return func(ctx context.Context, req server.Request, rsp interface{}) error {
+				name, _ := server.PeerService(ctx)
+				peers <- name
+				return fn(ctx, req, rsp)
+			}
+		}),
+	)
+
+	errs := make(chan error, 1)
+
+	go func() {
+		// wait for service to start
+		wg.Wait()
+
+		// make a test call from another service
+		c := client.NewClient(
+			client.Selector(selector.NewSelector(selector.Registry(service.Options().Registry))),
+			client.Transport(transport.NewTransport(transport.TLSConfig(ca.Config("test.client", time.Hour)))),
+		)
+		errs <- testRequest(ctx, c, "test.service")
+
+		// shutdown the service
+		testShutdown(&wg, cancel)
+	}()
+
+	// start service
+	if err := service.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if name := <-peers; name != "test.client" {
+		t.Fatalf("Expected peer test.client got %q", name)
+	}
+}
+
 func benchmarkService(b *testing.B, n int, name string) {
 	// stop the timer
 	b.StopTimer()
@@ -0,0 +1,77 @@
+package store
+
+// OpType is the type of a batch operation
+type OpType int
+
+const (
+	// OpWrite writes the record
+	OpWrite OpType = iota
+	// OpDelete deletes the record with the key
+	OpDelete
+)
+
+// Condition must hold for a batch to be applied
+type Condition int
+
+const (
+	// CondNone applies the operation unconditionally
+	CondNone Condition = iota
+	// CondAbsent requires that the key does not exist
+	CondAbsent
+	// CondVersion requires that the record is at the given version
+	CondVersion
+)
+
+// Op is a single operation in a batch
+type Op struct {
+	Type OpType
+	// Record to write, only the key is used on delete
+	Record *Record
+	// Condition which must hold for the batch to be applied
+	Condition Condition
+	// Version compared against for CondVersion
+	Version uint64
+}
+
+// OpOption sets the condition of an Op
+type OpOption func(o *Op)
+
+// IfAbsent applies the operation only if the key does not exist
+func IfAbsent() OpOption {
+	return func(o *Op) {
+		o.Condition = CondAbsent
+	}
+}
+
+// IfVersion applies the operation only if the stored
+// record is at version v as returned by Read
+func IfVersion(v uint64) OpOption {
+	return func(o *Op) {
+		o.Condition = CondVersion
+		o.Version = v
+	}
+}
+
+// WriteOp returns an operation which writes the record
+func WriteOp(r *Record, opts ...OpOption) *Op {
+	op := &Op{
+		Type:   OpWrite,
+		Record: r,
+	}
+	for _, o := range opts {
+		o(op)
+	}
+	return op
+}
+
+// DeleteOp returns an operation which deletes the key
+func DeleteOp(key string, opts ...OpOption) *Op {
+	op := &Op{
+		Type:   OpDelete,
+		Record: &Record{Key: key},
+	}
+	for _, o := range opts {
+		o(op)
+	}
+	return op
+}
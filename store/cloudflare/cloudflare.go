@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/micro/go-micro/config/options"
@@ -26,6 +27,11 @@ const (
 	apiBaseURL = "https://api.cloudflare.com/client/v4/"
 )
 
+var (
+	// DefaultWatchInterval is how often watchers poll for changes
+	DefaultWatchInterval = time.Minute
+)
+
 type workersKV struct {
 	options.Options
 	// cf account id
@@ -36,6 +42,8 @@ type workersKV struct {
 	namespace string
 	// http client to use
 	httpClient *http.Client
+	// how often watchers poll for changes
+	watchInterval time.Duration
 }
 
 // apiResponse is a cloudflare v4 api response
@@ -61,10 +69,11 @@ type apiResponse struct {
 	// not sure Messages is ever populated?
 	Messages   []apiMessage `json:"messages"`
 	ResultInfo struct {
-		Page       int `json:"page"`
-		PerPage    int `json:"per_page"`
-		Count      int `json:"count"`
-		TotalCount int `json:"total_count"`
+		Page       int    `json:"page"`
+		PerPage    int    `json:"per_page"`
+		Count      int    `json:"count"`
+		TotalCount int    `json:"total_count"`
+		Cursor     string `json:"cursor"`
 	} `json:"result_info"`
 }
 
@@ -99,40 +108,112 @@ func validateOptions(account, token, namespace string) {
 
 // In the cloudflare workers KV implemention, List() doesn't guarantee
 // anything as the workers API is eventually consistent.
-func (w *workersKV) List() ([]*store.Record, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	path := fmt.Sprintf("accounts/%s/storage/kv/namespaces/%s/keys", w.account, w.namespace)
+func (w *workersKV) List(opts ...store.ListOption) ([]*store.Record, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
 
-	response, _, _, err := w.request(ctx, http.MethodGet, path, nil, make(http.Header))
+	keys, err := w.listKeys(options.Prefix, options.Suffix, options.Offset, options.Limit)
 	if err != nil {
 		return nil, err
 	}
 
-	a := &apiResponse{}
-	if err := json.Unmarshal(response, a); err != nil {
-		return nil, err
+	return w.read(keys...)
+}
+
+func (w *workersKV) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
 	}
 
-	if !a.Success {
-		messages := ""
-		for _, m := range a.Errors {
-			messages += strconv.Itoa(m.Code) + " " + m.Message + "\n"
-		}
-		return nil, errors.New(messages)
+	if !options.Prefix && !options.Suffix {
+		return w.read(key)
 	}
 
-	keys := make([]string, 0, len(a.Result))
+	var prefix, suffix string
+	if options.Prefix {
+		prefix = key
+	}
+	if options.Suffix {
+		suffix = key
+	}
 
-	for _, r := range a.Result {
-		keys = append(keys, r.Name)
+	keys, err := w.listKeys(prefix, suffix, options.Offset, options.Limit)
+	if err != nil {
+		return nil, err
 	}
 
-	return w.Read(keys...)
+	return w.read(keys...)
 }
 
-func (w *workersKV) Read(keys ...string) ([]*store.Record, error) {
+// listKeys returns the names of the keys matching prefix and suffix.
+// The workers API returns keys in lexicographic order.
+func (w *workersKV) listKeys(prefix, suffix string, offset, limit uint) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	path := fmt.Sprintf("accounts/%s/storage/kv/namespaces/%s/keys", w.account, w.namespace)
+
+	//nolint:prealloc
+	var keys []string
+	var cursor string
+
+	for {
+		params := url.Values{}
+		if len(prefix) > 0 {
+			params.Set("prefix", prefix)
+		}
+		if len(cursor) > 0 {
+			params.Set("cursor", cursor)
+		}
+
+		p := path
+		if len(params) > 0 {
+			p = path + "?" + params.Encode()
+		}
+
+		response, _, _, err := w.request(ctx, http.MethodGet, p, nil, make(http.Header))
+		if err != nil {
+			return nil, err
+		}
+
+		a := &apiResponse{}
+		if err := json.Unmarshal(response, a); err != nil {
+			return nil, err
+		}
+
+		if !a.Success {
+			messages := ""
+			for _, m := range a.Errors {
+				messages += strconv.Itoa(m.Code) + " " + m.Message + "\n"
+			}
+			return nil, errors.New(messages)
+		}
+
+		for _, r := range a.Result {
+			if !strings.HasSuffix(r.Name, suffix) {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			keys = append(keys, r.Name)
+			if limit > 0 && uint(len(keys)) == limit {
+				return keys, nil
+			}
+		}
+
+		cursor = a.ResultInfo.Cursor
+		if len(cursor) == 0 {
+			return keys, nil
+		}
+	}
+}
+
+func (w *workersKV) read(keys ...string) ([]*store.Record, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -145,6 +226,9 @@ func (w *workersKV) Read(keys ...string) ([]*store.Record, error) {
 		if err != nil {
 			return records, err
 		}
+		if status == http.StatusNotFound {
+			return records, store.ErrNotFound
+		}
 		if status < 200 || status >= 300 {
 			return records, errors.New("Received unexpected Status " + strconv.Itoa(status) + string(response))
 		}
@@ -229,6 +313,26 @@ func (w *workersKV) Delete(keys ...string) error {
 	return nil
 }
 
+// Batch is not supported as workers KV is eventually
+// consistent and has no transactions.
+func (w *workersKV) Batch(ops ...*store.Op) error {
+	return store.ErrNotSupported
+}
+
+// Watch polls the namespace for changes as the workers
+// KV API offers no way to subscribe to updates.
+func (w *workersKV) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var wo store.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	return newWatcher(w, wo)
+}
+
+func (w *workersKV) String() string {
+	return "cloudflare"
+}
+
 func (w *workersKV) request(ctx context.Context, method, path string, body interface{}, headers http.Header) ([]byte, http.Header, int, error) {
 	var jsonBody []byte
 	var err error
@@ -328,11 +432,21 @@ func NewStore(opts ...options.Option) store.Store {
 	// validate options are not blank or log.Fatal
 	validateOptions(account, token, namespace)
 
+	watchInterval := DefaultWatchInterval
+	if v, ok := options.Values().Get("store.cf.watch_interval"); ok {
+		d, ok := v.(time.Duration)
+		if !ok {
+			log.Fatal("Store: Option store.cf.watch_interval contains a non-duration")
+		}
+		watchInterval = d
+	}
+
 	return &workersKV{
-		account:    account,
-		namespace:  namespace,
-		token:      token,
-		Options:    options,
-		httpClient: &http.Client{},
+		account:       account,
+		namespace:     namespace,
+		token:         token,
+		Options:       options,
+		httpClient:    &http.Client{},
+		watchInterval: watchInterval,
 	}
 }
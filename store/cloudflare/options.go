@@ -1,6 +1,8 @@
 package cloudflare
 
 import (
+	"time"
+
 	"github.com/micro/go-micro/config/options"
 )
 
@@ -21,3 +23,8 @@ func Namespace(ns string) options.Option {
 	// TODO: change to store.cf.namespace
 	return options.WithValue("KV_NAMESPACE_ID", ns)
 }
+
+// WatchInterval sets how often watchers poll the KV namespace for changes
+func WatchInterval(d time.Duration) options.Option {
+	return options.WithValue("store.cf.watch_interval", d)
+}
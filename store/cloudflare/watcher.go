@@ -0,0 +1,110 @@
+package cloudflare
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/micro/go-micro/store"
+)
+
+// watcher polls the workers KV namespace and diffs
+// each snapshot against the previous one to emit events
+type watcher struct {
+	w    *workersKV
+	wo   store.WatchOptions
+	res  chan *store.Event
+	exit chan bool
+}
+
+func newWatcher(w *workersKV, wo store.WatchOptions) (store.Watcher, error) {
+	// take the initial snapshot so only subsequent changes are emitted
+	records, err := w.List(store.ListPrefix(wo.Prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	cw := &watcher{
+		w:    w,
+		wo:   wo,
+		res:  make(chan *store.Event),
+		exit: make(chan bool),
+	}
+
+	go cw.run(snapshot(records))
+
+	return cw, nil
+}
+
+func snapshot(records []*store.Record) map[string]*store.Record {
+	snap := make(map[string]*store.Record, len(records))
+	for _, r := range records {
+		snap[r.Key] = r
+	}
+	return snap
+}
+
+func (cw *watcher) run(last map[string]*store.Record) {
+	t := time.NewTicker(cw.w.watchInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-cw.exit:
+			return
+		case <-t.C:
+		}
+
+		records, err := cw.w.List(store.ListPrefix(cw.wo.Prefix))
+		if err != nil {
+			// try again on the next tick
+			continue
+		}
+		next := snapshot(records)
+
+		var events []*store.Event
+
+		for k, r := range next {
+			prev, ok := last[k]
+			switch {
+			case !ok:
+				events = append(events, &store.Event{Type: store.Create, Timestamp: time.Now(), Record: r})
+			case !bytes.Equal(prev.Value, r.Value):
+				events = append(events, &store.Event{Type: store.Update, Timestamp: time.Now(), Record: r})
+			}
+		}
+
+		for k := range last {
+			if _, ok := next[k]; !ok {
+				events = append(events, &store.Event{Type: store.Delete, Timestamp: time.Now(), Record: &store.Record{Key: k}})
+			}
+		}
+
+		last = next
+
+		for _, e := range events {
+			select {
+			case cw.res <- e:
+			case <-cw.exit:
+				return
+			}
+		}
+	}
+}
+
+func (cw *watcher) Next() (*store.Event, error) {
+	select {
+	case e := <-cw.res:
+		return e, nil
+	case <-cw.exit:
+		return nil, store.ErrWatcherStopped
+	}
+}
+
+func (cw *watcher) Stop() {
+	select {
+	case <-cw.exit:
+		return
+	default:
+		close(cw.exit)
+	}
+}
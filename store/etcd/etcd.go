@@ -4,6 +4,7 @@ package etcd
 import (
 	"context"
 	"log"
+	"strings"
 
 	"github.com/micro/go-micro/config/options"
 	"github.com/micro/go-micro/store"
@@ -13,14 +14,26 @@ import (
 
 type ekv struct {
 	options.Options
-	kv client.KV
+	client *client.Client
+	kv     client.KV
 }
 
-func (e *ekv) Read(keys ...string) ([]*store.Record, error) {
-	//nolint:prealloc
-	var values []*mvccpb.KeyValue
+func (e *ekv) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
 
-	for _, key := range keys {
+	var prefix, suffix string
+
+	switch {
+	case options.Prefix && options.Suffix:
+		prefix, suffix = key, key
+	case options.Prefix:
+		prefix = key
+	case options.Suffix:
+		suffix = key
+	default:
 		keyval, err := e.kv.Get(context.Background(), key)
 		if err != nil {
 			return nil, err
@@ -30,20 +43,10 @@ func (e *ekv) Read(keys ...string) ([]*store.Record, error) {
 			return nil, store.ErrNotFound
 		}
 
-		values = append(values, keyval.Kvs...)
-	}
-
-	records := make([]*store.Record, 0, len(values))
-
-	for _, kv := range values {
-		records = append(records, &store.Record{
-			Key:   string(kv.Key),
-			Value: kv.Value,
-			// TODO: implement expiry
-		})
+		return toRecords(keyval.Kvs), nil
 	}
 
-	return records, nil
+	return e.find(prefix, suffix, options.Offset, options.Limit)
 }
 
 func (e *ekv) Delete(keys ...string) error {
@@ -69,22 +72,142 @@ func (e *ekv) Write(records ...*store.Record) error {
 	return gerr
 }
 
-func (e *ekv) List() ([]*store.Record, error) {
-	keyval, err := e.kv.Get(context.Background(), "/", client.WithPrefix())
+// last returns the last operation on each key in the order the keys
+// first appear. etcd rejects transactions changing a key twice and
+// applying only the last operation on a key has the same result.
+func last(ops []*store.Op) []*store.Op {
+	index := make(map[string]int, len(ops))
+	merged := make([]*store.Op, 0, len(ops))
+
+	for _, op := range ops {
+		if i, ok := index[op.Record.Key]; ok {
+			merged[i] = op
+			continue
+		}
+		index[op.Record.Key] = len(merged)
+		merged = append(merged, op)
+	}
+
+	return merged
+}
+
+func (e *ekv) Batch(ops ...*store.Op) error {
+	cmps := make([]client.Cmp, 0, len(ops))
+
+	// conditions are checked against the records before the batch
+	for _, op := range ops {
+		key := op.Record.Key
+
+		switch op.Condition {
+		case store.CondAbsent:
+			cmps = append(cmps, client.Compare(client.CreateRevision(key), "=", 0))
+		case store.CondVersion:
+			cmps = append(cmps, client.Compare(client.ModRevision(key), "=", int64(op.Version)))
+		}
+	}
+
+	merged := last(ops)
+	txnOps := make([]client.Op, 0, len(merged))
+
+	for _, op := range merged {
+		switch op.Type {
+		case store.OpWrite:
+			txnOps = append(txnOps, client.OpPut(op.Record.Key, string(op.Record.Value)))
+		case store.OpDelete:
+			txnOps = append(txnOps, client.OpDelete(op.Record.Key))
+		}
+	}
+
+	rsp, err := e.kv.Txn(context.Background()).If(cmps...).Then(txnOps...).Commit()
+	if err != nil {
+		return err
+	}
+	if !rsp.Succeeded {
+		return store.ErrConflict
+	}
+
+	return nil
+}
+
+func (e *ekv) List(opts ...store.ListOption) ([]*store.Record, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := options.Prefix
+	if len(prefix) == 0 {
+		prefix = "/"
+	}
+
+	return e.find(prefix, options.Suffix, options.Offset, options.Limit)
+}
+
+func (e *ekv) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var wo store.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	return newWatcher(e.client, wo)
+}
+
+// find returns the keys matching prefix and suffix sorted by key
+func (e *ekv) find(prefix, suffix string, offset, limit uint) ([]*store.Record, error) {
+	getOpts := []client.OpOption{
+		client.WithSort(client.SortByKey, client.SortAscend),
+	}
+
+	if len(prefix) > 0 {
+		getOpts = append(getOpts, client.WithPrefix())
+	} else {
+		// an empty key with the from key option returns all keys
+		getOpts = append(getOpts, client.WithFromKey())
+	}
+
+	// without a suffix filter etcd can apply the limit itself
+	if len(suffix) == 0 && limit > 0 {
+		getOpts = append(getOpts, client.WithLimit(int64(offset+limit)))
+	}
+
+	keyval, err := e.kv.Get(context.Background(), prefix, getOpts...)
 	if err != nil {
 		return nil, err
 	}
 	if keyval == nil || len(keyval.Kvs) == 0 {
 		return nil, nil
 	}
-	vals := make([]*store.Record, 0, len(keyval.Kvs))
-	for _, keyv := range keyval.Kvs {
-		vals = append(vals, &store.Record{
-			Key:   string(keyv.Key),
-			Value: keyv.Value,
+
+	kvs := make([]*mvccpb.KeyValue, 0, len(keyval.Kvs))
+	for _, kv := range keyval.Kvs {
+		if !strings.HasSuffix(string(kv.Key), suffix) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		kvs = append(kvs, kv)
+		if limit > 0 && uint(len(kvs)) == limit {
+			break
+		}
+	}
+
+	return toRecords(kvs), nil
+}
+
+func toRecords(kvs []*mvccpb.KeyValue) []*store.Record {
+	records := make([]*store.Record, 0, len(kvs))
+
+	for _, kv := range kvs {
+		records = append(records, &store.Record{
+			Key:     string(kv.Key),
+			Value:   kv.Value,
+			Version: uint64(kv.ModRevision),
+			// TODO: implement expiry
 		})
 	}
-	return vals, nil
+
+	return records
 }
 
 func (e *ekv) String() string {
@@ -114,6 +237,7 @@ func NewStore(opts ...options.Option) store.Store {
 
 	return &ekv{
 		Options: options,
+		client:  c,
 		kv:      client.NewKV(c),
 	}
 }
@@ -0,0 +1,28 @@
+package etcd
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/store"
+)
+
+func TestLast(t *testing.T) {
+	ops := last([]*store.Op{
+		store.WriteOp(&store.Record{Key: "foo", Value: []byte("1")}),
+		store.WriteOp(&store.Record{Key: "bar", Value: []byte("1")}),
+		store.WriteOp(&store.Record{Key: "foo", Value: []byte("2")}),
+		store.DeleteOp("bar"),
+	})
+
+	if len(ops) != 2 {
+		t.Fatalf("Expected 2 operations got %d", len(ops))
+	}
+
+	// keys keep the order they first appear in
+	if ops[0].Record.Key != "foo" || string(ops[0].Record.Value) != "2" {
+		t.Fatalf("Expected the last write of foo got %s %s", ops[0].Record.Key, ops[0].Record.Value)
+	}
+	if ops[1].Record.Key != "bar" || ops[1].Type != store.OpDelete {
+		t.Fatalf("Expected the delete of bar got %s %v", ops[1].Record.Key, ops[1].Type)
+	}
+}
@@ -0,0 +1,86 @@
+package etcd
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/store"
+	client "go.etcd.io/etcd/clientv3"
+)
+
+type etcdWatcher struct {
+	stop chan bool
+	w    client.WatchChan
+	// events received but not yet returned by Next
+	events []*store.Event
+}
+
+func newWatcher(c *client.Client, wo store.WatchOptions) (store.Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := make(chan bool, 1)
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	var opts []client.OpOption
+
+	if len(wo.Prefix) > 0 {
+		opts = append(opts, client.WithPrefix())
+	} else {
+		opts = append(opts, client.WithFromKey())
+	}
+
+	return &etcdWatcher{
+		stop: stop,
+		w:    c.Watch(ctx, wo.Prefix, opts...),
+	}, nil
+}
+
+func (ew *etcdWatcher) Next() (*store.Event, error) {
+	for len(ew.events) == 0 {
+		wresp, ok := <-ew.w
+		if !ok {
+			return nil, store.ErrWatcherStopped
+		}
+		if wresp.Err() != nil {
+			return nil, wresp.Err()
+		}
+
+		for _, ev := range wresp.Events {
+			event := &store.Event{
+				Timestamp: time.Now(),
+				Record: &store.Record{
+					Key: string(ev.Kv.Key),
+				},
+			}
+
+			switch ev.Type {
+			case client.EventTypePut:
+				event.Type = store.Update
+				if ev.IsCreate() {
+					event.Type = store.Create
+				}
+				event.Record.Value = ev.Kv.Value
+			case client.EventTypeDelete:
+				event.Type = store.Delete
+			}
+
+			ew.events = append(ew.events, event)
+		}
+	}
+
+	event := ew.events[0]
+	ew.events = ew.events[1:]
+	return event, nil
+}
+
+func (ew *etcdWatcher) Stop() {
+	select {
+	case <-ew.stop:
+		return
+	default:
+		close(ew.stop)
+	}
+}
@@ -0,0 +1,709 @@
+// Package file is a durable single node store backed by an append-only log
+package file
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/config/options"
+	"github.com/micro/go-micro/store"
+)
+
+var (
+	// DefaultDir is the directory the store files are kept in
+	DefaultDir = filepath.Join(os.TempDir(), "micro", "store")
+	// DefaultNamespace is the file name used if no namespace is provided
+	DefaultNamespace = "micro"
+	// DefaultCompactInterval is how often the log is checked for compaction
+	DefaultCompactInterval = time.Minute
+
+	sendEventTime = 10 * time.Millisecond
+
+	// ErrClosed is returned when the store is closed during an operation
+	ErrClosed = errors.New("store closed")
+)
+
+type fileStore struct {
+	options.Options
+
+	sync.RWMutex
+	path string
+	// how often the log is checked for compaction
+	interval time.Duration
+	// closed to stop compacting the log
+	exit chan bool
+	// the log, opened on first use
+	file *os.File
+	// end of the log
+	size int64
+	// bytes used by deleted, overwritten and expired records
+	garbage int64
+	noSync  bool
+	index   map[string]*entry
+	// last version handed out
+	version uint64
+
+	watchers map[*fileWatcher]bool
+}
+
+// entry locates the latest value of a key in the log
+type entry struct {
+	// offset of the record in the log
+	offset int64
+	// size of the record in the log
+	length int64
+	// offset of the value within the record
+	valueOffset int64
+	valueSize   int
+	expiry      time.Time
+	version     uint64
+}
+
+func (e *entry) expired() bool {
+	return !e.expiry.IsZero() && time.Now().After(e.expiry)
+}
+
+// open opens the log and rebuilds the index from it
+func (f *fileStore) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]*entry)
+	r := bufio.NewReader(file)
+
+	var offset, garbage int64
+
+	for {
+		rec, err := decodeRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// a partially written record at the tail of the log means
+			// we crashed mid write, drop it and carry on from there
+			log.Printf("Store: truncating %s at offset %d: %v", f.path, offset, err)
+			if err := file.Truncate(offset); err != nil {
+				file.Close()
+				return err
+			}
+			break
+		}
+
+		if rec.version > f.version {
+			f.version = rec.version
+		}
+
+		if rec.op == opMeta {
+			offset += rec.size()
+			continue
+		}
+
+		if old, ok := index[rec.key]; ok {
+			garbage += old.length
+			delete(index, rec.key)
+		}
+
+		switch rec.op {
+		case opPut:
+			e := &entry{
+				offset:      offset,
+				length:      rec.size(),
+				valueOffset: rec.valueOffset(),
+				valueSize:   len(rec.value),
+				expiry:      rec.expiry,
+				version:     rec.version,
+			}
+			if e.expired() {
+				garbage += e.length
+			} else {
+				index[rec.key] = e
+			}
+		case opDelete:
+			garbage += rec.size()
+		}
+
+		offset += rec.size()
+	}
+
+	f.file = file
+	f.size = offset
+	f.garbage = garbage
+	f.index = index
+
+	return nil
+}
+
+// connect opens the log unless it's already open and
+// starts compacting it in the background
+func (f *fileStore) connect() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file != nil {
+		return nil
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.exit = make(chan bool)
+	go f.run(f.interval, f.exit)
+
+	return nil
+}
+
+// rlock read locks the store once the log is open
+func (f *fileStore) rlock() error {
+	if err := f.connect(); err != nil {
+		return err
+	}
+	f.RLock()
+	if f.file == nil {
+		f.RUnlock()
+		return ErrClosed
+	}
+	return nil
+}
+
+// lock write locks the store once the log is open
+func (f *fileStore) lock() error {
+	if err := f.connect(); err != nil {
+		return err
+	}
+	f.Lock()
+	if f.file == nil {
+		f.Unlock()
+		return ErrClosed
+	}
+	return nil
+}
+
+// append writes the records to the end of the log.
+// The caller must hold the write lock.
+func (f *fileStore) append(recs ...*logRecord) ([]*entry, error) {
+	var buf []byte
+	entries := make([]*entry, 0, len(recs))
+	offset := f.size
+
+	for _, r := range recs {
+		buf = append(buf, r.encode()...)
+		entries = append(entries, &entry{
+			offset:      offset,
+			length:      r.size(),
+			valueOffset: r.valueOffset(),
+			valueSize:   len(r.value),
+			expiry:      r.expiry,
+			version:     r.version,
+		})
+		offset += r.size()
+	}
+
+	if _, err := f.file.WriteAt(buf, f.size); err != nil {
+		// drop anything partially written
+		f.file.Truncate(f.size)
+		return nil, err
+	}
+
+	if !f.noSync {
+		if err := f.file.Sync(); err != nil {
+			return nil, err
+		}
+	}
+
+	f.size = offset
+
+	return entries, nil
+}
+
+// get returns the record for key if it exists and has not expired.
+// The caller must hold the lock.
+func (f *fileStore) get(key string) (*store.Record, error) {
+	e, ok := f.index[key]
+	if !ok || e.expired() {
+		return nil, store.ErrNotFound
+	}
+
+	value := make([]byte, e.valueSize)
+	if _, err := f.file.ReadAt(value, e.offset+e.valueOffset); err != nil {
+		return nil, err
+	}
+
+	r := &store.Record{
+		Key:     key,
+		Value:   value,
+		Version: e.version,
+	}
+	if !e.expiry.IsZero() {
+		r.Expiry = time.Until(e.expiry)
+	}
+
+	return r, nil
+}
+
+// find returns the records matching prefix and suffix sorted by key.
+// The caller must hold the lock.
+func (f *fileStore) find(prefix, suffix string, offset, limit uint) ([]*store.Record, error) {
+	keys := make([]string, 0, len(f.index))
+	for k, e := range f.index {
+		if e.expired() || !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, suffix) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if uint(len(keys)) <= offset {
+		return nil, nil
+	}
+	keys = keys[offset:]
+	if limit > 0 && uint(len(keys)) > limit {
+		keys = keys[:limit]
+	}
+
+	records := make([]*store.Record, 0, len(keys))
+	for _, k := range keys {
+		r, err := f.get(k)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+func (f *fileStore) List(opts ...store.ListOption) ([]*store.Record, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if err := f.rlock(); err != nil {
+		return nil, err
+	}
+	defer f.RUnlock()
+
+	return f.find(options.Prefix, options.Suffix, options.Offset, options.Limit)
+}
+
+func (f *fileStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if err := f.rlock(); err != nil {
+		return nil, err
+	}
+	defer f.RUnlock()
+
+	if options.Prefix || options.Suffix {
+		var prefix, suffix string
+		if options.Prefix {
+			prefix = key
+		}
+		if options.Suffix {
+			suffix = key
+		}
+		return f.find(prefix, suffix, options.Offset, options.Limit)
+	}
+
+	r, err := f.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*store.Record{r}, nil
+}
+
+func (f *fileStore) Write(records ...*store.Record) error {
+	ops := make([]*store.Op, 0, len(records))
+	for _, r := range records {
+		ops = append(ops, store.WriteOp(r))
+	}
+	return f.Batch(ops...)
+}
+
+func (f *fileStore) Delete(keys ...string) error {
+	ops := make([]*store.Op, 0, len(keys))
+	for _, key := range keys {
+		ops = append(ops, store.DeleteOp(key))
+	}
+	return f.Batch(ops...)
+}
+
+func (f *fileStore) Batch(ops ...*store.Op) error {
+	if err := f.lock(); err != nil {
+		return err
+	}
+
+	// check every condition before applying anything
+	for _, op := range ops {
+		e, ok := f.index[op.Record.Key]
+		if ok && e.expired() {
+			ok = false
+		}
+
+		switch op.Condition {
+		case store.CondAbsent:
+			if ok {
+				f.Unlock()
+				return store.ErrConflict
+			}
+		case store.CondVersion:
+			if !ok || e.version != op.Version {
+				f.Unlock()
+				return store.ErrConflict
+			}
+		}
+	}
+
+	// keys written or deleted by the preceding ops
+	pending := make(map[string]bool)
+	exists := func(key string) bool {
+		if ok, seen := pending[key]; seen {
+			return ok
+		}
+		_, ok := f.index[key]
+		return ok
+	}
+
+	recs := make([]*logRecord, 0, len(ops))
+	for _, op := range ops {
+		r := op.Record
+
+		switch op.Type {
+		case store.OpWrite:
+			rec := &logRecord{
+				op:    opPut,
+				key:   r.Key,
+				value: r.Value,
+			}
+			if r.Expiry > time.Duration(0) {
+				rec.expiry = time.Now().Add(r.Expiry)
+			}
+			recs = append(recs, rec)
+			pending[r.Key] = true
+		case store.OpDelete:
+			// nothing to delete
+			if !exists(r.Key) {
+				continue
+			}
+			recs = append(recs, &logRecord{op: opDelete, key: r.Key})
+			pending[r.Key] = false
+		default:
+			continue
+		}
+
+		f.version++
+		recs[len(recs)-1].version = f.version
+	}
+
+	if len(recs) == 0 {
+		f.Unlock()
+		return nil
+	}
+
+	entries, err := f.append(recs...)
+	if err != nil {
+		f.Unlock()
+		return err
+	}
+
+	events := make([]*store.Event, 0, len(recs))
+
+	for i, rec := range recs {
+		old, ok := f.index[rec.key]
+		if ok {
+			f.garbage += old.length
+		}
+
+		switch rec.op {
+		case opPut:
+			typ := store.Create
+			if ok && !old.expired() {
+				typ = store.Update
+			}
+			f.index[rec.key] = entries[i]
+
+			r := &store.Record{
+				Key:     rec.key,
+				Value:   rec.value,
+				Version: rec.version,
+			}
+			if !rec.expiry.IsZero() {
+				r.Expiry = time.Until(rec.expiry)
+			}
+
+			events = append(events, &store.Event{
+				Type:      typ,
+				Timestamp: time.Now(),
+				Record:    r,
+			})
+		case opDelete:
+			f.garbage += rec.size()
+			delete(f.index, rec.key)
+
+			if ok && !old.expired() {
+				events = append(events, &store.Event{
+					Type:      store.Delete,
+					Timestamp: time.Now(),
+					Record:    &store.Record{Key: rec.key},
+				})
+			}
+		}
+	}
+
+	f.Unlock()
+
+	for _, e := range events {
+		f.sendEvent(e)
+	}
+
+	return nil
+}
+
+func (f *fileStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var wo store.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+
+	w := &fileWatcher{
+		exit: make(chan bool),
+		res:  make(chan *store.Event),
+		wo:   wo,
+	}
+
+	f.Lock()
+	f.watchers[w] = true
+	f.Unlock()
+
+	return w, nil
+}
+
+func (f *fileStore) sendEvent(e *store.Event) {
+	f.RLock()
+	watchers := make([]*fileWatcher, 0, len(f.watchers))
+	for w := range f.watchers {
+		watchers = append(watchers, w)
+	}
+	f.RUnlock()
+
+	for _, w := range watchers {
+		select {
+		case <-w.exit:
+			f.Lock()
+			delete(f.watchers, w)
+			f.Unlock()
+		default:
+			select {
+			case w.res <- e:
+			case <-time.After(sendEventTime):
+			}
+		}
+	}
+}
+
+// compact rewrites the live records to a new log once at
+// least half of the current log is taken up by garbage
+func (f *fileStore) compact() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	// expired records are garbage too
+	for k, e := range f.index {
+		if e.expired() {
+			f.garbage += e.length
+			delete(f.index, k)
+		}
+	}
+
+	if f.garbage == 0 || f.garbage < f.size/2 {
+		return nil
+	}
+
+	tmp := f.path + ".compact"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(f.index))
+	for k := range f.index {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	index := make(map[string]*entry, len(keys))
+	w := bufio.NewWriter(file)
+
+	// the dropped records may hold the highest versions
+	meta := &logRecord{op: opMeta, version: f.version}
+	if _, err := w.Write(meta.encode()); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	offset := meta.size()
+
+	for _, k := range keys {
+		e := f.index[k]
+		value := make([]byte, e.valueSize)
+		if _, err := f.file.ReadAt(value, e.offset+e.valueOffset); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return err
+		}
+
+		rec := &logRecord{op: opPut, key: k, value: value, expiry: e.expiry, version: e.version}
+		if _, err := w.Write(rec.encode()); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return err
+		}
+
+		index[k] = &entry{
+			offset:      offset,
+			length:      rec.size(),
+			valueOffset: rec.valueOffset(),
+			valueSize:   e.valueSize,
+			expiry:      e.expiry,
+			version:     e.version,
+		}
+		offset += rec.size()
+	}
+
+	if err := w.Flush(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	// atomically replace the old log
+	if err := os.Rename(tmp, f.path); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(filepath.Dir(f.path))
+
+	f.file.Close()
+	f.file = file
+	f.size = offset
+	f.garbage = 0
+	f.index = index
+
+	return nil
+}
+
+func (f *fileStore) run(interval time.Duration, exit chan bool) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			if err := f.compact(); err != nil {
+				log.Printf("Store: error compacting %s: %v", f.path, err)
+			}
+		case <-exit:
+			return
+		}
+	}
+}
+
+// Close stops compacting the log and closes it.
+// The log is opened again if the store is used.
+// It's reached through the store.Store returned
+// by NewStore by asserting it to an io.Closer.
+func (f *fileStore) Close() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	close(f.exit)
+	err := f.file.Close()
+	f.file = nil
+	f.index = nil
+
+	return err
+}
+
+// syncDir flushes a rename to disk
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+func (f *fileStore) String() string {
+	return "file"
+}
+
+// NewStore returns a store.Store backed by a file in the
+// directory set by the Dir option, named after the namespace.
+// The file is opened on first use and closed by Close, which
+// the store implements as an io.Closer.
+func NewStore(opts ...options.Option) store.Store {
+	options := options.NewOptions(opts...)
+
+	dir := DefaultDir
+	if d, ok := options.Values().Get("store.file.dir"); ok {
+		dir = d.(string)
+	}
+
+	namespace := DefaultNamespace
+	if n, ok := options.Values().Get("store.namespace"); ok {
+		namespace = n.(string)
+	}
+
+	interval := DefaultCompactInterval
+	if i, ok := options.Values().Get("store.file.compact_interval"); ok {
+		interval = i.(time.Duration)
+	}
+
+	var noSync bool
+	if v, ok := options.Values().Get("store.file.no_sync"); ok {
+		noSync = v.(bool)
+	}
+
+	return &fileStore{
+		Options:  options,
+		path:     filepath.Join(dir, namespace+".db"),
+		interval: interval,
+		noSync:   noSync,
+		watchers: make(map[*fileWatcher]bool),
+	}
+}
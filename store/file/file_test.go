@@ -0,0 +1,266 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/store"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func newTestStore(t *testing.T, dir string) *fileStore {
+	return NewStore(Dir(dir), NoSync(), CompactInterval(time.Hour)).(*fileStore)
+}
+
+func TestPersistence(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := newTestStore(t, dir)
+	if err := s.Write(
+		&store.Record{Key: "foo", Value: []byte("bar")},
+		&store.Record{Key: "baz", Value: []byte("qux")},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar2")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("baz"); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s = newTestStore(t, dir)
+	defer s.Close()
+
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "bar2" {
+		t.Fatalf("expected bar2 got %s", recs[0].Value)
+	}
+
+	if _, err := s.Read("baz"); err != store.ErrNotFound {
+		t.Fatalf("expected not found got %v", err)
+	}
+}
+
+func TestTruncatedLog(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := newTestStore(t, dir)
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	size := s.size
+	if err := s.Write(&store.Record{Key: "baz", Value: []byte("qux")}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// simulate a crash part way through the second write
+	if err := os.Truncate(s.path, size+5); err != nil {
+		t.Fatal(err)
+	}
+
+	s = newTestStore(t, dir)
+	defer s.Close()
+
+	if err := s.connect(); err != nil {
+		t.Fatal(err)
+	}
+	if s.size != size {
+		t.Fatalf("expected log to be truncated to %d got %d", size, s.size)
+	}
+	if _, err := s.Read("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("baz"); err != store.ErrNotFound {
+		t.Fatalf("expected not found got %v", err)
+	}
+}
+
+func TestExpiryAndCompaction(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := newTestStore(t, dir)
+	defer s.Close()
+
+	expire := 50 * time.Millisecond
+
+	if err := s.Write(
+		&store.Record{Key: "foo", Value: []byte("bar")},
+		&store.Record{Key: "tmp1", Value: []byte("tmp"), Expiry: expire},
+		&store.Record{Key: "tmp2", Value: []byte("tmp"), Expiry: expire},
+		&store.Record{Key: "tmp3", Value: []byte("tmp"), Expiry: expire},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.Read("tmp", store.ReadPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records got %d", len(recs))
+	}
+
+	time.Sleep(expire)
+
+	if _, err := s.Read("tmp1"); err != store.ErrNotFound {
+		t.Fatalf("expected not found got %v", err)
+	}
+
+	before := s.size
+	if err := s.compact(); err != nil {
+		t.Fatal(err)
+	}
+	if s.size >= before {
+		t.Fatalf("expected log to shrink from %d got %d", before, s.size)
+	}
+
+	recs, err = s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Key != "foo" || string(recs[0].Value) != "bar" {
+		t.Fatalf("unexpected records after compaction %+v", recs)
+	}
+}
+
+func TestVersionAfterCompaction(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := newTestStore(t, dir)
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := recs[0].Version
+
+	// compaction drops the records holding the highest version
+	if err := s.Delete("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.compact(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s = newTestStore(t, dir)
+	defer s.Close()
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("baz")}); err != nil {
+		t.Fatal(err)
+	}
+	recs, err = s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recs[0].Version <= old+1 {
+		t.Fatalf("expected a version after %d got %d", old+1, recs[0].Version)
+	}
+
+	// a stale writer doesn't match the recreated key
+	if err := s.Batch(store.WriteOp(&store.Record{Key: "foo"}, store.IfVersion(old))); err != store.ErrConflict {
+		t.Fatalf("expected conflict got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := newTestStore(t, dir)
+	defer s.Close()
+
+	w, err := s.Watch(store.WatchPrefix("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	go func() {
+		s.Write(&store.Record{Key: "bar", Value: []byte("bar")})
+		s.Write(&store.Record{Key: "foo", Value: []byte("foo")})
+		s.Delete("foo")
+	}()
+
+	for _, typ := range []store.EventType{store.Create, store.Delete} {
+		e, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Type != typ || e.Record.Key != "foo" {
+			t.Fatalf("expected %s foo got %s %s", typ, e.Type, e.Record.Key)
+		}
+	}
+}
+
+func TestOpenError(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// a file where the directory should be
+	path := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestStore(t, path)
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err == nil {
+		t.Fatal("expected error opening the log")
+	}
+	if _, err := s.Read("foo"); err == nil {
+		t.Fatal("expected error opening the log")
+	}
+}
+
+func TestClose(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := newTestStore(t, dir)
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+
+	exit := s.exit
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-exit:
+	default:
+		t.Fatal("expected compaction to be stopped")
+	}
+
+	// the log is opened again when used
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "bar" {
+		t.Fatalf("expected bar got %s", recs[0].Value)
+	}
+	s.Close()
+}
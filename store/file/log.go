@@ -0,0 +1,120 @@
+package file
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// Records are appended to the log in the following format
+//
+//	crc32 (4) | payload length (4) | payload
+//
+// where the payload is
+//
+//	op (1) | expiry unix nano (8) | version (8) | key length (4) | value length (4) | key | value
+//
+// all integers are big endian. A zero expiry means the record never expires.
+// Meta records have no key or value and only carry the last version handed
+// out, so versions keep increasing after compaction drops the records
+// holding the highest versions.
+
+const (
+	opPut    byte = 1
+	opDelete byte = 2
+	opMeta   byte = 3
+
+	headerSize  = 8
+	payloadHead = 25
+)
+
+var (
+	errCorrupt = errors.New("corrupt record")
+)
+
+type logRecord struct {
+	op      byte
+	key     string
+	value   []byte
+	expiry  time.Time
+	version uint64
+}
+
+// size is the number of bytes the record takes in the log
+func (r *logRecord) size() int64 {
+	return int64(headerSize + payloadHead + len(r.key) + len(r.value))
+}
+
+// valueOffset is the offset of the value from the start of the record
+func (r *logRecord) valueOffset() int64 {
+	return int64(headerSize + payloadHead + len(r.key))
+}
+
+func (r *logRecord) encode() []byte {
+	b := make([]byte, r.size())
+	p := b[headerSize:]
+
+	var expiry int64
+	if !r.expiry.IsZero() {
+		expiry = r.expiry.UnixNano()
+	}
+
+	p[0] = r.op
+	binary.BigEndian.PutUint64(p[1:9], uint64(expiry))
+	binary.BigEndian.PutUint64(p[9:17], r.version)
+	binary.BigEndian.PutUint32(p[17:21], uint32(len(r.key)))
+	binary.BigEndian.PutUint32(p[21:25], uint32(len(r.value)))
+	copy(p[payloadHead:], r.key)
+	copy(p[payloadHead+len(r.key):], r.value)
+
+	binary.BigEndian.PutUint32(b[0:4], crc32.ChecksumIEEE(p))
+	binary.BigEndian.PutUint32(b[4:8], uint32(len(p)))
+
+	return b
+}
+
+// decodeRecord reads the next record. It returns io.EOF at the end
+// of the log and errCorrupt for a partially written or damaged record.
+func decodeRecord(r io.Reader) (*logRecord, error) {
+	var h [headerSize]byte
+	if _, err := io.ReadFull(r, h[:]); err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, errCorrupt
+	}
+
+	crc := binary.BigEndian.Uint32(h[0:4])
+	size := binary.BigEndian.Uint32(h[4:8])
+	if size < payloadHead {
+		return nil, errCorrupt
+	}
+
+	p := make([]byte, size)
+	if _, err := io.ReadFull(r, p); err != nil {
+		return nil, errCorrupt
+	}
+	if crc32.ChecksumIEEE(p) != crc {
+		return nil, errCorrupt
+	}
+
+	keyLen := binary.BigEndian.Uint32(p[17:21])
+	valLen := binary.BigEndian.Uint32(p[21:25])
+	if uint64(payloadHead)+uint64(keyLen)+uint64(valLen) != uint64(size) {
+		return nil, errCorrupt
+	}
+
+	rec := &logRecord{
+		op:      p[0],
+		key:     string(p[payloadHead : payloadHead+keyLen]),
+		value:   p[payloadHead+keyLen:],
+		version: binary.BigEndian.Uint64(p[9:17]),
+	}
+
+	if expiry := int64(binary.BigEndian.Uint64(p[1:9])); expiry > 0 {
+		rec.expiry = time.Unix(0, expiry)
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,24 @@
+package file
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/config/options"
+)
+
+// Dir sets the directory the store files are kept in
+func Dir(d string) options.Option {
+	return options.WithValue("store.file.dir", d)
+}
+
+// CompactInterval sets how often the log is checked for
+// expired and overwritten records that can be compacted
+func CompactInterval(d time.Duration) options.Option {
+	return options.WithValue("store.file.compact_interval", d)
+}
+
+// NoSync disables the fsync after each write. Writes are
+// faster but may be lost if the machine crashes.
+func NoSync() options.Option {
+	return options.WithValue("store.file.no_sync", true)
+}
@@ -0,0 +1,36 @@
+package file
+
+import (
+	"strings"
+
+	"github.com/micro/go-micro/store"
+)
+
+type fileWatcher struct {
+	wo   store.WatchOptions
+	res  chan *store.Event
+	exit chan bool
+}
+
+func (w *fileWatcher) Next() (*store.Event, error) {
+	for {
+		select {
+		case e := <-w.res:
+			if !strings.HasPrefix(e.Record.Key, w.wo.Prefix) {
+				continue
+			}
+			return e, nil
+		case <-w.exit:
+			return nil, store.ErrWatcherStopped
+		}
+	}
+}
+
+func (w *fileWatcher) Stop() {
+	select {
+	case <-w.exit:
+		return
+	default:
+		close(w.exit)
+	}
+}
@@ -2,6 +2,8 @@
 package memory
 
 import (
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -9,89 +11,140 @@ import (
 	"github.com/micro/go-micro/store"
 )
 
+var (
+	sendEventTime = 10 * time.Millisecond
+)
+
 type memoryStore struct {
 	options.Options
 
 	sync.RWMutex
-	values map[string]*memoryRecord
+	values   map[string]*memoryRecord
+	watchers map[*memoryWatcher]bool
+	// last version handed out
+	version uint64
 }
 
 type memoryRecord struct {
 	r *store.Record
 	c time.Time
+	v uint64
 }
 
-func (m *memoryStore) List() ([]*store.Record, error) {
-	m.RLock()
-	defer m.RUnlock()
-
-	//nolint:prealloc
-	var values []*store.Record
+// get returns the record if it has not expired.
+// The caller must hold the lock.
+func (m *memoryStore) get(key string) (*store.Record, bool) {
+	v, ok := m.values[key]
+	if !ok {
+		return nil, false
+	}
 
-	for _, v := range m.values {
-		// get expiry
-		d := v.r.Expiry
-		t := time.Since(v.c)
+	// get expiry
+	d := v.r.Expiry
+	t := time.Since(v.c)
 
-		if d > time.Duration(0) {
-			// expired
-			if t > d {
-				continue
-			}
-			// update expiry
-			v.r.Expiry -= t
-			v.c = time.Now()
+	if d > time.Duration(0) {
+		// expired
+		if t > d {
+			return nil, false
 		}
-
-		values = append(values, v.r)
+		// update expiry
+		v.r.Expiry -= t
+		v.c = time.Now()
 	}
 
-	return values, nil
+	v.r.Version = v.v
+
+	return v.r, true
 }
 
-func (m *memoryStore) Read(keys ...string) ([]*store.Record, error) {
-	m.RLock()
-	defer m.RUnlock()
+// find returns the records matching prefix and suffix sorted by key.
+// The caller must hold the lock.
+func (m *memoryStore) find(prefix, suffix string, offset, limit uint) []*store.Record {
+	keys := make([]string, 0, len(m.values))
+	for k := range m.values {
+		if !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, suffix) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 
 	//nolint:prealloc
 	var records []*store.Record
 
-	for _, key := range keys {
-		v, ok := m.values[key]
+	for _, k := range keys {
+		r, ok := m.get(k)
 		if !ok {
-			return nil, store.ErrNotFound
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		records = append(records, r)
+		if limit > 0 && uint(len(records)) == limit {
+			break
 		}
+	}
 
-		// get expiry
-		d := v.r.Expiry
-		t := time.Since(v.c)
+	return records
+}
 
-		// expired
-		if d > time.Duration(0) {
-			if t > d {
-				return nil, store.ErrNotFound
-			}
-			// update expiry
-			v.r.Expiry -= t
-			v.c = time.Now()
+func (m *memoryStore) List(opts ...store.ListOption) ([]*store.Record, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// find takes a write lock as reading updates the expiry
+	m.Lock()
+	defer m.Unlock()
+
+	return m.find(options.Prefix, options.Suffix, options.Offset, options.Limit), nil
+}
+
+func (m *memoryStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if options.Prefix || options.Suffix {
+		var prefix, suffix string
+		if options.Prefix {
+			prefix = key
+		}
+		if options.Suffix {
+			suffix = key
 		}
+		return m.find(prefix, suffix, options.Offset, options.Limit), nil
+	}
 
-		records = append(records, v.r)
+	r, ok := m.get(key)
+	if !ok {
+		return nil, store.ErrNotFound
 	}
 
-	return records, nil
+	return []*store.Record{r}, nil
 }
 
 func (m *memoryStore) Write(records ...*store.Record) error {
 	m.Lock()
-	defer m.Unlock()
+
+	events := make([]*store.Event, 0, len(records))
 
 	for _, r := range records {
-		// set the record
-		m.values[r.Key] = &memoryRecord{
-			r: r,
-			c: time.Now(),
-		}
+		events = append(events, m.set(r))
+	}
+
+	m.Unlock()
+
+	for _, e := range events {
+		m.sendEvent(e)
 	}
 
 	return nil
@@ -99,22 +152,164 @@ func (m *memoryStore) Write(records ...*store.Record) error {
 
 func (m *memoryStore) Delete(keys ...string) error {
 	m.Lock()
-	defer m.Unlock()
+
+	events := make([]*store.Event, 0, len(keys))
 
 	for _, key := range keys {
-		// delete the value
-		delete(m.values, key)
+		if e := m.remove(key); e != nil {
+			events = append(events, e)
+		}
+	}
+
+	m.Unlock()
+
+	for _, e := range events {
+		m.sendEvent(e)
+	}
+
+	return nil
+}
+
+func (m *memoryStore) Batch(ops ...*store.Op) error {
+	m.Lock()
+
+	// check every condition before applying anything
+	for _, op := range ops {
+		r, ok := m.get(op.Record.Key)
+
+		switch op.Condition {
+		case store.CondAbsent:
+			if ok {
+				m.Unlock()
+				return store.ErrConflict
+			}
+		case store.CondVersion:
+			if !ok || r.Version != op.Version {
+				m.Unlock()
+				return store.ErrConflict
+			}
+		}
+	}
+
+	events := make([]*store.Event, 0, len(ops))
+
+	for _, op := range ops {
+		switch op.Type {
+		case store.OpWrite:
+			events = append(events, m.set(op.Record))
+		case store.OpDelete:
+			if e := m.remove(op.Record.Key); e != nil {
+				events = append(events, e)
+			}
+		}
+	}
+
+	m.Unlock()
+
+	for _, e := range events {
+		m.sendEvent(e)
 	}
 
 	return nil
 }
 
+// set writes the record and returns the change event.
+// The caller must hold the lock.
+func (m *memoryStore) set(r *store.Record) *store.Event {
+	typ := store.Create
+	if _, ok := m.get(r.Key); ok {
+		typ = store.Update
+	}
+
+	// every write gets a new version
+	m.version++
+
+	// set the record
+	m.values[r.Key] = &memoryRecord{
+		r: r,
+		c: time.Now(),
+		v: m.version,
+	}
+
+	return &store.Event{
+		Type:      typ,
+		Timestamp: time.Now(),
+		Record:    r,
+	}
+}
+
+// remove deletes the key and returns the change event
+// or nil if it did not exist. The caller must hold the lock.
+func (m *memoryStore) remove(key string) *store.Event {
+	_, ok := m.get(key)
+
+	// delete the value
+	delete(m.values, key)
+
+	if !ok {
+		return nil
+	}
+
+	return &store.Event{
+		Type:      store.Delete,
+		Timestamp: time.Now(),
+		Record:    &store.Record{Key: key},
+	}
+}
+
+func (m *memoryStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var wo store.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+
+	w := &memoryWatcher{
+		exit: make(chan bool),
+		res:  make(chan *store.Event),
+		wo:   wo,
+	}
+
+	m.Lock()
+	m.watchers[w] = true
+	m.Unlock()
+
+	return w, nil
+}
+
+func (m *memoryStore) sendEvent(e *store.Event) {
+	m.RLock()
+	watchers := make([]*memoryWatcher, 0, len(m.watchers))
+	for w := range m.watchers {
+		watchers = append(watchers, w)
+	}
+	m.RUnlock()
+
+	for _, w := range watchers {
+		select {
+		case <-w.exit:
+			m.Lock()
+			delete(m.watchers, w)
+			m.Unlock()
+		default:
+			select {
+			case w.res <- e:
+			case <-time.After(sendEventTime):
+			}
+		}
+	}
+}
+
+func (m *memoryStore) String() string {
+	return "memory"
+}
+
 // NewStore returns a new store.Store
 func NewStore(opts ...options.Option) store.Store {
 	options := options.NewOptions(opts...)
 
 	return &memoryStore{
-		Options: options,
-		values:  make(map[string]*memoryRecord),
+		Options:  options,
+		values:   make(map[string]*memoryRecord),
+		watchers: make(map[*memoryWatcher]bool),
 	}
 }
@@ -35,3 +35,129 @@ func TestReadRecordExpire(t *testing.T) {
 		t.Fatal("expire elapsed, but key still accessable")
 	}
 }
+
+func TestReadPrefixLimitOffset(t *testing.T) {
+	s := NewStore()
+
+	for _, k := range []string{"foo/c", "foo/a", "foo/b", "bar/a"} {
+		if err := s.Write(&store.Record{Key: k, Value: []byte(k)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recs, err := s.Read("foo/", store.ReadPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(recs))
+	}
+	for i, k := range []string{"foo/a", "foo/b", "foo/c"} {
+		if recs[i].Key != k {
+			t.Fatalf("expected key %s at %d, got %s", k, i, recs[i].Key)
+		}
+	}
+
+	recs, err = s.Read("/a", store.ReadSuffix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+
+	recs, err = s.List(store.ListPrefix("foo/"), store.ListOffset(1), store.ListLimit(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Key != "foo/b" {
+		t.Fatalf("expected foo/b, got %+v", recs)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	s := NewStore()
+
+	w, err := s.Watch(store.WatchPrefix("foo/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	events := make(chan *store.Event, 3)
+	go func() {
+		for {
+			e, err := w.Next()
+			if err != nil {
+				close(events)
+				return
+			}
+			events <- e
+		}
+	}()
+
+	// wait for the watcher to start
+	time.Sleep(10 * time.Millisecond)
+
+	s.Write(&store.Record{Key: "bar/a"})
+	s.Write(&store.Record{Key: "foo/a"})
+	s.Write(&store.Record{Key: "foo/a"})
+	s.Delete("foo/a")
+
+	for _, typ := range []store.EventType{store.Create, store.Update, store.Delete} {
+		select {
+		case e := <-events:
+			if e.Type != typ {
+				t.Fatalf("expected %s event, got %s", typ, e.Type)
+			}
+			if e.Record.Key != "foo/a" {
+				t.Fatalf("expected key foo/a, got %s", e.Record.Key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s event", typ)
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	s := NewStore()
+
+	if err := s.Batch(store.WriteOp(&store.Record{Key: "foo", Value: []byte("bar")}, store.IfAbsent())); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Batch(store.WriteOp(&store.Record{Key: "foo", Value: []byte("baz")}, store.IfAbsent())); err != store.ErrConflict {
+		t.Fatalf("expected conflict got %v", err)
+	}
+
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := recs[0].Version
+
+	// a stale version fails the whole batch
+	err = s.Batch(
+		store.WriteOp(&store.Record{Key: "bar", Value: []byte("bar")}),
+		store.WriteOp(&store.Record{Key: "foo", Value: []byte("baz")}, store.IfVersion(version+1)),
+	)
+	if err != store.ErrConflict {
+		t.Fatalf("expected conflict got %v", err)
+	}
+	if _, err := s.Read("bar"); err != store.ErrNotFound {
+		t.Fatalf("expected batch not to be applied got %v", err)
+	}
+
+	err = s.Batch(
+		store.WriteOp(&store.Record{Key: "bar", Value: []byte("bar")}),
+		store.DeleteOp("foo", store.IfVersion(version)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("foo"); err != store.ErrNotFound {
+		t.Fatalf("expected foo to be deleted got %v", err)
+	}
+	if _, err := s.Read("bar"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/micro/go-micro/store"
+)
+
+type memoryWatcher struct {
+	wo   store.WatchOptions
+	res  chan *store.Event
+	exit chan bool
+}
+
+func (m *memoryWatcher) Next() (*store.Event, error) {
+	for {
+		select {
+		case e := <-m.res:
+			if !strings.HasPrefix(e.Record.Key, m.wo.Prefix) {
+				continue
+			}
+			return e, nil
+		case <-m.exit:
+			return nil, store.ErrWatcherStopped
+		}
+	}
+}
+
+func (m *memoryWatcher) Stop() {
+	select {
+	case <-m.exit:
+		return
+	default:
+		close(m.exit)
+	}
+}
@@ -10,6 +10,26 @@ type Store struct {
 	mock.Mock
 }
 
+// Batch provides a mock function with given fields: ops
+func (_m *Store) Batch(ops ...*store.Op) error {
+	_va := make([]interface{}, len(ops))
+	for _i := range ops {
+		_va[_i] = ops[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...*store.Op) error); ok {
+		r0 = rf(ops...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: key
 func (_m *Store) Delete(key ...string) error {
 	_va := make([]interface{}, len(key))
@@ -30,13 +50,19 @@ func (_m *Store) Delete(key ...string) error {
 	return r0
 }
 
-// List provides a mock function with given fields:
-func (_m *Store) List() ([]*store.Record, error) {
-	ret := _m.Called()
+// List provides a mock function with given fields: opts
+func (_m *Store) List(opts ...store.ListOption) ([]*store.Record, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 []*store.Record
-	if rf, ok := ret.Get(0).(func() []*store.Record); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(...store.ListOption) []*store.Record); ok {
+		r0 = rf(opts...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*store.Record)
@@ -44,8 +70,8 @@ func (_m *Store) List() ([]*store.Record, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(...store.ListOption) error); ok {
+		r1 = rf(opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -53,19 +79,20 @@ func (_m *Store) List() ([]*store.Record, error) {
 	return r0, r1
 }
 
-// Read provides a mock function with given fields: key
-func (_m *Store) Read(key ...string) ([]*store.Record, error) {
-	_va := make([]interface{}, len(key))
-	for _i := range key {
-		_va[_i] = key[_i]
+// Read provides a mock function with given fields: key, opts
+func (_m *Store) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
 	}
 	var _ca []interface{}
+	_ca = append(_ca, key)
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
 	var r0 []*store.Record
-	if rf, ok := ret.Get(0).(func(...string) []*store.Record); ok {
-		r0 = rf(key...)
+	if rf, ok := ret.Get(0).(func(string, ...store.ReadOption) []*store.Record); ok {
+		r0 = rf(key, opts...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*store.Record)
@@ -73,8 +100,37 @@ func (_m *Store) Read(key ...string) ([]*store.Record, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(...string) error); ok {
-		r1 = rf(key...)
+	if rf, ok := ret.Get(1).(func(string, ...store.ReadOption) error); ok {
+		r1 = rf(key, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Watch provides a mock function with given fields: opts
+func (_m *Store) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 store.Watcher
+	if rf, ok := ret.Get(0).(func(...store.WatchOption) store.Watcher); ok {
+		r0 = rf(opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.Watcher)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(...store.WatchOption) error); ok {
+		r1 = rf(opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -19,3 +19,105 @@ func Prefix(p string) options.Option {
 func Namespace(n string) options.Option {
 	return options.WithValue("store.namespace", n)
 }
+
+// ReadOptions configures an individual Read operation
+type ReadOptions struct {
+	// Prefix returns all records that are prefixed with key
+	Prefix bool
+	// Suffix returns all records that have the suffix key
+	Suffix bool
+	// Limit limits the number of returned records
+	Limit uint
+	// Offset when combined with Limit supports pagination
+	Offset uint
+}
+
+// ReadOption sets values in ReadOptions
+type ReadOption func(r *ReadOptions)
+
+// ReadPrefix returns all records that are prefixed with key
+func ReadPrefix() ReadOption {
+	return func(r *ReadOptions) {
+		r.Prefix = true
+	}
+}
+
+// ReadSuffix returns all records that have the suffix key
+func ReadSuffix() ReadOption {
+	return func(r *ReadOptions) {
+		r.Suffix = true
+	}
+}
+
+// ReadLimit limits the number of responses to l
+func ReadLimit(l uint) ReadOption {
+	return func(r *ReadOptions) {
+		r.Limit = l
+	}
+}
+
+// ReadOffset starts returning responses from o. Use in conjunction with Limit for pagination
+func ReadOffset(o uint) ReadOption {
+	return func(r *ReadOptions) {
+		r.Offset = o
+	}
+}
+
+// ListOptions configures an individual List operation
+type ListOptions struct {
+	// Prefix returns all records that are prefixed with the value
+	Prefix string
+	// Suffix returns all records that have the suffix value
+	Suffix string
+	// Limit limits the number of returned records
+	Limit uint
+	// Offset when combined with Limit supports pagination
+	Offset uint
+}
+
+// ListOption sets values in ListOptions
+type ListOption func(l *ListOptions)
+
+// ListPrefix returns all records that are prefixed with p
+func ListPrefix(p string) ListOption {
+	return func(l *ListOptions) {
+		l.Prefix = p
+	}
+}
+
+// ListSuffix returns all records that have the suffix s
+func ListSuffix(s string) ListOption {
+	return func(l *ListOptions) {
+		l.Suffix = s
+	}
+}
+
+// ListLimit limits the number of returned records to l
+func ListLimit(l uint) ListOption {
+	return func(lo *ListOptions) {
+		lo.Limit = l
+	}
+}
+
+// ListOffset starts returning records from o. Use in conjunction with Limit for pagination
+func ListOffset(o uint) ListOption {
+	return func(l *ListOptions) {
+		l.Offset = o
+	}
+}
+
+// WatchOptions configures a store watcher
+type WatchOptions struct {
+	// Prefix watches only records prefixed with the value
+	Prefix string
+}
+
+// WatchOption sets values in WatchOptions
+type WatchOption func(w *WatchOptions)
+
+// WatchPrefix watches records that are prefixed with p
+func WatchPrefix(p string) WatchOption {
+	return func(w *WatchOptions) {
+		w.Prefix = p
+	}
+}
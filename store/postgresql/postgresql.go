@@ -21,20 +21,102 @@ const DefaultNamespace = "micro"
 
 type sqlStore struct {
 	db *sql.DB
+	// connection string used by watchers
+	dsn string
 
 	table string
 	options.Options
 }
 
 // List all the known records
-func (s *sqlStore) List() ([]*store.Record, error) {
-	q, err := s.db.Prepare(fmt.Sprintf("SELECT key, value, expiry FROM micro.%s;", s.table))
+func (s *sqlStore) List(opts ...store.ListOption) ([]*store.Record, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var patterns []string
+	if len(options.Prefix) > 0 {
+		patterns = append(patterns, escapeLike(options.Prefix)+"%")
+	}
+	if len(options.Suffix) > 0 {
+		patterns = append(patterns, "%"+escapeLike(options.Suffix))
+	}
+
+	return s.find(patterns, options.Offset, options.Limit)
+}
+
+// Read all records with keys
+func (s *sqlStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Prefix || options.Suffix {
+		var patterns []string
+		if options.Prefix {
+			patterns = append(patterns, escapeLike(key)+"%")
+		}
+		if options.Suffix {
+			patterns = append(patterns, "%"+escapeLike(key))
+		}
+		return s.find(patterns, options.Offset, options.Limit)
+	}
+
+	q, err := s.db.Prepare(fmt.Sprintf("SELECT key, value, expiry, version FROM micro.%s WHERE key = $1;", s.table))
+	if err != nil {
+		return nil, err
+	}
+	var records []*store.Record
+	var timehelper pq.NullTime
+	row := q.QueryRow(key)
+	record := &store.Record{}
+	if err := row.Scan(&record.Key, &record.Value, &timehelper, &record.Version); err != nil {
+		if err == sql.ErrNoRows {
+			return records, store.ErrNotFound
+		}
+		return records, err
+	}
+	if timehelper.Valid {
+		if timehelper.Time.Before(time.Now()) {
+			// record has expired
+			go s.Delete(key)
+			return records, store.ErrNotFound
+		}
+		record.Expiry = time.Until(timehelper.Time)
+		records = append(records, record)
+	} else {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// find returns the unexpired records with keys matching all the LIKE patterns
+func (s *sqlStore) find(patterns []string, offset, limit uint) ([]*store.Record, error) {
+	query := fmt.Sprintf("SELECT key, value, expiry, version FROM micro.%s WHERE (expiry IS NULL OR expiry > now())", s.table)
+	args := make([]interface{}, 0, len(patterns)+2)
+	for _, p := range patterns {
+		args = append(args, p)
+		query += fmt.Sprintf(" AND key LIKE $%d", len(args))
+	}
+	query += " ORDER BY key"
+	if limit > 0 {
+		args = append(args, int64(limit))
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if offset > 0 {
+		args = append(args, int64(offset))
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	q, err := s.db.Prepare(query + ";")
 	if err != nil {
 		return nil, err
 	}
 	var records []*store.Record
 	var timehelper pq.NullTime
-	rows, err := q.Query()
+	rows, err := q.Query(args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return records, nil
@@ -44,21 +126,13 @@ func (s *sqlStore) List() ([]*store.Record, error) {
 	defer rows.Close()
 	for rows.Next() {
 		record := &store.Record{}
-		if err := rows.Scan(&record.Key, &record.Value, &timehelper); err != nil {
+		if err := rows.Scan(&record.Key, &record.Value, &timehelper, &record.Version); err != nil {
 			return records, err
 		}
 		if timehelper.Valid {
-			if timehelper.Time.Before(time.Now()) {
-				// record has expired
-				go s.Delete(record.Key)
-			} else {
-				record.Expiry = time.Until(timehelper.Time)
-				records = append(records, record)
-			}
-		} else {
-			records = append(records, record)
+			record.Expiry = time.Until(timehelper.Time)
 		}
-
+		records = append(records, record)
 	}
 	rowErr := rows.Close()
 	if rowErr != nil {
@@ -71,56 +145,18 @@ func (s *sqlStore) List() ([]*store.Record, error) {
 	return records, nil
 }
 
-// Read all records with keys
-func (s *sqlStore) Read(keys ...string) ([]*store.Record, error) {
-	q, err := s.db.Prepare(fmt.Sprintf("SELECT key, value, expiry FROM micro.%s WHERE key = $1;", s.table))
-	if err != nil {
-		return nil, err
-	}
-	var records []*store.Record
-	var timehelper pq.NullTime
-	for _, key := range keys {
-		row := q.QueryRow(key)
-		record := &store.Record{}
-		if err := row.Scan(&record.Key, &record.Value, &timehelper); err != nil {
-			if err == sql.ErrNoRows {
-				return records, store.ErrNotFound
-			}
-			return records, err
-		}
-		if timehelper.Valid {
-			if timehelper.Time.Before(time.Now()) {
-				// record has expired
-				go s.Delete(key)
-				return records, store.ErrNotFound
-			}
-			record.Expiry = time.Until(timehelper.Time)
-			records = append(records, record)
-		} else {
-			records = append(records, record)
-		}
-	}
-	return records, nil
-}
-
 // Write records
 func (s *sqlStore) Write(rec ...*store.Record) error {
-	q, err := s.db.Prepare(fmt.Sprintf(`INSERT INTO micro.%s(key, value, expiry)
+	q, err := s.db.Prepare(fmt.Sprintf(`INSERT INTO micro.%[1]s(key, value, expiry)
 		VALUES ($1, $2::bytea, $3)
 		ON CONFLICT (key)
 		DO UPDATE
-		SET value = EXCLUDED.value, expiry = EXCLUDED.expiry;`, s.table))
+		SET value = EXCLUDED.value, expiry = EXCLUDED.expiry, version = nextval('micro.%[1]s_version');`, s.table))
 	if err != nil {
 		return err
 	}
 	for _, r := range rec {
-		var err error
-		if r.Expiry != 0 {
-			_, err = q.Exec(r.Key, r.Value, time.Now().Add(r.Expiry))
-		} else {
-			_, err = q.Exec(r.Key, r.Value, nil)
-		}
-		if err != nil {
+		if _, err := q.Exec(r.Key, r.Value, expiry(r)); err != nil {
 			return errors.Wrap(err, "Couldn't insert record "+r.Key)
 		}
 	}
@@ -128,6 +164,14 @@ func (s *sqlStore) Write(rec ...*store.Record) error {
 	return nil
 }
 
+// expiry returns the time the record expires or nil if it doesn't
+func expiry(r *store.Record) interface{} {
+	if r.Expiry != 0 {
+		return time.Now().Add(r.Expiry)
+	}
+	return nil
+}
+
 // Delete records with keys
 func (s *sqlStore) Delete(keys ...string) error {
 	q, err := s.db.Prepare(fmt.Sprintf("DELETE FROM micro.%s WHERE key = $1;", s.table))
@@ -147,6 +191,104 @@ func (s *sqlStore) Delete(keys ...string) error {
 	return nil
 }
 
+// Batch applies the operations in a single transaction. Conditions are
+// checked as part of each statement so concurrent batches conflict
+// rather than overwrite each other.
+func (s *sqlStore) Batch(ops ...*store.Op) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		if err := s.apply(tx, op); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// apply executes a single batch operation in the transaction
+func (s *sqlStore) apply(tx *sql.Tx, op *store.Op) error {
+	var query string
+	var args []interface{}
+
+	r := op.Record
+
+	switch {
+	case op.Type == store.OpWrite && op.Condition == store.CondNone:
+		query = `INSERT INTO micro.%[1]s(key, value, expiry) VALUES ($1, $2::bytea, $3)
+			ON CONFLICT (key) DO UPDATE
+			SET value = EXCLUDED.value, expiry = EXCLUDED.expiry, version = nextval('micro.%[1]s_version');`
+		args = []interface{}{r.Key, r.Value, expiry(r)}
+	case op.Type == store.OpWrite && op.Condition == store.CondAbsent:
+		// an expired record counts as absent
+		query = `INSERT INTO micro.%[1]s(key, value, expiry) VALUES ($1, $2::bytea, $3)
+			ON CONFLICT (key) DO UPDATE
+			SET value = EXCLUDED.value, expiry = EXCLUDED.expiry, version = nextval('micro.%[1]s_version')
+			WHERE micro.%[1]s.expiry IS NOT NULL AND micro.%[1]s.expiry <= now();`
+		args = []interface{}{r.Key, r.Value, expiry(r)}
+	case op.Type == store.OpWrite && op.Condition == store.CondVersion:
+		query = `UPDATE micro.%[1]s
+			SET value = $2::bytea, expiry = $3, version = nextval('micro.%[1]s_version')
+			WHERE key = $1 AND version = $4 AND (expiry IS NULL OR expiry > now());`
+		args = []interface{}{r.Key, r.Value, expiry(r), int64(op.Version)}
+	case op.Type == store.OpDelete && op.Condition == store.CondNone:
+		query = "DELETE FROM micro.%[1]s WHERE key = $1;"
+		args = []interface{}{r.Key}
+	case op.Type == store.OpDelete && op.Condition == store.CondAbsent:
+		// deleting an absent key only asserts that it is absent
+		var n int
+		row := tx.QueryRow(fmt.Sprintf("SELECT count(*) FROM micro.%s WHERE key = $1 AND (expiry IS NULL OR expiry > now());", s.table), r.Key)
+		if err := row.Scan(&n); err != nil {
+			return err
+		}
+		if n > 0 {
+			return store.ErrConflict
+		}
+		return nil
+	case op.Type == store.OpDelete && op.Condition == store.CondVersion:
+		query = "DELETE FROM micro.%[1]s WHERE key = $1 AND version = $2 AND (expiry IS NULL OR expiry > now());"
+		args = []interface{}{r.Key, int64(op.Version)}
+	default:
+		return errors.New("unknown batch operation")
+	}
+
+	result, err := tx.Exec(fmt.Sprintf(query, s.table), args...)
+	if err != nil {
+		return errors.Wrap(err, "Couldn't apply operation on record "+r.Key)
+	}
+	if op.Condition == store.CondNone {
+		return nil
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrConflict
+	}
+	return nil
+}
+
+// Watch for changes to records using postgres LISTEN/NOTIFY
+func (s *sqlStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var wo store.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	return newWatcher(s, wo)
+}
+
+// escapeLike escapes the LIKE wildcard characters in a key
+func escapeLike(key string) string {
+	return likeReplacer.Replace(key)
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (s *sqlStore) initDB(options options.Options) error {
 	// Get the store.namespace option, or use sql.DefaultNamespace
 	namespaceOpt, found := options.Values().Get("store.namespace")
@@ -170,14 +312,22 @@ func (s *sqlStore) initDB(options options.Options) error {
 		return errors.Wrap(err, "Couldn't create Schema")
 	}
 
+	// Every write takes a new version from the sequence so a
+	// deleted and recreated record never reuses an old version
+	_, err = s.db.Exec(fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS micro.%s_version;", s.table))
+	if err != nil {
+		return errors.Wrap(err, "Couldn't create version sequence")
+	}
+
 	// Create a table for the Store namespace
 	tableq, err := s.db.Prepare(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS micro.%s
 	(
 		key text COLLATE "default" NOT NULL,
 		value bytea,
 		expiry timestamp with time zone,
+		version bigint NOT NULL DEFAULT nextval('micro.%s_version'),
 		CONSTRAINT %s_pkey PRIMARY KEY (key)
-	);`, s.table, s.table))
+	);`, s.table, s.table, s.table))
 	if err != nil {
 		return errors.Wrap(err, "SQL statement preparation failed")
 	}
@@ -186,9 +336,45 @@ func (s *sqlStore) initDB(options options.Options) error {
 		return errors.Wrap(err, "Couldn't create table")
 	}
 
+	// Tables created before versioning was added
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE micro.%[1]s ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT nextval('micro.%[1]s_version');", s.table))
+	if err != nil {
+		return errors.Wrap(err, "Couldn't add version column")
+	}
+
+	// Notify watchers of changes to the table
+	_, err = s.db.Exec(fmt.Sprintf(`CREATE OR REPLACE FUNCTION micro.%[1]s_notify() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			PERFORM pg_notify('%[2]s', TG_OP || ':' || OLD.key);
+		ELSE
+			PERFORM pg_notify('%[2]s', TG_OP || ':' || NEW.key);
+		END IF;
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;`, s.table, s.channel()))
+	if err != nil {
+		return errors.Wrap(err, "Couldn't create notify function")
+	}
+	_, err = s.db.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %[1]s_notify ON micro.%[1]s;", s.table))
+	if err != nil {
+		return errors.Wrap(err, "Couldn't drop notify trigger")
+	}
+	_, err = s.db.Exec(fmt.Sprintf(`CREATE TRIGGER %[1]s_notify
+		AFTER INSERT OR UPDATE OR DELETE ON micro.%[1]s
+		FOR EACH ROW EXECUTE PROCEDURE micro.%[1]s_notify();`, s.table))
+	if err != nil {
+		return errors.Wrap(err, "Couldn't create notify trigger")
+	}
+
 	return nil
 }
 
+// channel is the notification channel for the store namespace
+func (s *sqlStore) channel() string {
+	return "micro_" + s.table
+}
+
 // New returns a new micro Store backed by sql
 func New(opts ...options.Option) (store.Store, error) {
 	options := options.NewOptions(opts...)
@@ -207,7 +393,9 @@ func New(opts ...options.Option) (store.Store, error) {
 		return nil, err
 	}
 	s := &sqlStore{
-		db: db,
+		db:      db,
+		dsn:     dataSourceName,
+		Options: options,
 	}
 
 	return s, s.initDB(options)
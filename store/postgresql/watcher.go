@@ -0,0 +1,96 @@
+package postgresql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/micro/go-micro/store"
+)
+
+type sqlWatcher struct {
+	s        *sqlStore
+	wo       store.WatchOptions
+	listener *pq.Listener
+	exit     chan bool
+}
+
+func newWatcher(s *sqlStore, wo store.WatchOptions) (store.Watcher, error) {
+	listener := pq.NewListener(s.dsn, time.Second, time.Minute, nil)
+	if err := listener.Listen(s.channel()); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return &sqlWatcher{
+		s:        s,
+		wo:       wo,
+		listener: listener,
+		exit:     make(chan bool),
+	}, nil
+}
+
+func (w *sqlWatcher) Next() (*store.Event, error) {
+	for {
+		select {
+		case n, ok := <-w.listener.Notify:
+			if !ok {
+				return nil, store.ErrWatcherStopped
+			}
+			// nil is sent after the listener reconnects
+			if n == nil {
+				continue
+			}
+
+			// payload is formatted as OPERATION:key
+			parts := strings.SplitN(n.Extra, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			key := parts[1]
+			if !strings.HasPrefix(key, w.wo.Prefix) {
+				continue
+			}
+
+			event := &store.Event{
+				Timestamp: time.Now(),
+				Record:    &store.Record{Key: key},
+			}
+
+			switch parts[0] {
+			case "INSERT":
+				event.Type = store.Create
+			case "UPDATE":
+				event.Type = store.Update
+			case "DELETE":
+				event.Type = store.Delete
+				return event, nil
+			default:
+				continue
+			}
+
+			records, err := w.s.Read(key)
+			if err == store.ErrNotFound {
+				// deleted or expired since the notification
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			event.Record = records[0]
+
+			return event, nil
+		case <-w.exit:
+			return nil, store.ErrWatcherStopped
+		}
+	}
+}
+
+func (w *sqlWatcher) Stop() {
+	select {
+	case <-w.exit:
+		return
+	default:
+		close(w.exit)
+		w.listener.Close()
+	}
+}
@@ -15,16 +15,32 @@ type Store struct {
 }
 
 func (s *Store) Read(ctx context.Context, req *pb.ReadRequest, rsp *pb.ReadResponse) error {
-	vals, err := s.Store.Read(req.Keys...)
-	if err != nil {
-		return errors.InternalServerError("go.micro.store", err.Error())
+	var opts []store.ReadOption
+	if o := req.Options; o != nil {
+		if o.Prefix {
+			opts = append(opts, store.ReadPrefix())
+		}
+		if o.Suffix {
+			opts = append(opts, store.ReadSuffix())
+		}
+		opts = append(opts, store.ReadLimit(uint(o.Limit)), store.ReadOffset(uint(o.Offset)))
 	}
-	for _, val := range vals {
-		rsp.Records = append(rsp.Records, &pb.Record{
-			Key:    val.Key,
-			Value:  val.Value,
-			Expiry: int64(val.Expiry.Seconds()),
-		})
+
+	for _, key := range req.Keys {
+		vals, err := s.Store.Read(key, opts...)
+		if err == store.ErrNotFound {
+			return errors.NotFound("go.micro.store", err.Error())
+		} else if err != nil {
+			return errors.InternalServerError("go.micro.store", err.Error())
+		}
+		for _, val := range vals {
+			rsp.Records = append(rsp.Records, &pb.Record{
+				Key:     val.Key,
+				Value:   val.Value,
+				Expiry:  int64(val.Expiry.Seconds()),
+				Version: val.Version,
+			})
+		}
 	}
 	return nil
 }
@@ -55,6 +71,34 @@ func (s *Store) Delete(ctx context.Context, req *pb.DeleteRequest, rsp *pb.Delet
 	return nil
 }
 
+func (s *Store) Batch(ctx context.Context, req *pb.BatchRequest, rsp *pb.BatchResponse) error {
+	ops := make([]*store.Op, 0, len(req.Ops))
+
+	for _, op := range req.Ops {
+		record := &store.Record{}
+		if r := op.Record; r != nil {
+			record.Key = r.Key
+			record.Value = r.Value
+			record.Expiry = time.Duration(r.Expiry) * time.Second
+		}
+
+		ops = append(ops, &store.Op{
+			Type:      store.OpType(op.Type),
+			Record:    record,
+			Condition: store.Condition(op.Condition),
+			Version:   op.Version,
+		})
+	}
+
+	err := s.Store.Batch(ops...)
+	if err == store.ErrConflict {
+		return errors.Conflict("go.micro.store", err.Error())
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.store", err.Error())
+	}
+	return nil
+}
+
 func (s *Store) List(ctx context.Context, req *pb.ListRequest, stream pb.Store_ListStream) error {
 	var vals []*store.Record
 	var err error
@@ -62,7 +106,16 @@ func (s *Store) List(ctx context.Context, req *pb.ListRequest, stream pb.Store_L
 	if len(req.Key) > 0 {
 		vals, err = s.Store.Read(req.Key)
 	} else {
-		vals, err = s.Store.List()
+		var opts []store.ListOption
+		if o := req.Options; o != nil {
+			opts = append(opts,
+				store.ListPrefix(o.Prefix),
+				store.ListSuffix(o.Suffix),
+				store.ListLimit(uint(o.Limit)),
+				store.ListOffset(uint(o.Offset)),
+			)
+		}
+		vals, err = s.Store.List(opts...)
 	}
 	if err != nil {
 		return errors.InternalServerError("go.micro.store", err.Error())
@@ -72,9 +125,10 @@ func (s *Store) List(ctx context.Context, req *pb.ListRequest, stream pb.Store_L
 	// TODO: batch sync
 	for _, val := range vals {
 		rsp.Records = append(rsp.Records, &pb.Record{
-			Key:    val.Key,
-			Value:  val.Value,
-			Expiry: int64(val.Expiry.Seconds()),
+			Key:     val.Key,
+			Value:   val.Value,
+			Expiry:  int64(val.Expiry.Seconds()),
+			Version: val.Version,
 		})
 	}
 
@@ -87,3 +141,34 @@ func (s *Store) List(ctx context.Context, req *pb.ListRequest, stream pb.Store_L
 	}
 	return nil
 }
+
+func (s *Store) Watch(ctx context.Context, req *pb.WatchRequest, stream pb.Store_WatchStream) error {
+	watcher, err := s.Store.Watch(store.WatchPrefix(req.Prefix))
+	if err != nil {
+		return errors.InternalServerError("go.micro.store", err.Error())
+	}
+	defer watcher.Stop()
+
+	for {
+		next, err := watcher.Next()
+		if err != nil {
+			return errors.InternalServerError("go.micro.store", err.Error())
+		}
+		err = stream.Send(&pb.WatchResponse{
+			Type:      pb.EventType(next.Type),
+			Timestamp: next.Timestamp.Unix(),
+			Record: &pb.Record{
+				Key:     next.Record.Key,
+				Value:   next.Record.Value,
+				Expiry:  int64(next.Record.Expiry.Seconds()),
+				Version: next.Record.Version,
+			},
+		})
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.InternalServerError("go.micro.store", err.Error())
+		}
+	}
+}
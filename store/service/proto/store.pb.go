@@ -20,13 +20,97 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// EventType defines the type of record change
+type EventType int32
+
+const (
+	EventType_Create EventType = 0
+	EventType_Delete EventType = 1
+	EventType_Update EventType = 2
+)
+
+var EventType_name = map[int32]string{
+	0: "Create",
+	1: "Delete",
+	2: "Update",
+}
+
+var EventType_value = map[string]int32{
+	"Create": 0,
+	"Delete": 1,
+	"Update": 2,
+}
+
+func (x EventType) String() string {
+	return proto.EnumName(EventType_name, int32(x))
+}
+
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{0}
+}
+
+type Op_Type int32
+
+const (
+	Op_Write  Op_Type = 0
+	Op_Delete Op_Type = 1
+)
+
+var Op_Type_name = map[int32]string{
+	0: "Write",
+	1: "Delete",
+}
+
+var Op_Type_value = map[string]int32{
+	"Write":  0,
+	"Delete": 1,
+}
+
+func (x Op_Type) String() string {
+	return proto.EnumName(Op_Type_name, int32(x))
+}
+
+func (Op_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{8, 0}
+}
+
+type Op_Condition int32
+
+const (
+	Op_None    Op_Condition = 0
+	Op_Absent  Op_Condition = 1
+	Op_Version Op_Condition = 2
+)
+
+var Op_Condition_name = map[int32]string{
+	0: "None",
+	1: "Absent",
+	2: "Version",
+}
+
+var Op_Condition_value = map[string]int32{
+	"None":    0,
+	"Absent":  1,
+	"Version": 2,
+}
+
+func (x Op_Condition) String() string {
+	return proto.EnumName(Op_Condition_name, int32(x))
+}
+
+func (Op_Condition) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{8, 1}
+}
+
 type Record struct {
 	// key of the record
 	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	// value in the record
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	// timestamp in unix seconds
-	Expiry               int64    `protobuf:"varint,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	Expiry int64 `protobuf:"varint,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	// version of the record
+	Version              uint64   `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -78,18 +162,93 @@ func (m *Record) GetExpiry() int64 {
 	return 0
 }
 
-type ReadRequest struct {
-	Keys                 []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+func (m *Record) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type ReadOptions struct {
+	// read all keys with the prefix
+	Prefix bool `protobuf:"varint,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// read all keys with the suffix
+	Suffix bool `protobuf:"varint,2,opt,name=suffix,proto3" json:"suffix,omitempty"`
+	// max number of records
+	Limit uint64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// number of records to skip
+	Offset               uint64   `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *ReadOptions) Reset()         { *m = ReadOptions{} }
+func (m *ReadOptions) String() string { return proto.CompactTextString(m) }
+func (*ReadOptions) ProtoMessage()    {}
+func (*ReadOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{1}
+}
+
+func (m *ReadOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadOptions.Unmarshal(m, b)
+}
+func (m *ReadOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadOptions.Marshal(b, m, deterministic)
+}
+func (m *ReadOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadOptions.Merge(m, src)
+}
+func (m *ReadOptions) XXX_Size() int {
+	return xxx_messageInfo_ReadOptions.Size(m)
+}
+func (m *ReadOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadOptions proto.InternalMessageInfo
+
+func (m *ReadOptions) GetPrefix() bool {
+	if m != nil {
+		return m.Prefix
+	}
+	return false
+}
+
+func (m *ReadOptions) GetSuffix() bool {
+	if m != nil {
+		return m.Suffix
+	}
+	return false
+}
+
+func (m *ReadOptions) GetLimit() uint64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ReadOptions) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+type ReadRequest struct {
+	Keys                 []string     `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	Options              *ReadOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
 func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
 func (m *ReadRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()    {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{1}
+	return fileDescriptor_f84ccc98e143ed3e, []int{2}
 }
 
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
@@ -117,6 +276,13 @@ func (m *ReadRequest) GetKeys() []string {
 	return nil
 }
 
+func (m *ReadRequest) GetOptions() *ReadOptions {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 type ReadResponse struct {
 	Records              []*Record `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
@@ -128,7 +294,7 @@ func (m *ReadResponse) Reset()         { *m = ReadResponse{} }
 func (m *ReadResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse) ProtoMessage()    {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{2}
+	return fileDescriptor_f84ccc98e143ed3e, []int{3}
 }
 
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
@@ -167,7 +333,7 @@ func (m *WriteRequest) Reset()         { *m = WriteRequest{} }
 func (m *WriteRequest) String() string { return proto.CompactTextString(m) }
 func (*WriteRequest) ProtoMessage()    {}
 func (*WriteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{3}
+	return fileDescriptor_f84ccc98e143ed3e, []int{4}
 }
 
 func (m *WriteRequest) XXX_Unmarshal(b []byte) error {
@@ -205,7 +371,7 @@ func (m *WriteResponse) Reset()         { *m = WriteResponse{} }
 func (m *WriteResponse) String() string { return proto.CompactTextString(m) }
 func (*WriteResponse) ProtoMessage()    {}
 func (*WriteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{4}
+	return fileDescriptor_f84ccc98e143ed3e, []int{5}
 }
 
 func (m *WriteResponse) XXX_Unmarshal(b []byte) error {
@@ -237,7 +403,7 @@ func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
 func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteRequest) ProtoMessage()    {}
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{5}
+	return fileDescriptor_f84ccc98e143ed3e, []int{6}
 }
 
 func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
@@ -275,7 +441,7 @@ func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
 func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
 func (*DeleteResponse) ProtoMessage()    {}
 func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{6}
+	return fileDescriptor_f84ccc98e143ed3e, []int{7}
 }
 
 func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
@@ -296,19 +462,223 @@ func (m *DeleteResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
 
-type ListRequest struct {
-	// optional key
-	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+type Op struct {
+	Type Op_Type `protobuf:"varint,1,opt,name=type,proto3,enum=go.micro.store.Op.Type" json:"type,omitempty"`
+	// record to write, only key is set on delete
+	Record *Record `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+	// condition which must hold for the batch to apply
+	Condition Op_Condition `protobuf:"varint,3,opt,name=condition,proto3,enum=go.micro.store.Op.Condition" json:"condition,omitempty"`
+	// version compared against for the version condition
+	Version              uint64   `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Op) Reset()         { *m = Op{} }
+func (m *Op) String() string { return proto.CompactTextString(m) }
+func (*Op) ProtoMessage()    {}
+func (*Op) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{8}
+}
+
+func (m *Op) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Op.Unmarshal(m, b)
+}
+func (m *Op) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Op.Marshal(b, m, deterministic)
+}
+func (m *Op) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Op.Merge(m, src)
+}
+func (m *Op) XXX_Size() int {
+	return xxx_messageInfo_Op.Size(m)
+}
+func (m *Op) XXX_DiscardUnknown() {
+	xxx_messageInfo_Op.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Op proto.InternalMessageInfo
+
+func (m *Op) GetType() Op_Type {
+	if m != nil {
+		return m.Type
+	}
+	return Op_Write
+}
+
+func (m *Op) GetRecord() *Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+func (m *Op) GetCondition() Op_Condition {
+	if m != nil {
+		return m.Condition
+	}
+	return Op_None
+}
+
+func (m *Op) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type BatchRequest struct {
+	Ops                  []*Op    `protobuf:"bytes,1,rep,name=ops,proto3" json:"ops,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *BatchRequest) Reset()         { *m = BatchRequest{} }
+func (m *BatchRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchRequest) ProtoMessage()    {}
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{9}
+}
+
+func (m *BatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchRequest.Unmarshal(m, b)
+}
+func (m *BatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchRequest.Marshal(b, m, deterministic)
+}
+func (m *BatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchRequest.Merge(m, src)
+}
+func (m *BatchRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchRequest.Size(m)
+}
+func (m *BatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchRequest proto.InternalMessageInfo
+
+func (m *BatchRequest) GetOps() []*Op {
+	if m != nil {
+		return m.Ops
+	}
+	return nil
+}
+
+type BatchResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchResponse) Reset()         { *m = BatchResponse{} }
+func (m *BatchResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchResponse) ProtoMessage()    {}
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{10}
+}
+
+func (m *BatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchResponse.Unmarshal(m, b)
+}
+func (m *BatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchResponse.Marshal(b, m, deterministic)
+}
+func (m *BatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchResponse.Merge(m, src)
+}
+func (m *BatchResponse) XXX_Size() int {
+	return xxx_messageInfo_BatchResponse.Size(m)
+}
+func (m *BatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchResponse proto.InternalMessageInfo
+
+type ListOptions struct {
+	// optional key prefix
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// optional key suffix
+	Suffix string `protobuf:"bytes,2,opt,name=suffix,proto3" json:"suffix,omitempty"`
+	// max number of records
+	Limit uint64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// number of records to skip
+	Offset               uint64   `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListOptions) Reset()         { *m = ListOptions{} }
+func (m *ListOptions) String() string { return proto.CompactTextString(m) }
+func (*ListOptions) ProtoMessage()    {}
+func (*ListOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{11}
+}
+
+func (m *ListOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListOptions.Unmarshal(m, b)
+}
+func (m *ListOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListOptions.Marshal(b, m, deterministic)
+}
+func (m *ListOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListOptions.Merge(m, src)
+}
+func (m *ListOptions) XXX_Size() int {
+	return xxx_messageInfo_ListOptions.Size(m)
+}
+func (m *ListOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListOptions proto.InternalMessageInfo
+
+func (m *ListOptions) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *ListOptions) GetSuffix() string {
+	if m != nil {
+		return m.Suffix
+	}
+	return ""
+}
+
+func (m *ListOptions) GetLimit() uint64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListOptions) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+type ListRequest struct {
+	// optional key
+	Key                  string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Options              *ListOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
 func (m *ListRequest) Reset()         { *m = ListRequest{} }
 func (m *ListRequest) String() string { return proto.CompactTextString(m) }
 func (*ListRequest) ProtoMessage()    {}
 func (*ListRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{7}
+	return fileDescriptor_f84ccc98e143ed3e, []int{12}
 }
 
 func (m *ListRequest) XXX_Unmarshal(b []byte) error {
@@ -336,6 +706,13 @@ func (m *ListRequest) GetKey() string {
 	return ""
 }
 
+func (m *ListRequest) GetOptions() *ListOptions {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 type ListResponse struct {
 	Records              []*Record `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
@@ -347,7 +724,7 @@ func (m *ListResponse) Reset()         { *m = ListResponse{} }
 func (m *ListResponse) String() string { return proto.CompactTextString(m) }
 func (*ListResponse) ProtoMessage()    {}
 func (*ListResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f84ccc98e143ed3e, []int{8}
+	return fileDescriptor_f84ccc98e143ed3e, []int{13}
 }
 
 func (m *ListResponse) XXX_Unmarshal(b []byte) error {
@@ -375,16 +752,124 @@ func (m *ListResponse) GetRecords() []*Record {
 	return nil
 }
 
+type WatchRequest struct {
+	// optional key prefix
+	Prefix               string   `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{14}
+}
+
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+type WatchResponse struct {
+	// type of change
+	Type EventType `protobuf:"varint,1,opt,name=type,proto3,enum=go.micro.store.EventType" json:"type,omitempty"`
+	// unix timestamp of the change
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// changed record, only key is set on delete
+	Record               *Record  `protobuf:"bytes,3,opt,name=record,proto3" json:"record,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchResponse) Reset()         { *m = WatchResponse{} }
+func (m *WatchResponse) String() string { return proto.CompactTextString(m) }
+func (*WatchResponse) ProtoMessage()    {}
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f84ccc98e143ed3e, []int{15}
+}
+
+func (m *WatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchResponse.Unmarshal(m, b)
+}
+func (m *WatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchResponse.Marshal(b, m, deterministic)
+}
+func (m *WatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchResponse.Merge(m, src)
+}
+func (m *WatchResponse) XXX_Size() int {
+	return xxx_messageInfo_WatchResponse.Size(m)
+}
+func (m *WatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchResponse proto.InternalMessageInfo
+
+func (m *WatchResponse) GetType() EventType {
+	if m != nil {
+		return m.Type
+	}
+	return EventType_Create
+}
+
+func (m *WatchResponse) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *WatchResponse) GetRecord() *Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterEnum("go.micro.store.EventType", EventType_name, EventType_value)
+	proto.RegisterEnum("go.micro.store.Op.Type", Op_Type_name, Op_Type_value)
+	proto.RegisterEnum("go.micro.store.Op.Condition", Op_Condition_name, Op_Condition_value)
 	proto.RegisterType((*Record)(nil), "go.micro.store.Record")
+	proto.RegisterType((*ReadOptions)(nil), "go.micro.store.ReadOptions")
 	proto.RegisterType((*ReadRequest)(nil), "go.micro.store.ReadRequest")
 	proto.RegisterType((*ReadResponse)(nil), "go.micro.store.ReadResponse")
 	proto.RegisterType((*WriteRequest)(nil), "go.micro.store.WriteRequest")
 	proto.RegisterType((*WriteResponse)(nil), "go.micro.store.WriteResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "go.micro.store.DeleteRequest")
 	proto.RegisterType((*DeleteResponse)(nil), "go.micro.store.DeleteResponse")
+	proto.RegisterType((*Op)(nil), "go.micro.store.Op")
+	proto.RegisterType((*BatchRequest)(nil), "go.micro.store.BatchRequest")
+	proto.RegisterType((*BatchResponse)(nil), "go.micro.store.BatchResponse")
+	proto.RegisterType((*ListOptions)(nil), "go.micro.store.ListOptions")
 	proto.RegisterType((*ListRequest)(nil), "go.micro.store.ListRequest")
 	proto.RegisterType((*ListResponse)(nil), "go.micro.store.ListResponse")
+	proto.RegisterType((*WatchRequest)(nil), "go.micro.store.WatchRequest")
+	proto.RegisterType((*WatchResponse)(nil), "go.micro.store.WatchResponse")
 }
 
 func init() {
@@ -392,26 +877,47 @@ func init() {
 }
 
 var fileDescriptor_f84ccc98e143ed3e = []byte{
-	// 333 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0x4d, 0x4f, 0xc2, 0x40,
-	0x10, 0x86, 0x59, 0x0a, 0x35, 0x0c, 0x1f, 0x92, 0x89, 0x21, 0x0d, 0x7e, 0xd5, 0x7a, 0xe9, 0xc5,
-	0x42, 0xf0, 0x0f, 0x98, 0xf8, 0x11, 0x4d, 0x3c, 0xad, 0x07, 0xcf, 0x08, 0x13, 0xd2, 0x80, 0x2e,
-	0xee, 0x16, 0x62, 0xff, 0x90, 0xbf, 0xd3, 0xec, 0x6e, 0xab, 0xc5, 0x42, 0x62, 0xbc, 0xcd, 0xee,
-	0xbc, 0xf3, 0xec, 0xdb, 0x79, 0x0b, 0xd1, 0x6b, 0x3c, 0x91, 0x62, 0x30, 0x13, 0x17, 0xb6, 0x50,
-	0x89, 0x90, 0x34, 0x50, 0x24, 0xd7, 0xf1, 0x84, 0x06, 0x4b, 0x29, 0x92, 0xec, 0x2e, 0x32, 0x35,
-	0x76, 0x66, 0xc2, 0x8e, 0x44, 0xe6, 0x36, 0xb8, 0x07, 0x97, 0xd3, 0x44, 0xc8, 0x29, 0x76, 0xc1,
-	0x99, 0x53, 0xea, 0x31, 0x9f, 0x85, 0x0d, 0xae, 0x4b, 0x3c, 0x80, 0xfa, 0x7a, 0xbc, 0x58, 0x91,
-	0x57, 0xf5, 0x59, 0xd8, 0xe2, 0xf6, 0x80, 0x3d, 0x70, 0xe9, 0x63, 0x19, 0xcb, 0xd4, 0x73, 0x7c,
-	0x16, 0x3a, 0x3c, 0x3b, 0x05, 0x67, 0xd0, 0xe4, 0x34, 0x9e, 0x72, 0x7a, 0x5f, 0x91, 0x4a, 0x10,
-	0xa1, 0x36, 0xa7, 0x54, 0x79, 0xcc, 0x77, 0xc2, 0x06, 0x37, 0x75, 0x70, 0x05, 0x2d, 0x2b, 0x51,
-	0x4b, 0xf1, 0xa6, 0x08, 0x87, 0xb0, 0x27, 0xcd, 0xe3, 0x56, 0xd6, 0x1c, 0xf5, 0xa2, 0x4d, 0x7b,
-	0x91, 0xf5, 0xc6, 0x73, 0x99, 0x26, 0x3c, 0xcb, 0x38, 0xa1, 0xfc, 0x95, 0x02, 0xa1, 0xfa, 0x37,
-	0xc2, 0x3e, 0xb4, 0x33, 0x82, 0x35, 0x11, 0x9c, 0x43, 0xfb, 0x86, 0x16, 0xf4, 0xc3, 0xdc, 0xe6,
-	0xbc, 0x0b, 0x9d, 0x5c, 0x94, 0x8d, 0x9d, 0x42, 0xf3, 0x31, 0x56, 0x49, 0x3e, 0x54, 0xda, 0x9e,
-	0xb6, 0x6a, 0x05, 0xff, 0xfd, 0xd8, 0xd1, 0x67, 0x15, 0xea, 0x4f, 0xba, 0x83, 0xb7, 0x50, 0xd3,
-	0x2c, 0x3c, 0xfc, 0x3d, 0x52, 0xb0, 0xd0, 0x3f, 0xda, 0xde, 0xcc, 0xfc, 0x56, 0x86, 0x0c, 0xaf,
-	0xa1, 0xa6, 0xf7, 0x5f, 0xc6, 0x14, 0x82, 0x2b, 0x63, 0x8a, 0x91, 0x05, 0x15, 0xbc, 0x83, 0xba,
-	0x59, 0x20, 0x96, 0x84, 0xc5, 0x64, 0xfa, 0xc7, 0x3b, 0xba, 0xdf, 0x9c, 0x07, 0x70, 0xed, 0x4a,
-	0xb1, 0x24, 0xdd, 0xc8, 0xa3, 0x7f, 0xb2, 0xab, 0x9d, 0xa3, 0x5e, 0x5c, 0xf3, 0x6f, 0x5f, 0x7e,
-	0x05, 0x00, 0x00, 0xff, 0xff, 0x30, 0x48, 0x25, 0x2d, 0x0d, 0x03, 0x00, 0x00,
+	// 664 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0x5d, 0x6f, 0x12, 0x4d,
+	0x14, 0xee, 0x7e, 0x00, 0xdd, 0x03, 0xe5, 0x25, 0x27, 0x6f, 0x2a, 0x62, 0x6b, 0xc8, 0x6a, 0x0c,
+	0xd1, 0x74, 0x69, 0x50, 0x6f, 0xbc, 0x52, 0x6b, 0x8d, 0x26, 0xc6, 0x26, 0xa3, 0x16, 0x6f, 0x29,
+	0x1c, 0xea, 0x86, 0xb2, 0xb3, 0xee, 0x4e, 0x49, 0xf9, 0x03, 0x5e, 0xf9, 0x83, 0xfc, 0x79, 0x66,
+	0x3e, 0x96, 0x2e, 0x85, 0xc5, 0xa6, 0x77, 0x73, 0x3e, 0xf6, 0x39, 0xcf, 0x3c, 0xf3, 0xcc, 0x2c,
+	0x04, 0xd3, 0x70, 0x98, 0xf0, 0xee, 0x39, 0x3f, 0xd0, 0x8b, 0x54, 0xf0, 0x84, 0xba, 0x29, 0x25,
+	0xb3, 0x70, 0x48, 0xdd, 0x38, 0xe1, 0xc2, 0xe4, 0x02, 0xb5, 0xc6, 0xfa, 0x39, 0xd7, 0x9f, 0x04,
+	0x2a, 0xeb, 0x9f, 0x41, 0x99, 0xd1, 0x90, 0x27, 0x23, 0x6c, 0x80, 0x33, 0xa1, 0x79, 0xd3, 0x6a,
+	0x5b, 0x1d, 0x8f, 0xc9, 0x25, 0xfe, 0x0f, 0xa5, 0xd9, 0xe0, 0xe2, 0x92, 0x9a, 0x76, 0xdb, 0xea,
+	0xd4, 0x98, 0x0e, 0x70, 0x17, 0xca, 0x74, 0x15, 0x87, 0xc9, 0xbc, 0xe9, 0xb4, 0xad, 0x8e, 0xc3,
+	0x4c, 0x84, 0x4d, 0xa8, 0xcc, 0x28, 0x49, 0x43, 0x1e, 0x35, 0xdd, 0xb6, 0xd5, 0x71, 0x59, 0x16,
+	0xfa, 0x13, 0xa8, 0x32, 0x1a, 0x8c, 0x4e, 0x62, 0x11, 0xf2, 0x28, 0x95, 0x00, 0x71, 0x42, 0xe3,
+	0xf0, 0x4a, 0xcd, 0xda, 0x66, 0x26, 0x92, 0xf9, 0xf4, 0x72, 0x2c, 0xf3, 0xb6, 0xce, 0xeb, 0x48,
+	0xd2, 0xb8, 0x08, 0xa7, 0xa1, 0x50, 0xf3, 0x5c, 0xa6, 0x03, 0xd9, 0xcd, 0xc7, 0xe3, 0x94, 0x84,
+	0x99, 0x66, 0x22, 0xff, 0xbb, 0x1e, 0xc6, 0xe8, 0xe7, 0x25, 0xa5, 0x02, 0x11, 0xdc, 0x09, 0xcd,
+	0xd3, 0xa6, 0xd5, 0x76, 0x3a, 0x1e, 0x53, 0x6b, 0x7c, 0x09, 0x15, 0xae, 0xb9, 0xa8, 0x49, 0xd5,
+	0xde, 0x83, 0x60, 0x59, 0x95, 0x20, 0x47, 0x97, 0x65, 0xbd, 0xfe, 0x6b, 0xa8, 0x69, 0xe4, 0x34,
+	0xe6, 0x51, 0x4a, 0x78, 0x08, 0x95, 0x44, 0x49, 0xa7, 0xd1, 0xab, 0xbd, 0xdd, 0x55, 0x18, 0x59,
+	0x66, 0x59, 0x9b, 0x44, 0xe8, 0x27, 0xa1, 0xa0, 0x8c, 0x5c, 0x0e, 0xc1, 0xbe, 0x1d, 0xc2, 0x7f,
+	0xb0, 0x63, 0x10, 0x34, 0x09, 0xff, 0x11, 0xec, 0xbc, 0xa3, 0x0b, 0x12, 0xb4, 0x61, 0xc3, 0x7e,
+	0x03, 0xea, 0x59, 0x93, 0xf9, 0xec, 0x97, 0x0d, 0xf6, 0x49, 0x8c, 0xcf, 0xc0, 0x15, 0xf3, 0x98,
+	0xd4, 0x41, 0xd4, 0x7b, 0xf7, 0x6e, 0x4e, 0x3f, 0x89, 0x83, 0xaf, 0xf3, 0x98, 0x98, 0x6a, 0xc2,
+	0x00, 0xca, 0x9a, 0x86, 0x51, 0xad, 0x88, 0xac, 0xe9, 0xc2, 0x57, 0xe0, 0x0d, 0x79, 0x34, 0x0a,
+	0xa5, 0x7a, 0xea, 0xec, 0xea, 0xbd, 0xbd, 0x35, 0x13, 0x8e, 0xb2, 0x1e, 0x76, 0xdd, 0xbe, 0xc1,
+	0x4c, 0xfb, 0xe0, 0x4a, 0x4e, 0xe8, 0x41, 0x49, 0x29, 0xd1, 0xd8, 0x42, 0x80, 0xb2, 0xde, 0x5e,
+	0xc3, 0xf2, 0x03, 0xf0, 0x16, 0x80, 0xb8, 0x0d, 0xee, 0x67, 0x1e, 0x99, 0x96, 0x37, 0x67, 0x29,
+	0x45, 0xa2, 0x61, 0x61, 0x15, 0x2a, 0xa7, 0x1a, 0xac, 0x61, 0xfb, 0x2f, 0xa0, 0xf6, 0x76, 0x20,
+	0x86, 0x3f, 0x32, 0xf9, 0x1e, 0x83, 0xc3, 0xe3, 0xec, 0x40, 0x71, 0x95, 0x2e, 0x93, 0x65, 0x79,
+	0x0c, 0xe6, 0x2b, 0xa3, 0xe7, 0x04, 0xaa, 0x9f, 0xc2, 0x54, 0xac, 0xb7, 0xb8, 0x57, 0x60, 0x71,
+	0xef, 0x8e, 0x16, 0x3f, 0xd5, 0xc3, 0x32, 0xca, 0xab, 0x17, 0xf7, 0xdf, 0x06, 0xcf, 0x91, 0x5d,
+	0x32, 0xb8, 0xc6, 0xbd, 0xb3, 0xc1, 0x9f, 0x40, 0xad, 0x9f, 0x57, 0xb3, 0x40, 0x07, 0xff, 0xb7,
+	0x05, 0x3b, 0xfd, 0xbc, 0x80, 0x78, 0xb0, 0xe4, 0xc4, 0xfb, 0x37, 0x07, 0x1d, 0xcf, 0x28, 0x12,
+	0x39, 0x2f, 0xee, 0x81, 0x27, 0xc2, 0x29, 0xa5, 0x62, 0x30, 0x8d, 0xd5, 0x1e, 0x1d, 0x76, 0x9d,
+	0xc8, 0x39, 0xd5, 0xb9, 0x8d, 0x53, 0x9f, 0x76, 0xc1, 0x5b, 0x0c, 0x90, 0x56, 0x39, 0x4a, 0x68,
+	0x70, 0xd3, 0x59, 0x72, 0xfd, 0x2d, 0x1e, 0xc9, 0xbc, 0xdd, 0xfb, 0xe3, 0x40, 0xe9, 0x8b, 0x44,
+	0xc2, 0x63, 0x70, 0xa5, 0x66, 0xb8, 0x56, 0x61, 0x23, 0x43, 0x6b, 0x6f, 0x7d, 0xd1, 0x78, 0x67,
+	0xeb, 0xd0, 0xc2, 0x23, 0x70, 0xe5, 0xdb, 0x82, 0x6b, 0x5f, 0xa2, 0x42, 0x98, 0xfc, 0x73, 0xe4,
+	0x6f, 0xe1, 0x7b, 0x73, 0x25, 0x70, 0xa5, 0x31, 0xff, 0xea, 0xb4, 0xf6, 0x0b, 0xaa, 0x0b, 0x9c,
+	0x8f, 0xd9, 0xae, 0x71, 0xa5, 0x75, 0xe9, 0xad, 0x69, 0x3d, 0x2c, 0x2a, 0xe7, 0x29, 0xa9, 0x8b,
+	0xb2, 0x4a, 0x29, 0x7f, 0xeb, 0x5a, 0xfb, 0x05, 0xd5, 0x05, 0xce, 0x07, 0x28, 0xf5, 0xd7, 0xe3,
+	0xf4, 0x37, 0xe2, 0xf4, 0x97, 0x71, 0x0e, 0xad, 0xb3, 0xb2, 0xfa, 0x0f, 0x3e, 0xff, 0x3b, 0x00,
+	0x11, 0x75, 0x55, 0x50, 0x39, 0x07, 0x00, 0x00,
 }
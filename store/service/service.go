@@ -8,6 +8,7 @@ import (
 
 	"github.com/micro/go-micro/client"
 	"github.com/micro/go-micro/config/options"
+	"github.com/micro/go-micro/errors"
 	"github.com/micro/go-micro/store"
 	pb "github.com/micro/go-micro/store/service/proto"
 )
@@ -23,8 +24,20 @@ type serviceStore struct {
 }
 
 // Sync all the known records
-func (s *serviceStore) List() ([]*store.Record, error) {
-	stream, err := s.Client.List(context.Background(), &pb.ListRequest{}, client.WithAddress(s.Nodes...))
+func (s *serviceStore) List(opts ...store.ListOption) ([]*store.Record, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	stream, err := s.Client.List(context.Background(), &pb.ListRequest{
+		Options: &pb.ListOptions{
+			Prefix: options.Prefix,
+			Suffix: options.Suffix,
+			Limit:  uint64(options.Limit),
+			Offset: uint64(options.Offset),
+		},
+	}, client.WithAddress(s.Nodes...))
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +55,10 @@ func (s *serviceStore) List() ([]*store.Record, error) {
 		}
 		for _, record := range rsp.Records {
 			records = append(records, &store.Record{
-				Key:    record.Key,
-				Value:  record.Value,
-				Expiry: time.Duration(record.Expiry) * time.Second,
+				Key:     record.Key,
+				Value:   record.Value,
+				Expiry:  time.Duration(record.Expiry) * time.Second,
+				Version: record.Version,
 			})
 		}
 	}
@@ -53,20 +67,34 @@ func (s *serviceStore) List() ([]*store.Record, error) {
 }
 
 // Read a record with key
-func (s *serviceStore) Read(keys ...string) ([]*store.Record, error) {
+func (s *serviceStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
 	rsp, err := s.Client.Read(context.Background(), &pb.ReadRequest{
-		Keys: keys,
+		Keys: []string{key},
+		Options: &pb.ReadOptions{
+			Prefix: options.Prefix,
+			Suffix: options.Suffix,
+			Limit:  uint64(options.Limit),
+			Offset: uint64(options.Offset),
+		},
 	}, client.WithAddress(s.Nodes...))
-	if err != nil {
+	if err != nil && errors.Parse(err.Error()).Code == 404 {
+		return nil, store.ErrNotFound
+	} else if err != nil {
 		return nil, err
 	}
 
 	records := make([]*store.Record, 0, len(rsp.Records))
 	for _, val := range rsp.Records {
 		records = append(records, &store.Record{
-			Key:    val.Key,
-			Value:  val.Value,
-			Expiry: time.Duration(val.Expiry) * time.Second,
+			Key:     val.Key,
+			Value:   val.Value,
+			Expiry:  time.Duration(val.Expiry) * time.Second,
+			Version: val.Version,
 		})
 	}
 	return records, nil
@@ -99,6 +127,49 @@ func (s *serviceStore) Delete(keys ...string) error {
 	return err
 }
 
+// Batch applies the operations atomically
+func (s *serviceStore) Batch(ops ...*store.Op) error {
+	pbOps := make([]*pb.Op, 0, len(ops))
+
+	for _, op := range ops {
+		pbOps = append(pbOps, &pb.Op{
+			Type: pb.Op_Type(op.Type),
+			Record: &pb.Record{
+				Key:    op.Record.Key,
+				Value:  op.Record.Value,
+				Expiry: int64(op.Record.Expiry.Seconds()),
+			},
+			Condition: pb.Op_Condition(op.Condition),
+			Version:   op.Version,
+		})
+	}
+
+	_, err := s.Client.Batch(context.Background(), &pb.BatchRequest{
+		Ops: pbOps,
+	}, client.WithAddress(s.Nodes...))
+	if err != nil && errors.Parse(err.Error()).Code == 409 {
+		return store.ErrConflict
+	}
+	return err
+}
+
+// Watch for changes to records
+func (s *serviceStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var options store.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	stream, err := s.Client.Watch(context.Background(), &pb.WatchRequest{
+		Prefix: options.Prefix,
+	}, client.WithAddress(s.Nodes...))
+	if err != nil {
+		return nil, err
+	}
+
+	return newWatcher(stream), nil
+}
+
 // NewStore returns a new store service implementation
 func NewStore(opts ...options.Option) store.Store {
 	options := options.NewOptions(opts...)
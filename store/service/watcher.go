@@ -0,0 +1,59 @@
+package service
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/store"
+	pb "github.com/micro/go-micro/store/service/proto"
+)
+
+type serviceWatcher struct {
+	stream pb.Store_WatchService
+	closed chan bool
+}
+
+func (s *serviceWatcher) Next() (*store.Event, error) {
+	// check if closed
+	select {
+	case <-s.closed:
+		return nil, store.ErrWatcherStopped
+	default:
+	}
+
+	r, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	event := &store.Event{
+		Type:      store.EventType(r.Type),
+		Timestamp: time.Unix(r.Timestamp, 0),
+		Record:    &store.Record{},
+	}
+
+	if r.Record != nil {
+		event.Record.Key = r.Record.Key
+		event.Record.Value = r.Record.Value
+		event.Record.Expiry = time.Duration(r.Record.Expiry) * time.Second
+		event.Record.Version = r.Record.Version
+	}
+
+	return event, nil
+}
+
+func (s *serviceWatcher) Stop() {
+	select {
+	case <-s.closed:
+		return
+	default:
+		close(s.closed)
+		s.stream.Close()
+	}
+}
+
+func newWatcher(stream pb.Store_WatchService) store.Watcher {
+	return &serviceWatcher{
+		stream: stream,
+		closed: make(chan bool),
+	}
+}
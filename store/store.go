@@ -9,18 +9,31 @@ import (
 var (
 	// ErrNotFound is returned when a Read key doesn't exist
 	ErrNotFound = errors.New("not found")
+	// ErrWatcherStopped is returned when a store watcher has been stopped
+	ErrWatcherStopped = errors.New("watcher stopped")
+	// ErrConflict is returned when the condition of a batch operation does not hold
+	ErrConflict = errors.New("conflict")
+	// ErrNotSupported is returned when a store does not support an operation
+	ErrNotSupported = errors.New("not supported")
 )
 
 // Store is a data storage interface
 type Store interface {
 	// List all the known records
-	List() ([]*Record, error)
+	List(opts ...ListOption) ([]*Record, error)
 	// Read records with keys
-	Read(key ...string) ([]*Record, error)
+	Read(key string, opts ...ReadOption) ([]*Record, error)
 	// Write records
 	Write(rec ...*Record) error
 	// Delete records with keys
 	Delete(key ...string) error
+	// Batch applies the operations atomically. If the condition of any
+	// operation does not hold none are applied and ErrConflict is returned.
+	// Conditions are checked against the records before the batch, not
+	// the changes made by earlier operations in the same batch.
+	Batch(ops ...*Op) error
+	// Watch returns a watcher for record changes
+	Watch(opts ...WatchOption) (Watcher, error)
 }
 
 // Record represents a data record
@@ -28,4 +41,7 @@ type Record struct {
 	Key    string
 	Value  []byte
 	Expiry time.Duration
+	// Version is set by the store when the record is read and
+	// changes every time the record is written
+	Version uint64
 }
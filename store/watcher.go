@@ -0,0 +1,49 @@
+package store
+
+import "time"
+
+// EventType defines store event type
+type EventType int
+
+const (
+	// Create is emitted when a new record is written
+	Create EventType = iota
+	// Delete is emitted when an existing record is deleted
+	Delete
+	// Update is emitted when an existing record is overwritten
+	Update
+)
+
+// String returns human readable event type
+func (t EventType) String() string {
+	switch t {
+	case Create:
+		return "create"
+	case Delete:
+		return "delete"
+	case Update:
+		return "update"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is returned by a call to Next on the watcher.
+type Event struct {
+	// Type defines type of event
+	Type EventType
+	// Timestamp is event timestamp
+	Timestamp time.Time
+	// Record is the changed record. For
+	// delete events only the key is set.
+	Record *Record
+}
+
+// Watcher is an interface that returns updates
+// about records within the store.
+type Watcher interface {
+	// Next is a blocking call
+	Next() (*Event, error)
+	// Stop stops the watcher
+	Stop()
+}
@@ -1,19 +1,34 @@
 // Package event provides a distributed log interface
 package event
 
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrInvalidOffset is returned when seeking outside of the log
+	ErrInvalidOffset = errors.New("invalid offset")
+	// ErrClosed is returned when using a closed log handle
+	ErrClosed = errors.New("log closed")
+)
+
 // Event provides a distributed log interface
 type Event interface {
 	// Log retrieves the log with an id/name
 	Log(id string) (Log, error)
 }
 
-// Log is an individual event log
+// Log is an individual event log. Each handle returned by
+// Event.Log has its own read position so many readers can
+// consume the same log concurrently.
 type Log interface {
 	// Close the log handle
 	Close() error
 	// Log ID
 	Id() string
-	// Read will read the next record
+	// Read will read the next record. It returns
+	// io.EOF once the end of the log is reached.
 	Read() (*Record, error)
 	// Go to an offset
 	Seek(offset int64) error
@@ -22,6 +37,18 @@ type Log interface {
 }
 
 type Record struct {
-	Metadata map[string]interface{}
-	Data     []byte
+	Metadata map[string]interface{} `json:"metadata"`
+	Data     []byte                 `json:"data"`
+	// Offset of the record in the log, set on Read and Write
+	Offset int64 `json:"-"`
 }
+
+type Options struct {
+	// Prefix for log ids
+	Prefix string
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
+}
+
+type Option func(o *Options)
@@ -0,0 +1,307 @@
+// Package file is a local segmented append-only log implementation of event
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/micro/go-micro/sync/event"
+)
+
+var (
+	// DefaultDir is the directory the logs are kept in
+	DefaultDir = filepath.Join(os.TempDir(), "micro", "event")
+	// DefaultSegmentSize is the size after which a new segment is started
+	DefaultSegmentSize int64 = 64 * 1024 * 1024
+)
+
+type fileEvent struct {
+	opts        event.Options
+	dir         string
+	segmentSize int64
+
+	sync.Mutex
+	logs map[string]*fileLog
+}
+
+// fileLog is shared by all the open handles of a log
+type fileLog struct {
+	dir         string
+	segmentSize int64
+	// number of open handles
+	refs int
+
+	sync.RWMutex
+	segments []*segment
+}
+
+type fileHandle struct {
+	id    string
+	event *fileEvent
+	log   *fileLog
+
+	sync.Mutex
+	offset int64
+	closed bool
+}
+
+func openLog(dir string, segmentSize int64) (*fileLog, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var bases []int64
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		base, err := strconv.ParseInt(strings.TrimSuffix(name, ".log"), 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+
+	if len(bases) == 0 {
+		bases = append(bases, 0)
+	}
+
+	l := &fileLog{
+		dir:         dir,
+		segmentSize: segmentSize,
+	}
+
+	for i, base := range bases {
+		// only the last segment can have a partial write
+		s, err := openSegment(dir, base, i == len(bases)-1)
+		if err != nil {
+			l.close()
+			return nil, err
+		}
+		l.segments = append(l.segments, s)
+	}
+
+	return l, nil
+}
+
+// next is the offset the next record will be written at
+func (l *fileLog) next() int64 {
+	return l.segments[len(l.segments)-1].next()
+}
+
+func (l *fileLog) read(offset int64) (*event.Record, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if offset >= l.next() {
+		return nil, io.EOF
+	}
+
+	// find the segment holding the offset
+	i := sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].base > offset
+	}) - 1
+
+	b, err := l.segments[i].read(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(event.Record)
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	r.Offset = offset
+
+	return r, nil
+}
+
+func (l *fileLog) write(r *event.Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	s := l.segments[len(l.segments)-1]
+
+	// start a new segment once the current one is full
+	if s.size > 0 && s.size+int64(frameHeader+len(b)) > l.segmentSize {
+		ns, err := openSegment(l.dir, s.next(), true)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, ns)
+		s = ns
+	}
+
+	offset := s.next()
+	if err := s.append(b); err != nil {
+		return err
+	}
+	r.Offset = offset
+
+	return nil
+}
+
+func (l *fileLog) close() error {
+	var gerr error
+	for _, s := range l.segments {
+		if err := s.close(); err != nil {
+			gerr = err
+		}
+	}
+	return gerr
+}
+
+func (f *fileEvent) Log(id string) (event.Log, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	l, ok := f.logs[id]
+	if !ok {
+		var err error
+		l, err = openLog(filepath.Join(f.dir, url.PathEscape(f.opts.Prefix+id)), f.segmentSize)
+		if err != nil {
+			return nil, err
+		}
+		f.logs[id] = l
+	}
+	l.refs++
+
+	return &fileHandle{
+		id:    id,
+		event: f,
+		log:   l,
+	}, nil
+}
+
+// release closes the log once it has no open handles
+func (f *fileEvent) release(id string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	l, ok := f.logs[id]
+	if !ok {
+		return nil
+	}
+
+	l.refs--
+	if l.refs > 0 {
+		return nil
+	}
+
+	delete(f.logs, id)
+	return l.close()
+}
+
+func (h *fileHandle) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+
+	return h.event.release(h.id)
+}
+
+func (h *fileHandle) Id() string {
+	return h.id
+}
+
+func (h *fileHandle) Read() (*event.Record, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return nil, event.ErrClosed
+	}
+
+	r, err := h.log.read(h.offset)
+	if err != nil {
+		return nil, err
+	}
+	h.offset++
+
+	return r, nil
+}
+
+func (h *fileHandle) Seek(offset int64) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return event.ErrClosed
+	}
+
+	h.log.RLock()
+	next := h.log.next()
+	h.log.RUnlock()
+
+	if offset < 0 || offset > next {
+		return event.ErrInvalidOffset
+	}
+	h.offset = offset
+
+	return nil
+}
+
+func (h *fileHandle) Write(r *event.Record) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return event.ErrClosed
+	}
+
+	return h.log.write(r)
+}
+
+// NewEvent returns an event.Event which keeps each
+// log as a directory of segment files.
+func NewEvent(opts ...event.Option) event.Event {
+	options := event.Options{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	dir := DefaultDir
+	if d, ok := options.Context.Value(dirKey{}).(string); ok {
+		dir = d
+	}
+
+	segmentSize := DefaultSegmentSize
+	if s, ok := options.Context.Value(segmentSizeKey{}).(int64); ok {
+		segmentSize = s
+	}
+
+	return &fileEvent{
+		opts:        options,
+		dir:         dir,
+		segmentSize: segmentSize,
+		logs:        make(map[string]*fileLog),
+	}
+}
@@ -0,0 +1,88 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro/go-micro/sync/event"
+)
+
+func TestLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// small segments so the log rolls over
+	e := NewEvent(Dir(dir), SegmentSize(64))
+
+	l, err := e.Log("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		r := &event.Record{Data: []byte(fmt.Sprintf("event-%d", i))}
+		if err := l.Write(r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Offset != int64(i) {
+			t.Fatalf("expected offset %d got %d", i, r.Offset)
+		}
+	}
+	l.Close()
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("expected multiple segments got %d", len(files))
+	}
+
+	// reopen and read with two independent readers
+	r1, err := e.Log("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r1.Close()
+	r2, err := e.Log("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+
+	if err := r2.Seek(7); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		r, err := r1.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Offset != int64(i) || string(r.Data) != fmt.Sprintf("event-%d", i) {
+			t.Fatalf("unexpected record %d %s at %d", r.Offset, r.Data, i)
+		}
+	}
+	if _, err := r1.Read(); err != io.EOF {
+		t.Fatalf("expected EOF got %v", err)
+	}
+
+	r, err := r2.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(r.Data) != "event-7" {
+		t.Fatalf("expected event-7 got %s", r.Data)
+	}
+
+	if err := r2.Seek(11); err != event.ErrInvalidOffset {
+		t.Fatalf("expected invalid offset got %v", err)
+	}
+}
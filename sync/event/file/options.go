@@ -0,0 +1,30 @@
+package file
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/sync/event"
+)
+
+type dirKey struct{}
+type segmentSizeKey struct{}
+
+// Dir sets the directory the logs are kept in
+func Dir(d string) event.Option {
+	return func(o *event.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, dirKey{}, d)
+	}
+}
+
+// SegmentSize sets the size in bytes after which a new segment is started
+func SegmentSize(s int64) event.Option {
+	return func(o *event.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, segmentSizeKey{}, s)
+	}
+}
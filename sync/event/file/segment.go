@@ -0,0 +1,135 @@
+package file
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+)
+
+// Records are framed in a segment as
+//
+//	payload length (4) | crc32 (4) | payload
+//
+// and segments are named after the offset of their first record.
+
+const frameHeader = 8
+
+var errCorrupt = errors.New("corrupt record")
+
+type segment struct {
+	// offset of the first record
+	base int64
+	file *os.File
+	// position of each record in the file
+	positions []int64
+	size      int64
+}
+
+func segmentName(base int64) string {
+	return fmt.Sprintf("%020d.log", base)
+}
+
+// openSegment opens the segment and indexes its records. A partially
+// written record at the end is truncated if truncate is set.
+func openSegment(dir string, base int64, truncate bool) (*segment, error) {
+	file, err := os.OpenFile(filepath.Join(dir, segmentName(base)), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	s := &segment{
+		base: base,
+		file: file,
+	}
+
+	for s.size < info.Size() {
+		payload, err := s.readAt(s.size, info.Size())
+		if err != nil {
+			if !truncate {
+				file.Close()
+				return nil, err
+			}
+			if err := file.Truncate(s.size); err != nil {
+				file.Close()
+				return nil, err
+			}
+			break
+		}
+		s.positions = append(s.positions, s.size)
+		s.size += int64(frameHeader + len(payload))
+	}
+
+	return s, nil
+}
+
+// readAt returns the payload of the record at pos. The
+// record must end before end, the size of the segment.
+func (s *segment) readAt(pos, end int64) ([]byte, error) {
+	var h [frameHeader]byte
+	if pos+frameHeader > end {
+		return nil, errCorrupt
+	}
+	if _, err := s.file.ReadAt(h[:], pos); err != nil {
+		return nil, err
+	}
+
+	size := int64(binary.BigEndian.Uint32(h[0:4]))
+	if pos+frameHeader+size > end {
+		return nil, errCorrupt
+	}
+
+	payload := make([]byte, size)
+	if _, err := s.file.ReadAt(payload, pos+frameHeader); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(h[4:8]) {
+		return nil, errCorrupt
+	}
+
+	return payload, nil
+}
+
+// read returns the payload of the record at offset
+func (s *segment) read(offset int64) ([]byte, error) {
+	return s.readAt(s.positions[offset-s.base], s.size)
+}
+
+// append writes the payload to the end of the segment
+func (s *segment) append(payload []byte) error {
+	b := make([]byte, frameHeader+len(payload))
+	binary.BigEndian.PutUint32(b[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(b[4:8], crc32.ChecksumIEEE(payload))
+	copy(b[frameHeader:], payload)
+
+	if _, err := s.file.WriteAt(b, s.size); err != nil {
+		// drop anything partially written
+		s.file.Truncate(s.size)
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	s.positions = append(s.positions, s.size)
+	s.size += int64(len(b))
+
+	return nil
+}
+
+// next is the offset after the last record in the segment
+func (s *segment) next() int64 {
+	return s.base + int64(len(s.positions))
+}
+
+func (s *segment) close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,8 @@
+package event
+
+// Prefix sets a prefix to any log ids used
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
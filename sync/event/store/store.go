@@ -0,0 +1,234 @@
+// Package store is a store.Store backed implementation of event
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/micro/go-micro/store"
+	"github.com/micro/go-micro/store/memory"
+	"github.com/micro/go-micro/sync/event"
+)
+
+type storeKey struct{}
+
+type storeEvent struct {
+	opts  event.Options
+	store store.Store
+}
+
+// storeLog keeps each record under its own key. Writers claim the
+// next offset with a conditional write so concurrent writers, even
+// in different processes, never overwrite each other.
+type storeLog struct {
+	id     string
+	prefix string
+	store  store.Store
+
+	sync.Mutex
+	offset int64
+	// every offset below tail is known to be written
+	tail   int64
+	closed bool
+}
+
+// WithStore sets the store the logs are kept in
+func WithStore(s store.Store) event.Option {
+	return func(o *event.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, storeKey{}, s)
+	}
+}
+
+func (s *storeEvent) Log(id string) (event.Log, error) {
+	return &storeLog{
+		id:     id,
+		prefix: s.opts.Prefix + id + "/",
+		store:  s.store,
+	}, nil
+}
+
+// key is zero padded so keys sort in offset order
+func (l *storeLog) key(offset int64) string {
+	return fmt.Sprintf("%s%020d", l.prefix, offset)
+}
+
+func (l *storeLog) exists(offset int64) (bool, error) {
+	_, err := l.store.Read(l.key(offset))
+	if err == store.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// end finds the offset the next record will be written at
+// by probing forward from the known tail. The caller must
+// hold the lock.
+func (l *storeLog) end() (int64, error) {
+	lo, hi := l.tail, l.tail
+	step := int64(1)
+
+	// gallop until we find a missing offset
+	for {
+		ok, err := l.exists(hi)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		lo = hi + 1
+		hi = lo + step
+		step *= 2
+	}
+
+	// everything below lo exists and hi is missing
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		ok, err := l.exists(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	l.tail = lo
+	return lo, nil
+}
+
+func (l *storeLog) Close() error {
+	l.Lock()
+	l.closed = true
+	l.Unlock()
+	return nil
+}
+
+func (l *storeLog) Id() string {
+	return l.id
+}
+
+func (l *storeLog) Read() (*event.Record, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.closed {
+		return nil, event.ErrClosed
+	}
+
+	recs, err := l.store.Read(l.key(l.offset))
+	if err == store.ErrNotFound {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, err
+	}
+
+	r := new(event.Record)
+	if err := json.Unmarshal(recs[0].Value, r); err != nil {
+		return nil, err
+	}
+	r.Offset = l.offset
+	l.offset++
+
+	return r, nil
+}
+
+func (l *storeLog) Seek(offset int64) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.closed {
+		return event.ErrClosed
+	}
+
+	if offset < 0 {
+		return event.ErrInvalidOffset
+	}
+
+	if offset > l.tail {
+		end, err := l.end()
+		if err != nil {
+			return err
+		}
+		if offset > end {
+			return event.ErrInvalidOffset
+		}
+	}
+
+	l.offset = offset
+	return nil
+}
+
+func (l *storeLog) Write(r *event.Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.closed {
+		return event.ErrClosed
+	}
+
+	offset, err := l.end()
+	if err != nil {
+		return err
+	}
+
+	for {
+		err := l.store.Batch(store.WriteOp(&store.Record{
+			Key:   l.key(offset),
+			Value: b,
+		}, store.IfAbsent()))
+		if err == nil {
+			break
+		}
+		if err != store.ErrConflict {
+			return err
+		}
+		// another writer got there first
+		l.tail = offset + 1
+		if offset, err = l.end(); err != nil {
+			return err
+		}
+	}
+
+	r.Offset = offset
+	l.tail = offset + 1
+
+	return nil
+}
+
+// NewEvent returns an event.Event which keeps logs in a store.Store.
+// The store must support conditional writes.
+func NewEvent(opts ...event.Option) event.Event {
+	options := event.Options{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	s, ok := options.Context.Value(storeKey{}).(store.Store)
+	if !ok {
+		s = memory.NewStore()
+	}
+
+	return &storeEvent{
+		opts:  options,
+		store: s,
+	}
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/micro/go-micro/store/memory"
+	"github.com/micro/go-micro/sync/event"
+)
+
+func TestLog(t *testing.T) {
+	s := memory.NewStore()
+
+	e1 := NewEvent(WithStore(s))
+	e2 := NewEvent(WithStore(s))
+
+	w1, _ := e1.Log("test")
+	w2, _ := e2.Log("test")
+
+	// interleaved writers on the same store never overwrite each other
+	for i := 0; i < 5; i++ {
+		if err := w1.Write(&event.Record{Data: []byte(fmt.Sprintf("w1-%d", i))}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w2.Write(&event.Record{Data: []byte(fmt.Sprintf("w2-%d", i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, _ := e1.Log("test")
+	seen := make(map[string]bool)
+
+	for i := 0; i < 10; i++ {
+		rec, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Offset != int64(i) {
+			t.Fatalf("expected offset %d got %d", i, rec.Offset)
+		}
+		seen[string(rec.Data)] = true
+	}
+	if len(seen) != 10 {
+		t.Fatalf("expected 10 distinct records got %d", len(seen))
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Fatalf("expected EOF got %v", err)
+	}
+
+	if err := r.Seek(3); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Offset != 3 {
+		t.Fatalf("expected offset 3 got %d", rec.Offset)
+	}
+
+	if err := r.Seek(11); err != event.ErrInvalidOffset {
+		t.Fatalf("expected invalid offset got %v", err)
+	}
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/micro/go-micro/store"
 	ckv "github.com/micro/go-micro/store/etcd"
-	lock "github.com/micro/go-micro/sync/lock/etcd"
 )
 
 type syncMap struct {
@@ -28,12 +27,6 @@ func (m *syncMap) Read(key, val interface{}) error {
 
 	kstr := ekey(key)
 
-	// lock
-	if err := m.opts.Lock.Acquire(kstr); err != nil {
-		return err
-	}
-	defer m.opts.Lock.Release(kstr)
-
 	// get key
 	kval, err := m.opts.Store.Read(kstr)
 	if err != nil {
@@ -55,12 +48,6 @@ func (m *syncMap) Write(key, val interface{}) error {
 
 	kstr := ekey(key)
 
-	// lock
-	if err := m.opts.Lock.Acquire(kstr); err != nil {
-		return err
-	}
-	defer m.opts.Lock.Release(kstr)
-
 	// encode value
 	b, err := json.Marshal(val)
 	if err != nil {
@@ -74,18 +61,57 @@ func (m *syncMap) Write(key, val interface{}) error {
 	})
 }
 
-func (m *syncMap) Delete(key interface{}) error {
+func (m *syncMap) Update(key, val interface{}, fn func() error) error {
 	if key == nil {
 		return fmt.Errorf("key is nil")
 	}
 
 	kstr := ekey(key)
 
-	// lock
-	if err := m.opts.Lock.Acquire(kstr); err != nil {
-		return err
+	for {
+		// get the key and its version
+		var cond store.OpOption
+
+		kval, err := m.opts.Store.Read(kstr)
+		switch {
+		case err == store.ErrNotFound || (err == nil && len(kval) == 0):
+			cond = store.IfAbsent()
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal(kval[0].Value, val); err != nil {
+				return err
+			}
+			cond = store.IfVersion(kval[0].Version)
+		}
+
+		if err := fn(); err != nil {
+			return err
+		}
+
+		// encode value
+		b, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+
+		// set key unless it changed since it was read
+		err = m.opts.Store.Batch(store.WriteOp(&store.Record{
+			Key:   kstr,
+			Value: b,
+		}, cond))
+		if err != store.ErrConflict {
+			return err
+		}
 	}
-	defer m.opts.Lock.Release(kstr)
+}
+
+func (m *syncMap) Delete(key interface{}) error {
+	if key == nil {
+		return fmt.Errorf("key is nil")
+	}
+
+	kstr := ekey(key)
 	return m.opts.Store.Delete(kstr)
 }
 
@@ -100,13 +126,6 @@ func (m *syncMap) Iterate(fn func(key, val interface{}) error) error {
 	})
 
 	for _, keyval := range keyvals {
-		// lock
-		if err := m.opts.Lock.Acquire(keyval.Key); err != nil {
-			return err
-		}
-		// unlock
-		defer m.opts.Lock.Release(keyval.Key)
-
 		// unmarshal value
 		var val interface{}
 
@@ -131,14 +150,14 @@ func (m *syncMap) Iterate(fn func(key, val interface{}) error) error {
 
 		// no save
 		if i := bytes.Compare(keyval.Value, b); i == 0 {
-			return nil
+			continue
 		}
 
-		// set key
-		if err := m.opts.Store.Write(&store.Record{
+		// set key unless it changed since it was listed
+		if err := m.opts.Store.Batch(store.WriteOp(&store.Record{
 			Key:   keyval.Key,
 			Value: b,
-		}); err != nil {
+		}, store.IfVersion(keyval.Version))); err != nil {
 			return err
 		}
 	}
@@ -152,10 +171,6 @@ func NewMap(opts ...Option) Map {
 		o(&options)
 	}
 
-	if options.Lock == nil {
-		options.Lock = lock.NewLock()
-	}
-
 	if options.Store == nil {
 		options.Store = ckv.NewStore()
 	}
@@ -1,12 +1,13 @@
 package sync
 
 import (
+	gosync "sync"
 	"testing"
 	"time"
 
 	"github.com/micro/go-micro/store"
+	"github.com/micro/go-micro/store/memory"
 	store_mock "github.com/micro/go-micro/store/mock"
-	mem_lock "github.com/micro/go-micro/sync/lock/memory"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -23,18 +24,48 @@ func TestIterate(t *testing.T) {
 	s2 := &store_mock.Store{}
 	s1.On("List").Return([]*store.Record{recA, recB}, nil)
 	s2.On("List").Return([]*store.Record{recB, recA}, nil)
-	s1.On("Write", mock.Anything).Return(nil)
-	s2.On("Write", mock.Anything).Return(nil)
+	s1.On("Batch", mock.Anything).Return(nil)
+	s2.On("Batch", mock.Anything).Return(nil)
 
 	f := func(key, val interface{}) error {
 		time.Sleep(1 * time.Millisecond)
 		return nil
 	}
-	l := mem_lock.NewLock()
-	m1 := NewMap(WithStore(s1), WithLock(l))
-	m2 := NewMap(WithStore(s2), WithLock(l))
+	m1 := NewMap(WithStore(s1))
+	m2 := NewMap(WithStore(s2))
 	go func() {
 		m2.Iterate(f)
 	}()
 	m1.Iterate(f)
 }
+
+func TestUpdate(t *testing.T) {
+	m := NewMap(WithStore(memory.NewStore()))
+
+	var wg gosync.WaitGroup
+
+	// concurrent updates are not lost
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var n int
+			if err := m.Update("counter", &n, func() error {
+				n++
+				return nil
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var n int
+	if err := m.Read("counter", &n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Fatalf("Expected 10 got %d", n)
+	}
+}
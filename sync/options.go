@@ -14,7 +14,10 @@ func WithLeader(l leader.Leader) Option {
 	}
 }
 
-// WithLock sets the locking implementation option
+// WithLock sets the locking implementation option.
+//
+// Deprecated: the map uses the atomic operations of the store
+// and no longer locks, the option has no effect.
 func WithLock(l lock.Lock) Option {
 	return func(o *Options) {
 		o.Lock = l
@@ -10,13 +10,18 @@ import (
 )
 
 // Map provides synchronized access to key-value storage.
-// It uses the store interface and lock interface to
-// provide a consistent storage mechanism.
+// It uses the atomic operations of the store interface
+// to provide a consistent storage mechanism.
 type Map interface {
 	// Read value with given key
 	Read(key, val interface{}) error
-	// Write value with given key
+	// Write value with given key. Concurrent writes
+	// overwrite each other, use Update to avoid that.
 	Write(key, val interface{}) error
+	// Update reads the value with given key into val and calls fn
+	// to change it. The value is written unless the key changed
+	// since it was read, in which case the update is retried.
+	Update(key, val interface{}, fn func() error) error
 	// Delete value with given key
 	Delete(key interface{}) error
 	// Iterate over all key/vals. Value changes are saved
@@ -32,10 +37,12 @@ type Cron interface {
 
 type Options struct {
 	Leader leader.Leader
-	Lock   lock.Lock
-	Store  store.Store
-	Task   task.Task
-	Time   time.Time
+	// Deprecated: Lock is unused, the map uses the
+	// atomic operations of the store instead.
+	Lock  lock.Lock
+	Store store.Store
+	Task  task.Task
+	Time  time.Time
 }
 
 type Option func(o *Options)
@@ -105,6 +105,7 @@ func (t *grpcTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 				InsecureSkipVerify: true,
 			}
 		}
+		config = mls.VerifyService(config, dopts.Service)
 		creds := credentials.NewTLS(config)
 		options = append(options, grpc.WithTransportCredentials(creds))
 	} else {
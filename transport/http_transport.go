@@ -80,6 +80,14 @@ func (h *httpTransportClient) Remote() string {
 	return h.remote
 }
 
+func (h *httpTransportClient) ConnectionState() *tls.ConnectionState {
+	if c, ok := h.conn.(*tls.Conn); ok {
+		state := c.ConnectionState()
+		return &state
+	}
+	return nil
+}
+
 func (h *httpTransportClient) Send(m *Message) error {
 	header := make(http.Header)
 
@@ -188,6 +196,10 @@ func (h *httpTransportSocket) Remote() string {
 	return h.remote
 }
 
+func (h *httpTransportSocket) ConnectionState() *tls.ConnectionState {
+	return h.r.TLS
+}
+
 func (h *httpTransportSocket) Recv(m *Message) error {
 	if m == nil {
 		return errors.New("message passed in is nil")
@@ -497,6 +509,7 @@ func (h *httpTransport) Dial(addr string, opts ...DialOption) (Client, error) {
 				InsecureSkipVerify: true,
 			}
 		}
+		config = mls.VerifyService(config, dopts.Service)
 		config.NextProtos = []string{"http/1.1"}
 		conn, err = newConn(func(addr string) (net.Conn, error) {
 			return tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, "tcp", addr, config)
@@ -0,0 +1,217 @@
+package mux
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+)
+
+// conn is a connection of the underlying transport carrying streams
+type conn struct {
+	opts Options
+	sock transport.Socket
+	// timeout of Send and Recv on a stream
+	timeout time.Duration
+	// dialed by a client, closed once it has no streams
+	client bool
+
+	// serialises sends on the socket
+	smtx sync.Mutex
+
+	sync.Mutex
+	streams map[string]*stream
+	// id of the last stream opened
+	last uint64
+	// set once the connection fails
+	err error
+}
+
+func newConn(sock transport.Socket, opts Options, timeout time.Duration, client bool) *conn {
+	return &conn{
+		opts:    opts,
+		sock:    sock,
+		timeout: timeout,
+		client:  client,
+		streams: make(map[string]*stream),
+	}
+}
+
+func (c *conn) send(m *transport.Message) error {
+	c.smtx.Lock()
+	defer c.smtx.Unlock()
+	return c.sock.Send(m)
+}
+
+// sendStream sends a message of a stream. The first message
+// opens the stream on the remote.
+func (c *conn) sendStream(s *stream, m *transport.Message) error {
+	c.smtx.Lock()
+	defer c.smtx.Unlock()
+
+	if !s.opened {
+		m.Header[typeHeader] = typeOpen
+	}
+
+	if err := c.sock.Send(m); err != nil {
+		return err
+	}
+
+	s.opened = true
+
+	return nil
+}
+
+// control sends a control frame for a stream
+func (c *conn) control(id, typ string, window int) error {
+	header := map[string]string{
+		StreamHeader: id,
+		typeHeader:   typ,
+	}
+	if window > 0 {
+		header[windowHeader] = strconv.Itoa(window)
+	}
+	return c.send(&transport.Message{Header: header})
+}
+
+// open opens a new stream. It returns nil if the connection
+// failed or has the maximum number of streams.
+func (c *conn) open() *stream {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.err != nil {
+		return nil
+	}
+
+	if c.opts.MaxStreams > 0 && len(c.streams) >= c.opts.MaxStreams {
+		return nil
+	}
+
+	c.last++
+	s := newStream(c, strconv.FormatUint(c.last, 10))
+	c.streams[s.id] = s
+	return s
+}
+
+// remove removes a stream, closing client connections left without any
+func (c *conn) remove(id string) {
+	c.Lock()
+	delete(c.streams, id)
+	idle := c.idle()
+	c.Unlock()
+
+	if idle {
+		c.sock.Close()
+	}
+}
+
+// idle marks a client connection without streams as closed so no more
+// streams are opened on it. It returns true if the socket should be
+// closed. The lock must be held.
+func (c *conn) idle() bool {
+	if !c.client || len(c.streams) > 0 || c.err != nil {
+		return false
+	}
+	c.err = ErrConnClosed
+	return true
+}
+
+// close fails the connection and all its streams
+func (c *conn) close(err error) {
+	c.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	streams := c.streams
+	c.streams = make(map[string]*stream)
+	c.Unlock()
+
+	for _, s := range streams {
+		s.fail(err)
+	}
+
+	c.sock.Close()
+}
+
+// run reads frames from the connection until it fails. Streams opened
+// by the remote are passed to accept, or refused if accept is nil.
+func (c *conn) run(accept func(*stream)) {
+	for {
+		var m transport.Message
+		if err := c.sock.Recv(&m); err != nil {
+			if err == io.EOF {
+				err = ErrConnClosed
+			}
+			c.close(err)
+			return
+		}
+
+		id := m.Header[StreamHeader]
+		typ := m.Header[typeHeader]
+
+		c.Lock()
+		s, ok := c.streams[id]
+
+		switch typ {
+		case typeWindow:
+			c.Unlock()
+			if ok {
+				n, _ := strconv.Atoi(m.Header[windowHeader])
+				s.grow(n)
+			}
+			continue
+		case typeClose, typeReset:
+			delete(c.streams, id)
+			idle := c.idle()
+			c.Unlock()
+			if idle {
+				c.sock.Close()
+			}
+			if !ok {
+				continue
+			}
+			if typ == typeReset {
+				s.fail(ErrStreamRefused)
+			} else {
+				s.fail(io.EOF)
+			}
+			continue
+		}
+
+		// a message for a new stream
+		if !ok {
+			// ignore messages for streams which were closed
+			if accept == nil || typ != typeOpen {
+				c.Unlock()
+				continue
+			}
+
+			// refuse streams over the limit
+			if c.opts.MaxStreams > 0 && len(c.streams) >= c.opts.MaxStreams {
+				c.Unlock()
+				c.control(id, typeReset, 0)
+				continue
+			}
+
+			s = newStream(c, id)
+			s.opened = true
+			c.streams[id] = s
+			accept(s)
+		}
+
+		c.Unlock()
+
+		delete(m.Header, StreamHeader)
+		delete(m.Header, typeHeader)
+
+		if !s.push(&m) {
+			// drop streams which ignore the window
+			c.control(id, typeReset, 0)
+			c.remove(id)
+			s.reset(ErrWindowExceeded)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package mux multiplexes many sockets over a single connection of
+// another transport. Streams have their own flow control so a slow
+// stream doesn't hold up the others. The underlying transport has to
+// send and receive independently such as tcp, unix, grpc, quic or memory.
+// Both the client and server have to use the mux. The Send and Recv
+// timeout of the transport applies to each stream rather than the
+// connection, which is idle between requests.
+package mux
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+)
+
+const (
+	// StreamHeader is the id of the stream of a message
+	StreamHeader = "Micro-Mux-Stream"
+
+	typeHeader   = "Micro-Mux-Type"
+	windowHeader = "Micro-Mux-Window"
+
+	// the first message of a stream
+	typeOpen = "open"
+	// the receiver read bytes of the window
+	typeWindow = "window"
+	// the stream was closed
+	typeClose = "close"
+	// the stream was refused or broke the flow control
+	typeReset = "reset"
+)
+
+var (
+	// DefaultMaxStreams is the number of streams on a connection
+	DefaultMaxStreams = 100
+
+	// DefaultWindow is the flow control window of a stream in bytes
+	DefaultWindow = 1024 * 1024
+
+	// ErrStreamClosed is returned when using a closed stream
+	ErrStreamClosed = errors.New("stream closed")
+	// ErrStreamRefused is returned when the server has too many streams
+	ErrStreamRefused = errors.New("stream refused")
+	// ErrWindowExceeded is returned when the remote sends
+	// more on a stream than its flow control window allows
+	ErrWindowExceeded = errors.New("stream window exceeded")
+	// ErrConnClosed is returned when the connection of a stream closes
+	ErrConnClosed = errors.New("connection closed")
+	// ErrTimeout is returned when Send or Recv on a stream times out
+	ErrTimeout = errors.New("stream timeout")
+)
+
+// Supported returns true if the transport can be multiplexed
+func Supported(t transport.Transport) bool {
+	switch t.String() {
+	case "tcp", "unix", "grpc", "quic", "memory":
+		return true
+	}
+	return false
+}
+
+type muxTransport struct {
+	transport.Transport
+	opts Options
+
+	sync.Mutex
+	// timeout of Send and Recv on a stream
+	timeout time.Duration
+	// client connections by address and service
+	conns map[string][]*conn
+}
+
+type muxListener struct {
+	transport.Listener
+	t *muxTransport
+}
+
+func (l *muxListener) Accept(fn func(transport.Socket)) error {
+	return l.Listener.Accept(func(sock transport.Socket) {
+		c := newConn(sock, l.t.opts, l.t.getTimeout(), false)
+		c.run(func(s *stream) {
+			go func() {
+				fn(s)
+				s.Close()
+			}()
+		})
+	})
+}
+
+// Dial opens a stream on a connection to the address, dialing a new
+// connection if all the existing ones have the maximum number of streams
+func (t *muxTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	var options transport.DialOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// conns verified for a service aren't shared with other services
+	key := addr
+	if len(options.Service) > 0 {
+		key = options.Service + "@" + addr
+	}
+
+	t.Lock()
+	for _, c := range t.conns[key] {
+		if s := c.open(); s != nil {
+			t.Unlock()
+			return s, nil
+		}
+	}
+	t.Unlock()
+
+	sock, err := t.Transport.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newConn(sock, t.opts, t.getTimeout(), true)
+	s := c.open()
+
+	t.Lock()
+	t.conns[key] = append(t.conns[key], c)
+	t.Unlock()
+
+	go func() {
+		c.run(nil)
+
+		// remove the failed connection
+		t.Lock()
+		conns := t.conns[key]
+		for i, cc := range conns {
+			if cc == c {
+				conns = append(conns[:i], conns[i+1:]...)
+				break
+			}
+		}
+		if len(conns) == 0 {
+			delete(t.conns, key)
+		} else {
+			t.conns[key] = conns
+		}
+		t.Unlock()
+	}()
+
+	return s, nil
+}
+
+func (t *muxTransport) getTimeout() time.Duration {
+	t.Lock()
+	defer t.Unlock()
+	return t.timeout
+}
+
+// Init initialises the underlying transport. The timeout is kept
+// for the streams and connections are created without one.
+func (t *muxTransport) Init(opts ...transport.Option) error {
+	options := t.Transport.Options()
+	options.Timeout = t.getTimeout()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	t.Lock()
+	t.timeout = options.Timeout
+	t.Unlock()
+
+	return t.Transport.Init(append(opts, transport.Timeout(0))...)
+}
+
+func (t *muxTransport) Options() transport.Options {
+	opts := t.Transport.Options()
+	opts.Timeout = t.getTimeout()
+	return opts
+}
+
+func (t *muxTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
+	l, err := t.Transport.Listen(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &muxListener{
+		Listener: l,
+		t:        t,
+	}, nil
+}
+
+// NewTransport returns a transport multiplexing sockets over the
+// connections of the given transport. Use Supported to check the
+// transport can be multiplexed.
+func NewTransport(t transport.Transport, opts ...Option) transport.Transport {
+	options := Options{
+		MaxStreams: DefaultMaxStreams,
+		Window:     DefaultWindow,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	mt := &muxTransport{
+		Transport: t,
+		opts:      options,
+		timeout:   t.Options().Timeout,
+		conns:     make(map[string][]*conn),
+	}
+
+	// move the timeout from the connections to the streams
+	mt.Init()
+
+	return mt
+}
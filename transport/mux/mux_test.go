@@ -0,0 +1,361 @@
+package mux
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+	"github.com/micro/go-micro/transport/tcp"
+)
+
+func echo(sock transport.Socket) {
+	for {
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		if err := sock.Send(&m); err != nil {
+			return
+		}
+	}
+}
+
+func listen(t *testing.T, tr transport.Transport, fn func(transport.Socket)) transport.Listener {
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	go l.Accept(fn)
+	return l
+}
+
+func TestStreams(t *testing.T) {
+	tr := NewTransport(tcp.NewTransport())
+
+	l := listen(t, tr, echo)
+	defer l.Close()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		c, err := tr.Dial(l.Addr())
+		if err != nil {
+			t.Fatalf("Unexpected error dialing %v", err)
+		}
+		defer c.Close()
+
+		wg.Add(1)
+		go func(i int, c transport.Client) {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				body := fmt.Sprintf("%d-%d", i, j)
+				if err := c.Send(&transport.Message{
+					Header: map[string]string{"Foo": "bar"},
+					Body:   []byte(body),
+				}); err != nil {
+					t.Errorf("Unexpected error sending %v", err)
+					return
+				}
+
+				var m transport.Message
+				if err := c.Recv(&m); err != nil {
+					t.Errorf("Unexpected error receiving %v", err)
+					return
+				}
+
+				if string(m.Body) != body || m.Header["Foo"] != "bar" || len(m.Header) != 1 {
+					t.Errorf("Expected %s got %+v", body, m)
+					return
+				}
+			}
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	// all the streams share a connection
+	if n := len(tr.(*muxTransport).conns[l.Addr()]); n != 1 {
+		t.Fatalf("Expected 1 connection got %d", n)
+	}
+}
+
+func TestMaxStreams(t *testing.T) {
+	tr := NewTransport(tcp.NewTransport(), MaxStreams(2))
+
+	l := listen(t, tr, echo)
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		c, err := tr.Dial(l.Addr())
+		if err != nil {
+			t.Fatalf("Unexpected error dialing %v", err)
+		}
+		defer c.Close()
+	}
+
+	if n := len(tr.(*muxTransport).conns[l.Addr()]); n != 2 {
+		t.Fatalf("Expected 2 connections got %d", n)
+	}
+
+	// servers refuse streams over the limit
+	ctr := NewTransport(tcp.NewTransport(), MaxStreams(3))
+	for i := 0; i < 3; i++ {
+		c, err := ctr.Dial(l.Addr())
+		if err != nil {
+			t.Fatalf("Unexpected error dialing %v", err)
+		}
+		defer c.Close()
+
+		if err := c.Send(&transport.Message{Body: []byte(`ping`)}); err != nil {
+			t.Fatalf("Unexpected error sending %v", err)
+		}
+
+		var m transport.Message
+		err = c.Recv(&m)
+		if i < 2 && err != nil {
+			t.Fatalf("Unexpected error receiving %v", err)
+		}
+		if i == 2 && err != ErrStreamRefused {
+			t.Fatalf("Expected %v got %v", ErrStreamRefused, err)
+		}
+	}
+}
+
+func TestFlowControl(t *testing.T) {
+	tr := NewTransport(tcp.NewTransport(), Window(10))
+
+	read := make(chan bool)
+	received := make(chan string, 3)
+
+	l := listen(t, tr, func(sock transport.Socket) {
+		<-read
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			received <- string(m.Body)
+		}
+	})
+	defer l.Close()
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	sent := make(chan bool, 3)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			if err := c.Send(&transport.Message{Body: []byte(`12345678`)}); err != nil {
+				return
+			}
+			sent <- true
+		}
+	}()
+
+	// the third message waits for the window
+	<-sent
+	<-sent
+	select {
+	case <-sent:
+		t.Fatal("Expected send to wait for the window")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// reading opens the window
+	close(read)
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Expected send after the window opened")
+	}
+
+	for i := 0; i < 3; i++ {
+		<-received
+	}
+}
+
+func TestWindowExceeded(t *testing.T) {
+	tr := NewTransport(tcp.NewTransport(), Window(10))
+
+	read := make(chan bool)
+	errs := make(chan error, 1)
+
+	l := listen(t, tr, func(sock transport.Socket) {
+		<-read
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				errs <- err
+				return
+			}
+		}
+	})
+	defer l.Close()
+
+	// a client which ignores the window
+	c, err := tcp.NewTransport().Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		header := map[string]string{StreamHeader: "1"}
+		if i == 0 {
+			header[typeHeader] = typeOpen
+		}
+		if err := c.Send(&transport.Message{Header: header, Body: []byte(`12345678`)}); err != nil {
+			t.Fatalf("Unexpected error sending %v", err)
+		}
+	}
+
+	// the stream is reset
+	var m transport.Message
+	if err := c.Recv(&m); err != nil {
+		t.Fatalf("Unexpected error receiving %v", err)
+	}
+	if m.Header[StreamHeader] != "1" || m.Header[typeHeader] != typeReset {
+		t.Fatalf("Expected reset of stream 1 got %v", m.Header)
+	}
+
+	close(read)
+
+	select {
+	case err := <-errs:
+		if err != ErrWindowExceeded {
+			t.Fatalf("Expected %v got %v", ErrWindowExceeded, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream to fail")
+	}
+}
+
+func TestClose(t *testing.T) {
+	tr := NewTransport(tcp.NewTransport())
+
+	errs := make(chan error, 1)
+
+	l := listen(t, tr, func(sock transport.Socket) {
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				errs <- err
+				return
+			}
+		}
+	})
+	defer l.Close()
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+
+	if err := c.Send(&transport.Message{Body: []byte(`ping`)}); err != nil {
+		t.Fatalf("Unexpected error sending %v", err)
+	}
+
+	c.Close()
+
+	// the server sees the end of the stream
+	if err := <-errs; err != io.EOF {
+		t.Fatalf("Expected %v got %v", io.EOF, err)
+	}
+
+	if err := c.Send(&transport.Message{}); err != ErrStreamClosed {
+		t.Fatalf("Expected %v got %v", ErrStreamClosed, err)
+	}
+}
+
+func TestIdleConn(t *testing.T) {
+	tr := NewTransport(tcp.NewTransport())
+
+	l := listen(t, tr, echo)
+	defer l.Close()
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	c.Close()
+
+	// connections without streams are closed
+	mt := tr.(*muxTransport)
+	for i := 0; i < 100; i++ {
+		mt.Lock()
+		n := len(mt.conns[l.Addr()])
+		mt.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected the idle connection to be closed")
+}
+
+func TestTimeout(t *testing.T) {
+	tr := NewTransport(tcp.NewTransport(transport.Timeout(50 * time.Millisecond)))
+
+	// the timeout is kept for the streams
+	if d := tr.Options().Timeout; d != 50*time.Millisecond {
+		t.Fatalf("Expected timeout 50ms got %v", d)
+	}
+
+	l := listen(t, tr, func(sock transport.Socket) {
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		// reply to the first message only
+		sock.Send(&m)
+		<-time.After(time.Second)
+	})
+	defer l.Close()
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	// keep another stream open on the connection
+	o, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer o.Close()
+
+	// idle connections don't time out
+	time.Sleep(100 * time.Millisecond)
+
+	if err := c.Send(&transport.Message{Body: []byte(`ping`)}); err != nil {
+		t.Fatalf("Unexpected error sending %v", err)
+	}
+	var m transport.Message
+	if err := c.Recv(&m); err != nil {
+		t.Fatalf("Unexpected error receiving %v", err)
+	}
+
+	// streams time out
+	if err := c.Recv(&m); err != ErrTimeout {
+		t.Fatalf("Expected %v got %v", ErrTimeout, err)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	if !Supported(tcp.NewTransport()) {
+		t.Fatal("Expected tcp to be supported")
+	}
+	if Supported(transport.NewTransport()) {
+		t.Fatal("Expected http not to be supported")
+	}
+}
@@ -0,0 +1,28 @@
+package mux
+
+type Options struct {
+	// MaxStreams is the number of streams on a connection. Clients
+	// open another connection when all of theirs are full and servers
+	// refuse streams over the limit.
+	MaxStreams int
+	// Window is the number of bytes sent on a stream before the
+	// receiver has to read them. Receivers reset streams which send
+	// more so both ends have to use the same window.
+	Window int
+}
+
+type Option func(o *Options)
+
+// MaxStreams sets the number of streams on a connection
+func MaxStreams(n int) Option {
+	return func(o *Options) {
+		o.MaxStreams = n
+	}
+}
+
+// Window sets the flow control window of a stream in bytes
+func Window(n int) Option {
+	return func(o *Options) {
+		o.Window = n
+	}
+}
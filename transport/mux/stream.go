@@ -0,0 +1,268 @@
+package mux
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+)
+
+// stream is a socket multiplexed on a connection
+type stream struct {
+	id   string
+	conn *conn
+
+	// signalled when a message arrives or the stream closes
+	recvc chan bool
+	// signalled when the window grows or the stream closes
+	sendc chan bool
+
+	sync.Mutex
+	queue []*transport.Message
+	// bytes of the messages queued
+	queued int
+	// set when the stream is closed locally
+	closed bool
+	// set when the remote closes the stream or the connection fails
+	err error
+	// bytes which can be sent before the receiver reads them
+	credit int
+	// bytes read since the last window update
+	consumed int
+	// set once the first message opened the stream on the remote
+	opened bool
+}
+
+func newStream(c *conn, id string) *stream {
+	return &stream{
+		id:     id,
+		conn:   c,
+		recvc:  make(chan bool, 1),
+		sendc:  make(chan bool, 1),
+		credit: c.opts.Window,
+	}
+}
+
+func signal(ch chan bool) {
+	select {
+	case ch <- true:
+	default:
+	}
+}
+
+// wait waits for a signal on the channel until the timeout
+func wait(ch chan bool, timeout <-chan time.Time) error {
+	select {
+	case <-ch:
+		return nil
+	case <-timeout:
+		return ErrTimeout
+	}
+}
+
+// deadline returns a channel signalled once the timeout of the stream
+// passes or nil if there is no timeout. The timer is stopped by stop.
+func (s *stream) deadline() (<-chan time.Time, func()) {
+	if s.conn.timeout <= 0 {
+		return nil, func() {}
+	}
+	t := time.NewTimer(s.conn.timeout)
+	return t.C, func() { t.Stop() }
+}
+
+// size of a message counted against the window
+func size(m *transport.Message) int {
+	n := len(m.Body)
+	for k, v := range m.Header {
+		n += len(k) + len(v)
+	}
+	return n
+}
+
+// push queues a message received from the remote. It returns false
+// if the remote ignored the window and sent more than it allows.
+func (s *stream) push(m *transport.Message) bool {
+	s.Lock()
+	// senders wait for the window once it's used up
+	if s.queued >= s.conn.opts.Window {
+		s.Unlock()
+		return false
+	}
+	s.queue = append(s.queue, m)
+	s.queued += size(m)
+	s.Unlock()
+	signal(s.recvc)
+	return true
+}
+
+// reset drops the queued messages and fails the stream
+func (s *stream) reset(err error) {
+	s.Lock()
+	s.queue = nil
+	s.queued = 0
+	s.Unlock()
+	s.fail(err)
+}
+
+// grow adds to the window after the remote read messages
+func (s *stream) grow(n int) {
+	s.Lock()
+	s.credit += n
+	s.Unlock()
+	signal(s.sendc)
+}
+
+// fail closes the stream from the remote side
+func (s *stream) fail(err error) {
+	s.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.Unlock()
+	signal(s.recvc)
+	signal(s.sendc)
+}
+
+func (s *stream) Recv(m *transport.Message) error {
+	if m == nil {
+		return errors.New("message passed in is nil")
+	}
+
+	timeout, stop := s.deadline()
+	defer stop()
+
+	for {
+		s.Lock()
+
+		if s.closed {
+			s.Unlock()
+			return ErrStreamClosed
+		}
+
+		if len(s.queue) == 0 {
+			err := s.err
+			s.Unlock()
+			// return the error once all messages are read
+			if err != nil {
+				return err
+			}
+			if err := wait(s.recvc, timeout); err != nil {
+				return err
+			}
+			continue
+		}
+
+		msg := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+
+		// let the sender know once half the window is read
+		var update int
+		s.queued -= size(msg)
+		s.consumed += size(msg)
+		if s.consumed >= s.conn.opts.Window/2 {
+			update = s.consumed
+			s.consumed = 0
+		}
+
+		s.Unlock()
+
+		if update > 0 {
+			s.conn.control(s.id, typeWindow, update)
+		}
+
+		if m.Header == nil {
+			m.Header = msg.Header
+		} else {
+			for k, v := range msg.Header {
+				m.Header[k] = v
+			}
+		}
+		m.Body = msg.Body
+
+		return nil
+	}
+}
+
+func (s *stream) Send(m *transport.Message) error {
+	n := size(m)
+
+	timeout, stop := s.deadline()
+	defer stop()
+
+	// wait for the window to open. A message larger
+	// than what's left of the window is still sent.
+	for {
+		s.Lock()
+
+		if s.closed {
+			s.Unlock()
+			return ErrStreamClosed
+		}
+
+		if s.err != nil {
+			err := s.err
+			s.Unlock()
+			return err
+		}
+
+		if s.credit > 0 {
+			s.credit -= n
+			s.Unlock()
+			break
+		}
+
+		s.Unlock()
+		if err := wait(s.sendc, timeout); err != nil {
+			return err
+		}
+	}
+
+	header := make(map[string]string, len(m.Header)+1)
+	for k, v := range m.Header {
+		header[k] = v
+	}
+	header[StreamHeader] = s.id
+
+	return s.conn.sendStream(s, &transport.Message{
+		Header: header,
+		Body:   m.Body,
+	})
+}
+
+func (s *stream) Close() error {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return nil
+	}
+	s.closed = true
+	remote := s.err != nil
+	s.Unlock()
+
+	signal(s.recvc)
+	signal(s.sendc)
+
+	// tell the remote unless it closed the stream
+	if !remote {
+		s.conn.control(s.id, typeClose, 0)
+	}
+
+	s.conn.remove(s.id)
+
+	return nil
+}
+
+func (s *stream) Local() string {
+	return s.conn.sock.Local()
+}
+
+func (s *stream) Remote() string {
+	return s.conn.sock.Remote()
+}
+
+func (s *stream) ConnectionState() *tls.ConnectionState {
+	return transport.ConnectionState(s.conn.sock)
+}
@@ -34,6 +34,9 @@ type DialOptions struct {
 	Stream bool
 	// Timeout for dialing
 	Timeout time.Duration
+	// Service expected at the address. Secure transports
+	// check the peer certificate names the service.
+	Service string
 
 	// TODO: add tls options when dialling
 	// Currently set in global options
@@ -102,3 +105,10 @@ func WithTimeout(d time.Duration) DialOption {
 		o.Timeout = d
 	}
 }
+
+// WithService sets the service expected at the address
+func WithService(s string) DialOption {
+	return func(o *DialOptions) {
+		o.Service = s
+	}
+}
@@ -117,6 +117,7 @@ func (q *quicTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 			NextProtos:         []string{"http/1.1"},
 		}
 	}
+	config = utls.VerifyService(config, options.Service)
 	s, err := quic.DialAddr(addr, config, &quic.Config{
 		IdleTimeout: time.Minute * 2,
 		KeepAlive:   true,
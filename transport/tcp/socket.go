@@ -0,0 +1,217 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+)
+
+var (
+	// MaxMessageSize is the largest frame read from a connection
+	MaxMessageSize = 1024 * 1024 * 64
+
+	// ErrMessageTooLarge is returned when a frame exceeds MaxMessageSize
+	ErrMessageTooLarge = errors.New("message too large")
+
+	// most memory allocated for a frame before it has been received
+	// so peers can't make us allocate by sending large lengths alone
+	recvChunkSize = 64 * 1024
+)
+
+// socket sends and receives messages as frames on a connection. A frame is
+//
+//	uint32 length of the rest of the frame
+//	uint32 number of headers
+//	uint32 length of the key, key, uint32 length of the value, value
+//	body
+//
+// with all the integers big endian.
+type socket struct {
+	conn    net.Conn
+	timeout time.Duration
+
+	// one sender and one receiver at a time
+	smtx sync.Mutex
+	w    *bufio.Writer
+	rmtx sync.Mutex
+	r    *bufio.Reader
+}
+
+// NewSocket returns a socket sending length prefixed frames on the
+// connection. Send and Recv fail if they take longer than the timeout.
+func NewSocket(conn net.Conn, timeout time.Duration) transport.Socket {
+	return &socket{
+		conn:    conn,
+		timeout: timeout,
+		w:       bufio.NewWriter(conn),
+		r:       bufio.NewReader(conn),
+	}
+}
+
+func (s *socket) Local() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *socket) Remote() string {
+	return s.conn.RemoteAddr().String()
+}
+
+func (s *socket) ConnectionState() *tls.ConnectionState {
+	if c, ok := s.conn.(*tls.Conn); ok {
+		state := c.ConnectionState()
+		return &state
+	}
+	return nil
+}
+
+func (s *socket) Recv(m *transport.Message) error {
+	if m == nil {
+		return errors.New("message passed in is nil")
+	}
+
+	s.rmtx.Lock()
+	defer s.rmtx.Unlock()
+
+	// set timeout if its greater than 0
+	if s.timeout > time.Duration(0) {
+		s.conn.SetReadDeadline(time.Now().Add(s.timeout))
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(s.r, size[:]); err != nil {
+		return err
+	}
+
+	n := binary.BigEndian.Uint32(size[:])
+	if n > uint32(MaxMessageSize) {
+		return ErrMessageTooLarge
+	}
+
+	// the buffer grows as the frame is received
+	var buf bytes.Buffer
+	if n < uint32(recvChunkSize) {
+		buf.Grow(int(n))
+	} else {
+		buf.Grow(recvChunkSize)
+	}
+
+	if _, err := io.CopyN(&buf, s.r, int64(n)); err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+
+	return decode(buf.Bytes(), m)
+}
+
+func (s *socket) Send(m *transport.Message) error {
+	// peers reject frames which are too large
+	if size(m)-4 > MaxMessageSize {
+		return ErrMessageTooLarge
+	}
+
+	buf := encode(m)
+
+	s.smtx.Lock()
+	defer s.smtx.Unlock()
+
+	// set timeout if its greater than 0
+	if s.timeout > time.Duration(0) {
+		s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	}
+
+	if _, err := s.w.Write(buf); err != nil {
+		return err
+	}
+
+	return s.w.Flush()
+}
+
+func (s *socket) Close() error {
+	return s.conn.Close()
+}
+
+// size returns the size of the frame of a message including its length
+func size(m *transport.Message) int {
+	n := 8 + len(m.Body)
+	for k, v := range m.Header {
+		n += 8 + len(k) + len(v)
+	}
+	return n
+}
+
+// encode returns the frame of a message including its length
+func encode(m *transport.Message) []byte {
+	n := size(m)
+
+	buf := make([]byte, n)
+	binary.BigEndian.PutUint32(buf, uint32(n-4))
+	binary.BigEndian.PutUint32(buf[4:], uint32(len(m.Header)))
+
+	i := 8
+	for k, v := range m.Header {
+		binary.BigEndian.PutUint32(buf[i:], uint32(len(k)))
+		i += 4 + copy(buf[i+4:], k)
+		binary.BigEndian.PutUint32(buf[i:], uint32(len(v)))
+		i += 4 + copy(buf[i+4:], v)
+	}
+
+	copy(buf[i:], m.Body)
+
+	return buf
+}
+
+// decode reads a frame without its length into the message
+func decode(buf []byte, m *transport.Message) error {
+	if len(buf) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+
+	n := binary.BigEndian.Uint32(buf)
+	buf = buf[4:]
+
+	// each header is at least 8 bytes
+	if uint64(n)*8 > uint64(len(buf)) {
+		return io.ErrUnexpectedEOF
+	}
+
+	// read a length prefixed string
+	next := func() (string, error) {
+		if len(buf) < 4 {
+			return "", io.ErrUnexpectedEOF
+		}
+		l := binary.BigEndian.Uint32(buf)
+		if uint64(l) > uint64(len(buf)-4) {
+			return "", io.ErrUnexpectedEOF
+		}
+		s := string(buf[4 : 4+l])
+		buf = buf[4+l:]
+		return s, nil
+	}
+
+	m.Header = make(map[string]string, n)
+
+	for i := uint32(0); i < n; i++ {
+		k, err := next()
+		if err != nil {
+			return err
+		}
+		v, err := next()
+		if err != nil {
+			return err
+		}
+		m.Header[k] = v
+	}
+
+	m.Body = buf
+
+	return nil
+}
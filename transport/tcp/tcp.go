@@ -0,0 +1,182 @@
+// Package tcp provides a raw tcp transport sending length prefixed frames
+// of the message headers and body. It skips the http handshake of the
+// default transport on every connection.
+package tcp
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+	maddr "github.com/micro/go-micro/util/addr"
+	mnet "github.com/micro/go-micro/util/net"
+	mls "github.com/micro/go-micro/util/tls"
+)
+
+type tcpTransport struct {
+	opts transport.Options
+}
+
+type tcpListener struct {
+	l       net.Listener
+	timeout time.Duration
+}
+
+// NewListener returns a listener accepting sockets which send length
+// prefixed frames. Send and Recv fail if they take longer than the timeout.
+func NewListener(l net.Listener, timeout time.Duration) transport.Listener {
+	return &tcpListener{
+		l:       l,
+		timeout: timeout,
+	}
+}
+
+func (t *tcpListener) Addr() string {
+	return t.l.Addr().String()
+}
+
+func (t *tcpListener) Close() error {
+	return t.l.Close()
+}
+
+func (t *tcpListener) Accept(fn func(transport.Socket)) error {
+	var tempDelay time.Duration
+
+	for {
+		c, err := t.l.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				if tempDelay == 0 {
+					tempDelay = 5 * time.Millisecond
+				} else {
+					tempDelay *= 2
+				}
+				if max := 1 * time.Second; tempDelay > max {
+					tempDelay = max
+				}
+				time.Sleep(tempDelay)
+				continue
+			}
+			return err
+		}
+
+		tempDelay = 0
+
+		go func() {
+			sock := NewSocket(c, t.timeout)
+			defer sock.Close()
+			fn(sock)
+		}()
+	}
+}
+
+func (t *tcpTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	dopts := transport.DialOptions{
+		Timeout: transport.DefaultDialTimeout,
+	}
+
+	for _, o := range opts {
+		o(&dopts)
+	}
+
+	var conn net.Conn
+	var err error
+
+	if t.opts.Secure || t.opts.TLSConfig != nil {
+		config := t.opts.TLSConfig
+		if config == nil {
+			config = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+		config = mls.VerifyService(config, dopts.Service)
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, "tcp", addr, config)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, dopts.Timeout)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSocket(conn, t.opts.Timeout), nil
+}
+
+func (t *tcpTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
+	var options transport.ListenOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var l net.Listener
+	var err error
+
+	if t.opts.Secure || t.opts.TLSConfig != nil {
+		config := t.opts.TLSConfig
+
+		fn := func(addr string) (net.Listener, error) {
+			if config == nil {
+				hosts := []string{addr}
+
+				// check if its a valid host:port
+				if host, _, err := net.SplitHostPort(addr); err == nil {
+					if len(host) == 0 {
+						hosts = maddr.IPs()
+					} else {
+						hosts = []string{host}
+					}
+				}
+
+				// generate a certificate
+				cert, err := mls.Certificate(hosts...)
+				if err != nil {
+					return nil, err
+				}
+				config = &tls.Config{Certificates: []tls.Certificate{cert}}
+			}
+			return tls.Listen("tcp", addr, config)
+		}
+
+		l, err = mnet.Listen(addr, fn)
+	} else {
+		fn := func(addr string) (net.Listener, error) {
+			return net.Listen("tcp", addr)
+		}
+
+		l, err = mnet.Listen(addr, fn)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewListener(l, t.opts.Timeout), nil
+}
+
+func (t *tcpTransport) Init(opts ...transport.Option) error {
+	for _, o := range opts {
+		o(&t.opts)
+	}
+	return nil
+}
+
+func (t *tcpTransport) Options() transport.Options {
+	return t.opts
+}
+
+func (t *tcpTransport) String() string {
+	return "tcp"
+}
+
+// NewTransport returns a new tcp transport
+func NewTransport(opts ...transport.Option) transport.Transport {
+	var options transport.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &tcpTransport{
+		opts: options,
+	}
+}
@@ -0,0 +1,345 @@
+package tcp
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/transport"
+	mls "github.com/micro/go-micro/util/tls"
+)
+
+func testTransport(t *testing.T, tr transport.Transport, addr string) {
+	l, err := tr.Listen(addr)
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	go func() {
+		l.Accept(func(sock transport.Socket) {
+			for {
+				var m transport.Message
+				if err := sock.Recv(&m); err != nil {
+					return
+				}
+				m.Header["Pong"] = "true"
+				if err := sock.Send(&m); err != nil {
+					return
+				}
+			}
+		})
+	}()
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := c.Send(&transport.Message{
+			Header: map[string]string{"Content-Type": "application/json"},
+			Body:   []byte(`{"message": "ping"}`),
+		}); err != nil {
+			t.Fatalf("Unexpected error sending %v", err)
+		}
+
+		var m transport.Message
+		if err := c.Recv(&m); err != nil {
+			t.Fatalf("Unexpected error receiving %v", err)
+		}
+
+		if m.Header["Content-Type"] != "application/json" || m.Header["Pong"] != "true" {
+			t.Fatalf("Unexpected headers %v", m.Header)
+		}
+		if string(m.Body) != `{"message": "ping"}` {
+			t.Fatalf("Unexpected body %s", m.Body)
+		}
+	}
+}
+
+func TestTCPTransport(t *testing.T) {
+	testTransport(t, NewTransport(), "127.0.0.1:0")
+}
+
+func TestTCPTransportSecure(t *testing.T) {
+	testTransport(t, NewTransport(transport.Secure(true)), "127.0.0.1:0")
+}
+
+func TestTCPTransportMutualTLS(t *testing.T) {
+	ca, err := mls.NewCA("test")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	testTransport(t, NewTransport(transport.TLSConfig(ca.Config("test", time.Hour))), "127.0.0.1:0")
+
+	tr := NewTransport(transport.TLSConfig(ca.Config("server", time.Hour)))
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	peers := make(chan string, 1)
+
+	go l.Accept(func(sock transport.Socket) {
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			peers <- ""
+			return
+		}
+		peers <- mls.PeerService(transport.ConnectionState(sock))
+	})
+
+	c, err := NewTransport(transport.TLSConfig(ca.Config("client", time.Hour))).Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Send(&transport.Message{Body: []byte(`ping`)}); err != nil {
+		t.Fatalf("Unexpected error sending %v", err)
+	}
+
+	// the server knows the service of the client
+	if name := <-peers; name != "client" {
+		t.Fatalf("Expected client got %q", name)
+	}
+
+	other, err := mls.NewCA("other")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	// clients of another CA are rejected
+	c, err = NewTransport(transport.TLSConfig(other.Config("client", time.Hour))).Dial(l.Addr())
+	if err == nil {
+		defer c.Close()
+		c.Send(&transport.Message{Body: []byte(`ping`)})
+		if name := <-peers; name != "" {
+			t.Fatalf("Expected the client to be rejected got %q", name)
+		}
+	}
+}
+
+func TestTCPTransportService(t *testing.T) {
+	ca, err := mls.NewCA("test")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	l, err := NewTransport(transport.TLSConfig(ca.Config("server", time.Hour))).Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock transport.Socket) {
+		var m transport.Message
+		sock.Recv(&m)
+	})
+
+	tr := NewTransport(transport.TLSConfig(ca.Config("client", time.Hour)))
+
+	c, err := tr.Dial(l.Addr(), transport.WithService("server"))
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	c.Close()
+
+	// servers of another service of the CA are rejected
+	if c, err := tr.Dial(l.Addr(), transport.WithService("other")); err == nil {
+		c.Close()
+		t.Fatal("Expected the server to be rejected")
+	}
+}
+
+func TestFrame(t *testing.T) {
+	testData := []*transport.Message{
+		{Header: map[string]string{}, Body: []byte{}},
+		{Header: map[string]string{"Foo": "bar", "": ""}, Body: []byte{}},
+		{Header: map[string]string{"Foo": "bar"}, Body: []byte(`hello world`)},
+	}
+
+	for _, m := range testData {
+		buf := encode(m)
+
+		var rm transport.Message
+		if err := decode(buf[4:], &rm); err != nil {
+			t.Fatalf("Unexpected error decoding %v", err)
+		}
+
+		if !reflect.DeepEqual(m, &rm) {
+			t.Fatalf("Expected %+v got %+v", m, rm)
+		}
+
+		// truncated frames fail to decode
+		if len(m.Header) > 0 {
+			if err := decode(buf[4:len(buf)-len(m.Body)-1], &rm); err != io.ErrUnexpectedEOF {
+				t.Fatalf("Expected %v got %v", io.ErrUnexpectedEOF, err)
+			}
+		}
+	}
+}
+
+func TestMessageSize(t *testing.T) {
+	max := MaxMessageSize
+	MaxMessageSize = 1024
+	defer func() {
+		MaxMessageSize = max
+	}()
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	// frames which are too large aren't sent
+	if err := NewSocket(c1, 0).Send(&transport.Message{Body: make([]byte, 2048)}); err != ErrMessageTooLarge {
+		t.Fatalf("Expected %v got %v", ErrMessageTooLarge, err)
+	}
+
+	sock := NewSocket(c2, 0)
+	errs := make(chan error, 1)
+	go func() {
+		var m transport.Message
+		errs <- sock.Recv(&m)
+	}()
+
+	// or received
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], 2048)
+	c1.Write(size[:])
+	if err := <-errs; err != ErrMessageTooLarge {
+		t.Fatalf("Expected %v got %v", ErrMessageTooLarge, err)
+	}
+
+	// frames which end early fail
+	go func() {
+		var m transport.Message
+		errs <- sock.Recv(&m)
+	}()
+
+	binary.BigEndian.PutUint32(size[:], 512)
+	c1.Write(size[:])
+	c1.Write(make([]byte, 256))
+	c1.Close()
+	if err := <-errs; err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected %v got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func call(b *testing.B, tr transport.Transport, c int) {
+	b.StopTimer()
+
+	// server listen
+	l, err := tr.Listen("localhost:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer l.Close()
+
+	// socket func
+	fn := func(sock transport.Socket) {
+		defer sock.Close()
+
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	}
+
+	// accept connections
+	go l.Accept(fn)
+
+	m := transport.Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: []byte(`{"message": "Hello World"}`),
+	}
+
+	send := func(c transport.Client) {
+		// send message
+		if err := c.Send(&m); err != nil {
+			b.Fatalf("Unexpected send err: %v", err)
+		}
+
+		var rm transport.Message
+		// receive message
+		if err := c.Recv(&rm); err != nil {
+			b.Fatalf("Unexpected recv err: %v", err)
+		}
+	}
+
+	ch := make(chan int, c*4)
+
+	var wg sync.WaitGroup
+	wg.Add(c)
+
+	for i := 0; i < c; i++ {
+		go func() {
+			cl, err := tr.Dial(l.Addr())
+			if err != nil {
+				b.Fatalf("Unexpected dial err: %v", err)
+			}
+			defer cl.Close()
+
+			for range ch {
+				send(cl)
+			}
+
+			wg.Done()
+		}()
+	}
+
+	b.StartTimer()
+
+	for i := 0; i < b.N; i++ {
+		ch <- i
+	}
+
+	close(ch)
+
+	wg.Wait()
+
+	b.StopTimer()
+}
+
+func BenchmarkTransport1(b *testing.B) {
+	call(b, NewTransport(), 1)
+}
+
+func BenchmarkTransport8(b *testing.B) {
+	call(b, NewTransport(), 8)
+}
+
+func BenchmarkTransport64(b *testing.B) {
+	call(b, NewTransport(), 64)
+}
+
+// the default http transport to compare against
+
+func BenchmarkHTTPTransport1(b *testing.B) {
+	call(b, transport.NewTransport(), 1)
+}
+
+func BenchmarkHTTPTransport8(b *testing.B) {
+	call(b, transport.NewTransport(), 8)
+}
+
+func BenchmarkHTTPTransport64(b *testing.B) {
+	call(b, transport.NewTransport(), 64)
+}
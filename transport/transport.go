@@ -2,12 +2,13 @@
 package transport
 
 import (
+	"crypto/tls"
 	"time"
 )
 
 // Transport is an interface which is used for communication between
 // services. It uses connection based socket send/recv semantics and
-// has various implementations; http, grpc, quic.
+// has various implementations; http, grpc, quic, tcp, unix.
 type Transport interface {
 	Init(...Option) error
 	Options() Options
@@ -33,6 +34,13 @@ type Client interface {
 	Socket
 }
 
+// TLSSocket is implemented by sockets which may be secured with tls
+type TLSSocket interface {
+	// ConnectionState returns the state of the tls connection
+	// or nil if the connection isn't secured with tls
+	ConnectionState() *tls.ConnectionState
+}
+
 type Listener interface {
 	Addr() string
 	Close() error
@@ -51,6 +59,15 @@ var (
 	DefaultDialTimeout = time.Second * 5
 )
 
+// ConnectionState returns the tls connection state of a socket
+// or nil if the socket isn't secured with tls
+func ConnectionState(s Socket) *tls.ConnectionState {
+	if ts, ok := s.(TLSSocket); ok {
+		return ts.ConnectionState()
+	}
+	return nil
+}
+
 func NewTransport(opts ...Option) Transport {
 	return newHTTPTransport(opts...)
 }
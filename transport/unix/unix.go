@@ -0,0 +1,115 @@
+// Package unix provides a unix domain socket transport for services on
+// the same host. Messages are sent as the length prefixed frames of the
+// tcp transport.
+package unix
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/transport"
+	"github.com/micro/go-micro/transport/tcp"
+	mls "github.com/micro/go-micro/util/tls"
+)
+
+type unixTransport struct {
+	opts transport.Options
+}
+
+func (u *unixTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	dopts := transport.DialOptions{
+		Timeout: transport.DefaultDialTimeout,
+	}
+
+	for _, o := range opts {
+		o(&dopts)
+	}
+
+	var conn net.Conn
+	var err error
+
+	if u.opts.Secure || u.opts.TLSConfig != nil {
+		config := u.opts.TLSConfig
+		if config == nil {
+			config = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+		config = mls.VerifyService(config, dopts.Service)
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, "unix", addr, config)
+	} else {
+		conn, err = net.DialTimeout("unix", addr, dopts.Timeout)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tcp.NewSocket(conn, u.opts.Timeout), nil
+}
+
+// Listen listens on the socket file at addr. A host:port address such as
+// the default server address of :0 listens on a new file in the temp dir.
+func (u *unixTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
+	var options transport.ListenOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(addr) == 0 || strings.HasPrefix(addr, ":") {
+		addr = filepath.Join(os.TempDir(), fmt.Sprintf("micro-%s.sock", uuid.New().String()))
+	}
+
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.opts.Secure || u.opts.TLSConfig != nil {
+		config := u.opts.TLSConfig
+		if config == nil {
+			// generate a certificate
+			cert, err := mls.Certificate("localhost")
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+			config = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+		l = tls.NewListener(l, config)
+	}
+
+	return tcp.NewListener(l, u.opts.Timeout), nil
+}
+
+func (u *unixTransport) Init(opts ...transport.Option) error {
+	for _, o := range opts {
+		o(&u.opts)
+	}
+	return nil
+}
+
+func (u *unixTransport) Options() transport.Options {
+	return u.opts
+}
+
+func (u *unixTransport) String() string {
+	return "unix"
+}
+
+// NewTransport returns a new unix domain socket transport
+func NewTransport(opts ...transport.Option) transport.Transport {
+	var options transport.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &unixTransport{
+		opts: options,
+	}
+}
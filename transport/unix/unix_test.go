@@ -0,0 +1,69 @@
+package unix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro/go-micro/transport"
+)
+
+func testTransport(t *testing.T, tr transport.Transport, addr string) {
+	l, err := tr.Listen(addr)
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	go func() {
+		l.Accept(func(sock transport.Socket) {
+			for {
+				var m transport.Message
+				if err := sock.Recv(&m); err != nil {
+					return
+				}
+				if err := sock.Send(&m); err != nil {
+					return
+				}
+			}
+		})
+	}()
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := c.Send(&transport.Message{
+			Header: map[string]string{"Foo": "bar"},
+			Body:   []byte(`ping`),
+		}); err != nil {
+			t.Fatalf("Unexpected error sending %v", err)
+		}
+
+		var m transport.Message
+		if err := c.Recv(&m); err != nil {
+			t.Fatalf("Unexpected error receiving %v", err)
+		}
+
+		if m.Header["Foo"] != "bar" || string(m.Body) != `ping` {
+			t.Fatalf("Unexpected message %+v", m)
+		}
+	}
+}
+
+func TestUnixTransport(t *testing.T) {
+	addr := filepath.Join(os.TempDir(), "micro-unix-test.sock")
+	testTransport(t, NewTransport(), addr)
+
+	// the socket file is removed on close
+	if _, err := os.Stat(addr); !os.IsNotExist(err) {
+		t.Fatalf("Expected socket file to be removed got %v", err)
+	}
+}
+
+func TestUnixTransportSecure(t *testing.T) {
+	testTransport(t, NewTransport(transport.Secure(true)), ":0")
+}
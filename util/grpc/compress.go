@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"github.com/micro/go-micro/codec/compress"
+	"google.golang.org/grpc/encoding"
+)
+
+type compressor struct {
+	compress.Compressor
+}
+
+func (c compressor) Name() string {
+	return c.String()
+}
+
+func init() {
+	RegisterCompressors()
+}
+
+// RegisterCompressors registers the compressors of the compress package
+// with grpc so its clients and servers negotiate them through the
+// grpc-encoding headers. Compressors grpc already has are kept. The
+// compressors of the compress package are registered when the package
+// is imported. Grpc isn't safe for registering compressors once it's in
+// use so call it only from the init function of packages adding
+// compressors with compress.Register.
+func RegisterCompressors() {
+	for _, name := range compress.Compressors() {
+		if encoding.GetCompressor(name) != nil {
+			continue
+		}
+		c, err := compress.Get(name)
+		if err != nil {
+			continue
+		}
+		encoding.RegisterCompressor(compressor{c})
+	}
+}
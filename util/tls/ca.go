@@ -0,0 +1,550 @@
+// Package tls provides the certificates securing connections between
+// services. A CA issues each service a certificate naming it, which the
+// services it connects to verify with mutual TLS.
+//
+// Holding the key of a CA means being able to issue certificates naming
+// any service. The local CA of LocalCA is shared by every service able to
+// read its directory, so any of them can impersonate the others. It only
+// authenticates services run by the same user on a host which trust each
+// other. Otherwise keep the key with a single issuer and give services
+// the certificates it issues.
+package tls
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// scheme of the uri naming the service of a certificate
+	serviceScheme = "micro"
+
+	caFile    = "ca.pem"
+	caKeyFile = "ca-key.pem"
+)
+
+var (
+	// DefaultCATTL is the lifetime of a new CA
+	DefaultCATTL = time.Hour * 24 * 365 * 10
+	// DefaultTTL is the lifetime of the certificates issued to services
+	DefaultTTL = time.Hour
+
+	// ErrNoService is returned for peers without a service certificate
+	ErrNoService = errors.New("certificate has no service name")
+)
+
+// CA issues certificates identifying services. Services trusting
+// the same CA authenticate each other with mutual TLS.
+type CA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+	pool *x509.CertPool
+	// pem encoded certificate and key
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func serial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encode(der []byte, priv *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	certOut := bytes.NewBuffer(nil)
+	pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	b, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyOut := bytes.NewBuffer(nil)
+	pem.Encode(keyOut, &pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+
+	return certOut.Bytes(), keyOut.Bytes(), nil
+}
+
+// NewCA creates a CA with a self signed certificate
+func NewCA(name string) (*CA, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return newCA(name, priv)
+}
+
+// newCA creates a CA with a self signed certificate for the key
+func newCA(name string, priv *ecdsa.PrivateKey) (*CA, error) {
+	serialNumber, err := serial()
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore := time.Now()
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(DefaultCATTL),
+
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM, keyPEM, err := encode(der, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadCA(certPEM, keyPEM)
+}
+
+// LoadCA returns the CA of a pem encoded certificate and key
+func LoadCA(certPEM, keyPEM []byte) (*CA, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !cert.IsCA {
+		return nil, errors.New("certificate is not a CA")
+	}
+
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &CA{
+		cert:    cert,
+		key:     key,
+		pool:    pool,
+		certPEM: certPEM,
+		keyPEM:  keyPEM,
+	}, nil
+}
+
+// LocalCA loads the CA stored in dir or creates it. Services
+// on a host share the CA by using the same directory. Anyone who
+// can read the directory can issue certificates for any service so
+// it must only be accessible by its owner, with the mode 0700, and
+// the key must have the mode 0600.
+func LocalCA(dir string) (*CA, error) {
+	certPath := filepath.Join(dir, caFile)
+	keyPath := filepath.Join(dir, caKeyFile)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := checkPrivate(dir); err != nil {
+		return nil, err
+	}
+
+	certPEM, err := ioutil.ReadFile(certPath)
+	if err == nil {
+		keyPEM, err := readKey(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		return LoadCA(certPEM, keyPEM)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var priv *ecdsa.PrivateKey
+
+	keyPEM, err := readKey(keyPath)
+	switch {
+	case os.IsNotExist(err):
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		b, err := x509.MarshalECPrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+
+		// the key is linked in place once it's complete so
+		// a service creating the CA at the same time wins
+		tmp, err := writeTemp(keyPath, keyPEM, 0600)
+		if err != nil {
+			return nil, err
+		}
+		err = os.Link(tmp, keyPath)
+		os.Remove(tmp)
+		if os.IsExist(err) {
+			return LocalCA(dir)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		// wait for the certificate of the service creating the CA
+		for i := 0; i < 10; i++ {
+			if certPEM, err := ioutil.ReadFile(certPath); err == nil {
+				return LoadCA(certPEM, keyPEM)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		// the service stopped before writing the certificate so it's
+		// created again. Certificates of the same key are interchangeable.
+		block, _ := pem.Decode(keyPEM)
+		if block == nil {
+			return nil, errors.New("invalid local ca key")
+		}
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ca, err := newCA("micro local ca", priv)
+	if err != nil {
+		return nil, err
+	}
+
+	// write the certificate in place once it's complete
+	tmp, err := writeTemp(certPath, ca.certPEM, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, certPath); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	return ca, nil
+}
+
+// checkPrivate returns an error if other users can access the path
+func checkPrivate(path string) error {
+	// windows doesn't have unix permissions
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if perm := fi.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("local ca %s is accessible by other users with mode %#o", path, perm)
+	}
+
+	return nil
+}
+
+// readKey reads the key of the local CA once it's checked to be private
+func readKey(path string) ([]byte, error) {
+	if err := checkPrivate(path); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// writeTemp writes data to a temporary file next to path
+func writeTemp(path string, data []byte, perm os.FileMode) (string, error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), perm)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Certificate returns the pem encoded certificate of the CA
+func (c *CA) Certificate() []byte {
+	return c.certPEM
+}
+
+// Pool returns a pool of the CA certificate
+func (c *CA) Pool() *x509.CertPool {
+	return c.pool
+}
+
+// Issue issues a certificate naming the service which expires after
+// ttl. Hosts are added so clients can also verify the host name.
+func (c *CA) Issue(service string, ttl time.Duration, host ...string) (tls.Certificate, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serialNumber, err := serial()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	// allow for clock skew between hosts
+	notBefore := time.Now().Add(-time.Minute)
+	notAfter := notBefore.Add(ttl + time.Minute)
+	if notAfter.After(c.cert.NotAfter) {
+		notAfter = c.cert.NotAfter
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: service,
+		},
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+
+		DNSNames: []string{service},
+		URIs:     []*url.URL{{Scheme: serviceScheme, Host: service}},
+	}
+
+	for _, h := range host {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, c.cert, &priv.PublicKey, c.key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+		Leaf:        leaf,
+	}, nil
+}
+
+// Config returns the tls config of a service for mutual TLS with the
+// services of the CA. Its certificate is issued when first used and
+// reissued after two thirds of the ttl. Connections are unaffected
+// since certificates are only checked when connecting.
+func (c *CA) Config(service string, ttl time.Duration) *tls.Config {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	id := &identity{
+		ca:      c,
+		service: service,
+		ttl:     ttl,
+	}
+
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return id.certificate()
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return id.certificate()
+		},
+		// servers verify clients against the CA
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  c.pool,
+		// clients dial addresses rather than host names so servers
+		// are verified against the CA without them. The connection
+		// state of clients therefore has no verified chains. The
+		// service dialed is checked with VerifyService.
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: c.verify,
+	}
+}
+
+// verify checks the peer certificate was issued by the CA to a service
+func (c *CA) verify(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	var leaf *x509.Certificate
+
+	if len(verifiedChains) > 0 {
+		leaf = verifiedChains[0][0]
+	} else {
+		if len(rawCerts) == 0 {
+			return errors.New("no peer certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		leaf = certs[0]
+
+		if _, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         c.pool,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(Service(leaf)) == 0 {
+		return ErrNoService
+	}
+
+	return nil
+}
+
+// VerifyService returns a copy of the config which also checks the peer
+// is the service dialed. Certificates without a service name are left to
+// the verification of the config.
+func VerifyService(config *tls.Config, service string) *tls.Config {
+	if config == nil || len(service) == 0 {
+		return config
+	}
+
+	verify := config.VerifyPeerCertificate
+
+	c := config.Clone()
+	c.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if verify != nil {
+			if err := verify(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+
+		var leaf *x509.Certificate
+
+		if len(verifiedChains) > 0 {
+			leaf = verifiedChains[0][0]
+		} else {
+			if len(rawCerts) == 0 {
+				return errors.New("no peer certificate")
+			}
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			leaf = cert
+		}
+
+		if name := Service(leaf); len(name) > 0 && name != service {
+			return fmt.Errorf("certificate is for service %s not %s", name, service)
+		}
+
+		return nil
+	}
+
+	return c
+}
+
+// identity is the certificate of a service which is reissued before it expires
+type identity struct {
+	ca      *CA
+	service string
+	ttl     time.Duration
+
+	sync.Mutex
+	cert  *tls.Certificate
+	renew time.Time
+}
+
+func (i *identity) certificate() (*tls.Certificate, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	now := time.Now()
+
+	if i.cert != nil && now.Before(i.renew) {
+		return i.cert, nil
+	}
+
+	cert, err := i.ca.Issue(i.service, i.ttl)
+	if err != nil {
+		// keep the current certificate until it expires
+		if i.cert != nil && now.Before(i.cert.Leaf.NotAfter) {
+			return i.cert, nil
+		}
+		return nil, err
+	}
+
+	i.cert = &cert
+	i.renew = now.Add(i.ttl * 2 / 3)
+
+	return i.cert, nil
+}
+
+// Service returns the name of the service a certificate was issued to
+func Service(cert *x509.Certificate) string {
+	for _, u := range cert.URIs {
+		if u.Scheme == serviceScheme {
+			return u.Host
+		}
+	}
+	return ""
+}
+
+// PeerService returns the service name of the verified peer of a
+// connection. It's empty if the peer certificate wasn't verified.
+func PeerService(state *tls.ConnectionState) string {
+	if state == nil || len(state.VerifiedChains) == 0 {
+		return ""
+	}
+	return Service(state.VerifiedChains[0][0])
+}
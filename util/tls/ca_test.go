@@ -0,0 +1,270 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// handshake connects a client and server returning the
+// service name of the client verified by the server
+func handshake(client, server *tls.Config) (string, error) {
+	l, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	ch := make(chan result, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			ch <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		tc := conn.(*tls.Conn)
+		if err := tc.Handshake(); err != nil {
+			ch <- result{err: err}
+			return
+		}
+		state := tc.ConnectionState()
+		ch <- result{name: PeerService(&state)}
+	}()
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), client)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	// read to complete the handshake the server may reject
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	conn.Read(make([]byte, 1))
+
+	res := <-ch
+	return res.name, res.err
+}
+
+func TestIssue(t *testing.T) {
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	cert, err := ca.Issue("go.micro.srv.foo", time.Hour, "127.0.0.1", "foo.local")
+	if err != nil {
+		t.Fatalf("Unexpected error issuing certificate %v", err)
+	}
+
+	if name := Service(cert.Leaf); name != "go.micro.srv.foo" {
+		t.Fatalf("Expected go.micro.srv.foo got %s", name)
+	}
+
+	if len(cert.Leaf.IPAddresses) != 1 || !cert.Leaf.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")) {
+		t.Fatalf("Unexpected ip addresses %v", cert.Leaf.IPAddresses)
+	}
+
+	if _, err := cert.Leaf.Verify(x509.VerifyOptions{
+		Roots:   ca.Pool(),
+		DNSName: "foo.local",
+	}); err != nil {
+		t.Fatalf("Unexpected error verifying certificate %v", err)
+	}
+
+	// the pem encoded CA can be loaded
+	lca, err := LoadCA(ca.Certificate(), ca.keyPEM)
+	if err != nil {
+		t.Fatalf("Unexpected error loading CA %v", err)
+	}
+	if !lca.cert.Equal(ca.cert) {
+		t.Fatal("Expected the loaded CA to match")
+	}
+
+	// leaf certificates aren't CAs
+	certPEM, keyPEM, err := encode(cert.Leaf.Raw, cert.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatalf("Unexpected error encoding certificate %v", err)
+	}
+	if _, err := LoadCA(certPEM, keyPEM); err == nil {
+		t.Fatal("Expected error loading a leaf certificate as a CA")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	name, err := handshake(ca.Config("foo", time.Hour), ca.Config("bar", time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error connecting %v", err)
+	}
+
+	// the server knows the service of the client
+	if name != "foo" {
+		t.Fatalf("Expected foo got %s", name)
+	}
+
+	other, err := NewCA("other")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	// servers reject clients of another CA
+	if _, err := handshake(other.Config("foo", time.Hour), ca.Config("bar", time.Hour)); err == nil {
+		t.Fatal("Expected server to reject a client of another CA")
+	}
+
+	// clients reject servers of another CA
+	if _, err := handshake(ca.Config("foo", time.Hour), other.Config("bar", time.Hour)); err == nil {
+		t.Fatal("Expected client to reject a server of another CA")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	id := &identity{ca: ca, service: "foo", ttl: time.Hour}
+
+	first, err := id.certificate()
+	if err != nil {
+		t.Fatalf("Unexpected error issuing certificate %v", err)
+	}
+
+	// the certificate is reused until it's due for renewal
+	cert, err := id.certificate()
+	if err != nil {
+		t.Fatalf("Unexpected error issuing certificate %v", err)
+	}
+	if cert != first {
+		t.Fatal("Expected the same certificate before renewal")
+	}
+
+	id.renew = time.Now().Add(-time.Second)
+
+	cert, err = id.certificate()
+	if err != nil {
+		t.Fatalf("Unexpected error issuing certificate %v", err)
+	}
+	if cert == first || cert.Leaf.SerialNumber.Cmp(first.Leaf.SerialNumber) == 0 {
+		t.Fatal("Expected a new certificate after renewal")
+	}
+	if name := Service(cert.Leaf); name != "foo" {
+		t.Fatalf("Expected foo got %s", name)
+	}
+}
+
+func TestLocalCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ca")
+	if err != nil {
+		t.Fatalf("Unexpected error creating dir %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, err := LocalCA(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	// services using the directory share the CA
+	lca, err := LocalCA(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error loading CA %v", err)
+	}
+
+	if !bytes.Equal(ca.Certificate(), lca.Certificate()) {
+		t.Fatal("Expected the same CA")
+	}
+
+	if _, err := handshake(ca.Config("foo", time.Hour), lca.Config("bar", time.Hour)); err != nil {
+		t.Fatalf("Unexpected error connecting %v", err)
+	}
+
+	// a CA whose certificate wasn't written is recovered from the key
+	if err := os.Remove(filepath.Join(dir, caFile)); err != nil {
+		t.Fatalf("Unexpected error removing certificate %v", err)
+	}
+
+	rca, err := LocalCA(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error recovering CA %v", err)
+	}
+
+	if _, err := handshake(ca.Config("foo", time.Hour), rca.Config("bar", time.Hour)); err != nil {
+		t.Fatalf("Unexpected error connecting %v", err)
+	}
+}
+
+func TestLocalCAPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows doesn't have unix permissions")
+	}
+
+	dir, err := ioutil.TempDir("", "ca")
+	if err != nil {
+		t.Fatalf("Unexpected error creating dir %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := LocalCA(dir); err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	// the key can't be readable by other users
+	keyPath := filepath.Join(dir, caKeyFile)
+	if err := os.Chmod(keyPath, 0644); err != nil {
+		t.Fatalf("Unexpected error changing mode %v", err)
+	}
+	if _, err := LocalCA(dir); err == nil {
+		t.Fatal("Expected error loading a key readable by other users")
+	}
+	os.Chmod(keyPath, 0600)
+
+	// nor the directory
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatalf("Unexpected error changing mode %v", err)
+	}
+	if _, err := LocalCA(dir); err == nil {
+		t.Fatal("Expected error loading a directory accessible by other users")
+	}
+	os.Chmod(dir, 0700)
+
+	if _, err := LocalCA(dir); err != nil {
+		t.Fatalf("Unexpected error loading CA %v", err)
+	}
+}
+
+func TestVerifyService(t *testing.T) {
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatalf("Unexpected error creating CA %v", err)
+	}
+
+	if _, err := handshake(VerifyService(ca.Config("foo", time.Hour), "bar"), ca.Config("bar", time.Hour)); err != nil {
+		t.Fatalf("Unexpected error connecting %v", err)
+	}
+
+	// clients reject other services of the CA
+	if _, err := handshake(VerifyService(ca.Config("foo", time.Hour), "baz"), ca.Config("bar", time.Hour)); err == nil {
+		t.Fatal("Expected client to reject another service")
+	}
+}
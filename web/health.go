@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/micro/go-micro/debug/health"
+	pb "github.com/micro/go-micro/debug/service/proto"
+)
+
+// healthHandler serves the health checks at path, the liveness checks
+// at path/live and the readiness checks at path/ready. Other requests
+// are passed to the next handler.
+type healthHandler struct {
+	h    *health.Health
+	path string
+	next http.Handler
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var types []health.Type
+
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case h.path:
+	case h.path + "/live":
+		types = append(types, health.Liveness)
+	case h.path + "/ready":
+		types = append(types, health.Readiness)
+	default:
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	results := h.h.Check(r.Context(), types...)
+
+	rsp := &pb.HealthResponse{
+		Status: results.Status(),
+	}
+
+	for _, res := range results {
+		check := &pb.HealthCheck{
+			Name:     res.Name,
+			Type:     string(res.Type),
+			Status:   health.StatusOk,
+			Duration: res.Duration.Nanoseconds(),
+		}
+		if res.Error != nil {
+			check.Status = health.StatusFailing
+			check.Error = res.Error.Error()
+		}
+		rsp.Checks = append(rsp.Checks, check)
+	}
+
+	b, err := json.Marshal(rsp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !results.Ok() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(b)
+}
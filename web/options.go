@@ -8,6 +8,7 @@ import (
 
 	"github.com/micro/cli"
 	"github.com/micro/go-micro"
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/registry"
 )
 
@@ -25,6 +26,11 @@ type Options struct {
 	RegisterTTL      time.Duration
 	RegisterInterval time.Duration
 
+	// Time to keep serving after deregistering
+	DrainPeriod time.Duration
+	// Time to wait for in-flight requests on shutdown
+	ShutdownTimeout time.Duration
+
 	Server  *http.Server
 	Handler http.Handler
 
@@ -43,6 +49,10 @@ type Options struct {
 
 	// Static directory
 	StaticDir string
+
+	// Health checks served at HealthPath
+	Health     *health.Health
+	HealthPath string
 }
 
 func newOptions(opts ...Option) Options {
@@ -53,7 +63,10 @@ func newOptions(opts ...Option) Options {
 		Address:          DefaultAddress,
 		RegisterTTL:      DefaultRegisterTTL,
 		RegisterInterval: DefaultRegisterInterval,
+		DrainPeriod:      DefaultDrainPeriod,
+		ShutdownTimeout:  DefaultShutdownTimeout,
 		StaticDir:        DefaultStaticDir,
+		Health:           health.DefaultHealth,
 		Service:          micro.NewService(),
 		Context:          context.TODO(),
 	}
@@ -144,6 +157,38 @@ func RegisterInterval(t time.Duration) Option {
 	}
 }
 
+// DrainPeriod is the time to keep serving requests after deregistering
+// so clients have time to stop sending new requests
+func DrainPeriod(d time.Duration) Option {
+	return func(o *Options) {
+		o.DrainPeriod = d
+	}
+}
+
+// ShutdownTimeout is the time to wait for in-flight requests before
+// connections are closed
+func ShutdownTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.ShutdownTimeout = d
+	}
+}
+
+// Health sets the health checks served by the service
+func Health(h *health.Health) Option {
+	return func(o *Options) {
+		o.Health = h
+	}
+}
+
+// HealthPath serves the health checks at the path e.g DefaultHealthPath.
+// The liveness and readiness checks are served at path/live and path/ready.
+// The health endpoints aren't served unless a path is set.
+func HealthPath(path string) Option {
+	return func(o *Options) {
+		o.HealthPath = path
+	}
+}
+
 func Handler(h http.Handler) Option {
 	return func(o *Options) {
 		o.Handler = h
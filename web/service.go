@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net"
@@ -185,6 +186,15 @@ func (s *service) start() error {
 		})
 	}
 
+	// serve the health checks
+	if len(s.opts.HealthPath) > 0 && s.opts.Health != nil {
+		h = &healthHandler{
+			h:    s.opts.Health,
+			path: strings.TrimSuffix(s.opts.HealthPath, "/"),
+			next: h,
+		}
+	}
+
 	for _, fn := range s.opts.BeforeStart {
 		if err := fn(); err != nil {
 			return err
@@ -213,7 +223,27 @@ func (s *service) start() error {
 
 	go func() {
 		ch := <-s.exit
-		ch <- l.Close()
+
+		// keep serving while clients learn the service is going away
+		if s.opts.DrainPeriod > 0 {
+			time.Sleep(s.opts.DrainPeriod)
+		}
+
+		ctx := context.Background()
+		if s.opts.ShutdownTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.opts.ShutdownTimeout)
+			defer cancel()
+		}
+
+		// stop accepting connections and wait for requests to finish
+		err := httpSrv.Shutdown(ctx)
+		if err == context.DeadlineExceeded {
+			log.Log("Shutdown timeout, closing connections")
+			err = httpSrv.Close()
+		}
+
+		ch <- err
 	}()
 
 	log.Logf("Listening on %v", l.Addr().String())
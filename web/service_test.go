@@ -1,16 +1,20 @@
 package web
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/signal"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/micro/go-micro/debug/health"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/registry/memory"
 )
@@ -258,3 +262,32 @@ func TestTLS(t *testing.T) {
 		t.Errorf("Expected %s got %s", str, string(b))
 	}
 }
+
+func TestHealth(t *testing.T) {
+	h := health.NewHealth()
+	h.Register("db", health.Readiness, func(ctx context.Context) error {
+		return errors.New("down")
+	})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	handler := &healthHandler{h: h, path: "/health", next: next}
+
+	testCases := map[string]int{
+		"/health":       http.StatusServiceUnavailable,
+		"/health/ready": http.StatusServiceUnavailable,
+		"/health/live":  http.StatusOK,
+		"/foo":          http.StatusTeapot,
+	}
+
+	for path, code := range testCases {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		if w.Code != code {
+			t.Fatalf("Expected %s to return %d got %d", path, code, w.Code)
+		}
+	}
+}
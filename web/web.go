@@ -31,8 +31,15 @@ var (
 	DefaultRegisterTTL      = time.Minute
 	DefaultRegisterInterval = time.Second * 30
 
+	// for shutdown
+	DefaultDrainPeriod     = time.Duration(0)
+	DefaultShutdownTimeout = time.Second * 10
+
 	// static directory
 	DefaultStaticDir = "html"
+
+	// health checks path when enabled with HealthPath
+	DefaultHealthPath = "/health"
 )
 
 // NewService returns a new web.Service